@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountController handles the signed-in user's own account
+type AccountController struct {
+	service      services.AccountService
+	personalData services.PersonalDataService
+}
+
+// NewAccountController creates a new AccountController
+func NewAccountController(service services.AccountService, personalData services.PersonalDataService) *AccountController {
+	return &AccountController{service: service, personalData: personalData}
+}
+
+// respondAccountError maps account service errors to HTTP responses
+func respondAccountError(ctx *gin.Context, err error) {
+	var policyErr *services.PasswordPolicyError
+	switch {
+	case errors.As(err, &policyErr), strings.HasPrefix(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err.Error() == "email address is already in use":
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "user not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// @Summary Get the current user
+// @Description Returns the account of the signed-in user
+// @Tags account
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.User "Account details"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me [get]
+func (c *AccountController) GetProfile(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := c.service.GetProfile(userID)
+	if err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Export personal data
+// @Description Downloads a ZIP archive with everything stored about the signed-in user as JSON files: the account, tasks, projects, tags, saved views, API keys, calendar feeds and admin actions by or about the user. manifest.json lists the files.
+// @Tags account
+// @Produce application/zip
+// @Security ApiKeyAuth
+// @Success 200 {file} file "ZIP archive"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "API keys cannot be used"
+// @Router /me/export [get]
+func (c *AccountController) ExportData(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filename := "task-manager-export-" + time.Now().UTC().Format("2006-01-02") + ".zip"
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure can only cut the archive short
+	if err := c.personalData.Export(userID, ctx.Writer); err != nil {
+		log.Printf("Personal data export for user %d failed: %v", userID, err)
+		ctx.Abort()
+	}
+}
+
+// @Summary Change the password
+// @Description Sets a new password after checking the current one. All login tokens are revoked, including the one used for this request; API keys keep working.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.PasswordChangeRequest true "Current and new password"
+// @Success 200 {object} models.MessageResponse "Password changed"
+// @Failure 400 {object} models.ErrorResponse "Wrong current password or new password rejected by the policy"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "API keys cannot be used"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/password [put]
+func (c *AccountController) ChangePassword(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.PasswordChangeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || request.CurrentPassword == "" || request.NewPassword == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := c.service.ChangePassword(userID, request.CurrentPassword, request.NewPassword); err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password changed, please log in again"})
+}
+
+// @Summary Change the email address
+// @Description Sends a verification link to the new address after checking the password. The account keeps its current address until the link is opened; the current address is notified.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.EmailChangeRequest true "New address and current password"
+// @Success 202 {object} models.MessageResponse "Verification link sent to the new address"
+// @Failure 400 {object} models.ErrorResponse "Invalid email or wrong password"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "API keys cannot be used"
+// @Failure 409 {object} models.ErrorResponse "Email address is already in use"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/email [put]
+func (c *AccountController) ChangeEmail(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.EmailChangeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || request.Password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if !isValidEmail(request.Email) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	if err := c.service.ChangeEmail(userID, request.Password, request.Email); err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "A verification link has been sent to the new address"})
+}
+
+// @Summary Delete the account
+// @Description Disables the account and signs it out everywhere after checking the password; the account is then erased in the background. Personal tasks, tags, views, API keys and calendar feeds are deleted. Tasks in projects shared with other members are kept without an author but with their title and description, with the user's address replaced by "deleted-user"; owned projects with other members are handed over to the longest-standing member.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.AccountDeleteRequest true "Current password"
+// @Success 202 {object} models.MessageResponse "Account disabled and queued for erasure"
+// @Failure 400 {object} models.ErrorResponse "Wrong password"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "API keys cannot be used"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me [delete]
+func (c *AccountController) DeleteAccount(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.AccountDeleteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || request.Password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := c.service.DeleteAccount(userID, request.Password); err != nil {
+		respondAccountError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "Your account has been disabled and will be erased shortly"})
+}
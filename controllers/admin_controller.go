@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminController handles administrator-only requests
+type AdminController struct {
+	service      services.AdminService
+	personalData services.PersonalDataService
+	audit        services.AuditService
+}
+
+// NewAdminController creates a new AdminController
+func NewAdminController(service services.AdminService, personalData services.PersonalDataService, audit services.AuditService) *AdminController {
+	return &AdminController{service: service, personalData: personalData, audit: audit}
+}
+
+// respondAdminError maps admin service errors to HTTP responses
+func respondAdminError(ctx *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err.Error() == "user not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseUserID reads the ":id" path parameter, responding with 400 if it is not a valid ID
+func parseUserID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List users
+// @Description Returns one page of users, optionally searched by email and filtered by role and status. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string false "Case-insensitive search in the email address"
+// @Param role query string false "user or admin"
+// @Param disabled query bool false "Only disabled (true) or active (false) accounts"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Users per page (max 100)" default(20)
+// @Success 200 {object} models.UserPage "Page of users"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/users [get]
+func (c *AdminController) ListUsers(ctx *gin.Context) {
+	filter, err := models.ParseUserFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.service.ListUsers(filter)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Get a user
+// @Description Returns any user's account. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User "Account details"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /admin/users/{id} [get]
+func (c *AdminController) GetUser(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := c.service.GetUser(userID)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Disable a user
+// @Description Disables an account: the user cannot log in and their tokens and API keys are rejected with 403. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User "Disabled account"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID or own account"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /admin/users/{id}/disable [post]
+func (c *AdminController) DisableUser(ctx *gin.Context) {
+	c.setDisabled(ctx, true)
+}
+
+// @Summary Enable a user
+// @Description Enables a disabled account again. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User "Enabled account"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /admin/users/{id}/enable [post]
+func (c *AdminController) EnableUser(ctx *gin.Context) {
+	c.setDisabled(ctx, false)
+}
+
+// setDisabled disables or enables the user in the path
+func (c *AdminController) setDisabled(ctx *gin.Context, disabled bool) {
+	adminID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := c.service.SetDisabled(adminID, userID, disabled)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Change a user's role
+// @Description Makes a user an administrator or a regular user. Administrators cannot change their own role. Admins only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param request body models.RoleChangeRequest true "New role"
+// @Success 200 {object} models.User "Updated account"
+// @Failure 400 {object} models.ErrorResponse "Invalid role or own account"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /admin/users/{id}/role [put]
+func (c *AdminController) SetRole(ctx *gin.Context) {
+	adminID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.RoleChangeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	middleware.AddAuditDetail(ctx, "role", request.Role)
+
+	user, err := c.service.SetRole(adminID, userID, request.Role)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Force a password reset
+// @Description Logs the user out everywhere, blocks logins until the password is reset and emails a reset link. API keys keep working. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 202 {object} models.MessageResponse "Reset link sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/users/{id}/password-reset [post]
+func (c *AdminController) ForcePasswordReset(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.ForcePasswordReset(userID); err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "Password reset required, a reset link has been sent"})
+}
+
+// @Summary List a user's tasks
+// @Description Returns one page of any user's tasks, with the same filters as GET /tasks. Read-only. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param status query string false "Filter by status"
+// @Param q query string false "Search in title and description"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Tasks per page (max 100)" default(20)
+// @Success 200 {object} models.TaskListResponse "Page of tasks"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID or query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /admin/users/{id}/tasks [get]
+func (c *AdminController) ListUserTasks(ctx *gin.Context) {
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+	filter, err := models.ParseTaskFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.service.ListUserTasks(userID, filter)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary System statistics
+// @Description Counts users, tasks and projects of the whole system. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.SystemStats "Statistics"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/stats [get]
+func (c *AdminController) GetStats(ctx *gin.Context) {
+	stats, err := c.service.GetStats()
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// @Summary Audit log
+// @Description Returns one page of recorded requests to admin routes, newest first. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param actor_id query int false "Only requests of this user"
+// @Param target_user_id query int false "Only requests about this user"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Entries per page (max 100)" default(20)
+// @Success 200 {object} models.AuditPage "Page of audit entries"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/audit-log [get]
+func (c *AdminController) GetAuditLog(ctx *gin.Context) {
+	filter, err := models.ParseAuditFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.audit.List(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Unlock an account
+// @Description Clears failed login attempts and the lockout of an account and, optionally, of a client IP. Admins only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.UnlockRequest true "Account email and optional IP"
+// @Success 200 {object} models.MessageResponse "Account unlocked"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/users/unlock [post]
+func (c *AdminController) UnlockUser(ctx *gin.Context) {
+	var request models.UnlockRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || (request.Email == "" && request.IP == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	middleware.AddAuditDetail(ctx, "email", request.Email)
+	middleware.AddAuditDetail(ctx, "ip", request.IP)
+
+	user, err := c.service.Unlock(request.Email, request.IP)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if user != nil {
+		middleware.SetAuditTarget(ctx, user.ID)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
+
+// @Summary Erase a user
+// @Description Answers a right-to-erasure request: disables the account right away and queues the erasure, which hard-deletes the user and their data, including soft-deleted rows, in the background. Tasks in projects shared with other members are kept without an author. Returns the pending record, which GET /admin/erasures shows completed once the job has run. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 202 {object} models.ErasureRecord "Pending erasure record"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/users/{id}/erase [post]
+func (c *AdminController) EraseUser(ctx *gin.Context) {
+	adminID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, ok := parseUserID(ctx)
+	if !ok {
+		return
+	}
+
+	record, err := c.personalData.RequestErasure(userID, adminID)
+	if err != nil {
+		respondAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, record)
+}
+
+// @Summary List erasure records
+// @Description Returns the records of all erasures, newest first; pending ones have no completed_at yet. Admins only.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.ErasureRecord "Erasure records"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/erasures [get]
+func (c *AdminController) GetErasureRecords(ctx *gin.Context) {
+	records, err := c.personalData.GetErasureRecords()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyController handles HTTP requests for personal API keys
+type APIKeyController struct {
+	service services.APIKeyService
+}
+
+// NewAPIKeyController creates a new APIKeyController
+func NewAPIKeyController(service services.APIKeyService) *APIKeyController {
+	return &APIKeyController{service: service}
+}
+
+// @Summary Create an API key
+// @Description Creates a personal API key for scripts and CI. The key is shown only once; use it as a bearer token. Requires the admin scope.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.APIKeyCreateRequest true "Key name, scopes (tasks:read, tasks:write, admin) and lifetime"
+// @Success 201 {object} models.APIKeyCreateResponse "Key created"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Insufficient scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api-keys [post]
+func (c *APIKeyController) CreateKey(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.APIKeyCreateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := c.service.CreateKey(userID, request)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// @Summary List API keys
+// @Description Lists the authenticated user's API keys with their scopes, expiry and last use. Secrets are never returned. Requires the admin scope.
+// @Tags api-keys
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.APIKey "List of API keys"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Insufficient scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api-keys [get]
+func (c *APIKeyController) ListKeys(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	keys, err := c.service.ListKeys(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// @Summary Revoke an API key
+// @Description Deletes one of the authenticated user's API keys. Requires the admin scope.
+// @Tags api-keys
+// @Security ApiKeyAuth
+// @Param id path int true "API key ID"
+// @Success 204 "Key revoked"
+// @Failure 400 {object} models.ErrorResponse "Invalid key ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Insufficient scope"
+// @Failure 404 {object} models.ErrorResponse "API key not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api-keys/{id} [delete]
+func (c *APIKeyController) RevokeKey(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID"})
+		return
+	}
+
+	if err := c.service.RevokeKey(uint(id), userID); err != nil {
+		if err.Error() == "api key not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/EmelinDanila/task-manager-api/repository"
@@ -15,21 +17,33 @@ import (
 
 // AuthController handles authentication-related operations.
 type AuthController struct {
-	authService services.AuthService      // Service for authentication operations
-	userRepo    repository.UserRepository // Repository for user data access
-	validate    *validator.Validate       // Validator for request data
+	authService  services.AuthService              // Service for authentication operations
+	userRepo     repository.UserRepository         // Repository for user data access
+	verification services.EmailVerificationService // Sends verification emails; optional
+	twoFactor    services.TwoFactorService         // Second login step for users with 2FA; optional
+	policy       *services.PasswordPolicy          // Rules for new passwords
+	invitations  services.InvitationService        // Lets invited people join on registration; optional
+	validate     *validator.Validate               // Validator for request data
 }
 
-func NewAuthController(authService services.AuthService, userRepo repository.UserRepository) *AuthController {
+// NewAuthController creates a new AuthController. Without a verification service
+// no verification emails are sent and unverified users can log in; without a
+// two-factor service logins never ask for a second factor; without an invitation
+// service registrations with an invitation token are rejected.
+func NewAuthController(authService services.AuthService, userRepo repository.UserRepository, verification services.EmailVerificationService, twoFactor services.TwoFactorService, policy *services.PasswordPolicy, invitations services.InvitationService) *AuthController {
 	return &AuthController{
-		authService: authService,
-		userRepo:    userRepo,
-		validate:    validator.New(),
+		authService:  authService,
+		userRepo:     userRepo,
+		verification: verification,
+		twoFactor:    twoFactor,
+		policy:       policy,
+		invitations:  invitations,
+		validate:     validator.New(),
 	}
 }
 
 // @Summary Register a new user
-// @Description Register a new user with email and password
+// @Description Register a new user with email and password. A verification link is emailed to the address. With the token of an invitation sent to the same address, the user joins the organization instead and the address counts as verified.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -41,8 +55,9 @@ func NewAuthController(authService services.AuthService, userRepo repository.Use
 // @Router /register [post]
 func (ac *AuthController) RegisterUser(c *gin.Context) {
 	var requestData struct {
-		Email    string `json:"email" validate:"required,email"`    // User's email
-		Password string `json:"password" validate:"required,min=8"` // User's password
+		Email           string `json:"email" validate:"required,email"`    // User's email
+		Password        string `json:"password" validate:"required,min=8"` // User's password
+		InvitationToken string `json:"invitation_token"`                   // Optional invitation to accept
 	}
 
 	// Bind and validate the request data
@@ -58,8 +73,8 @@ func (ac *AuthController) RegisterUser(c *gin.Context) {
 	}
 
 	// Validate password strength
-	if !isValidPassword(requestData.Password) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 8 characters long and contain a number, a special character, and an uppercase letter"})
+	if err := ac.policy.Validate(requestData.Password, requestData.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -70,6 +85,27 @@ func (ac *AuthController) RegisterUser(c *gin.Context) {
 		return
 	}
 
+	// Check the invitation before creating the account, so a bad token leaves nothing behind
+	if requestData.InvitationToken != "" {
+		if ac.invitations == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+			return
+		}
+		invitation, err := ac.invitations.Lookup(requestData.InvitationToken)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "invalid") {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check invitation"})
+			}
+			return
+		}
+		if !strings.EqualFold(invitation.Email, requestData.Email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation: it was sent to another email address"})
+			return
+		}
+	}
+
 	// Create a new user
 	user := &models.User{
 		Email:    requestData.Email,
@@ -80,26 +116,47 @@ func (ac *AuthController) RegisterUser(c *gin.Context) {
 		return
 	}
 
+	// Join the invited organization; the invitation proves the address, so no verification
+	// email is needed. If joining fails the user is registered like everyone else.
+	if requestData.InvitationToken != "" {
+		member, err := ac.invitations.Accept(requestData.InvitationToken, user.ID)
+		if err == nil {
+			c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "organization_id": member.OrganizationID})
+			return
+		}
+		log.Printf("Could not accept invitation for user %d: %v", user.ID, err)
+	}
+
+	// Send the verification email; the user can ask for another one if it fails
+	if ac.verification != nil {
+		if err := ac.verification.SendVerification(user); err != nil {
+			log.Printf("Could not send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
 	// Return success response
 	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
 }
 
 // LoginUser handles user login.
 // @Summary Login a user
-// @Description Login a user with email and password
+// @Description Login a user with email and password. The token has every scope unless a subset of scopes (tasks:read, tasks:write, admin) is requested. Users with two-factor authentication get an mfa_token instead, to be exchanged at /login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body models.UserRegisterRequest true "User login data"
+// @Param request body models.UserLoginRequest true "User login data"
 // @Success 200 {object} models.TokenResponse "Login successful, token generated"
+// @Success 202 {object} models.TwoFactorChallengeResponse "Password correct, second factor required"
 // @Failure 400 {object} models.ErrorResponse "Invalid request data"
 // @Failure 401 {object} models.ErrorResponse "Invalid email or password"
+// @Failure 403 {object} models.ErrorResponse "Account disabled, password reset required or email address not verified"
 // @Failure 500 {object} models.ErrorResponse "Could not generate token"
 // @Router /login [post]
 func (ac *AuthController) LoginUser(c *gin.Context) {
 	var loginData struct {
-		Email    string `json:"email" validate:"required,email"`    // User's email
-		Password string `json:"password" validate:"required,min=8"` // User's password
+		Email    string   `json:"email" validate:"required,email"`    // User's email
+		Password string   `json:"password" validate:"required,min=8"` // User's password
+		Scopes   []string `json:"scopes"`                             // Optional subset of scopes for the token
 	}
 
 	// Bind and validate the request data
@@ -133,8 +190,108 @@ func (ac *AuthController) LoginUser(c *gin.Context) {
 		return
 	}
 
-	// Generate a JWT token
-	token, err := ac.authService.GenerateToken(user.ID)
+	if !ac.canLogIn(c, user) {
+		return
+	}
+
+	// Validate the requested scopes
+	for _, scope := range loginData.Scopes {
+		if !models.IsValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope " + scope})
+			return
+		}
+	}
+
+	// Users with two-factor authentication continue at /login/2fa
+	if ac.twoFactor != nil && user.TOTPEnabledAt != nil {
+		mfaToken, err := ac.twoFactor.StartLogin(user.ID, loginData.Scopes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+			return
+		}
+		c.JSON(http.StatusAccepted, models.TwoFactorChallengeResponse{TwoFactorRequired: true, MFAToken: mfaToken})
+		return
+	}
+
+	ac.respondWithToken(c, user.ID, loginData.Scopes)
+}
+
+// LoginTwoFactor completes a login with a second factor.
+// @Summary Complete a two-factor login
+// @Description Exchanges the mfa_token from /login and a TOTP or recovery code for a login token. The mfa_token is valid for five minutes and stops working after five wrong codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.LoginTwoFactorRequest true "Intermediate token and code"
+// @Success 200 {object} models.TokenResponse "Login successful, token generated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Invalid code or expired mfa_token"
+// @Failure 403 {object} models.ErrorResponse "Account disabled, password reset required or email address not verified"
+// @Failure 500 {object} models.ErrorResponse "Could not generate token"
+// @Router /login/2fa [post]
+func (ac *AuthController) LoginTwoFactor(c *gin.Context) {
+	var request models.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.MFAToken == "" || request.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID, scopes, err := ac.twoFactor.CompleteLogin(request.MFAToken, request.Code)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// The account may have changed since the password step
+	user, err := ac.userRepo.FindByID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		return
+	}
+	if !ac.canLogIn(c, user) {
+		return
+	}
+
+	ac.respondWithToken(c, userID, scopes)
+}
+
+// canLogIn responds with 403 and returns false if the user's account may not log in.
+func (ac *AuthController) canLogIn(c *gin.Context, user *models.User) bool {
+	// Administrators can disable accounts and require a password reset
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return false
+	}
+	if user.MustResetPassword {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required, use the link sent by email or /password/forgot"})
+		return false
+	}
+
+	// Unverified users cannot log in if the policy requires it
+	if ac.verification != nil && ac.verification.Policy() == services.VerificationLogin && user.VerifiedAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+		return false
+	}
+	return true
+}
+
+// respondWithToken generates a JWT with the requested scopes, or every scope if none were requested.
+func (ac *AuthController) respondWithToken(c *gin.Context, userID uint, scopes []string) {
+	var token string
+	var err error
+	if len(scopes) > 0 {
+		token, err = ac.authService.GenerateScopedToken(userID, scopes)
+	} else {
+		token, err = ac.authService.GenerateToken(userID)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
 		return
@@ -149,22 +306,3 @@ func isValidEmail(email string) bool {
 	re := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	return re.MatchString(email)
 }
-
-// isValidPassword checks if the password meets the strength requirements.
-func isValidPassword(password string) bool {
-	if len(password) < 8 {
-		return false
-	}
-	hasUpper, hasNumber, hasSpecial := false, false, false
-	for _, char := range password {
-		switch {
-		case char >= 'A' && char <= 'Z':
-			hasUpper = true
-		case char >= '0' && char <= '9':
-			hasNumber = true
-		case (char >= '!' && char <= '/') || (char >= ':' && char <= '@') || (char >= '[' && char <= '`') || (char >= '{' && char <= '~'):
-			hasSpecial = true
-		}
-	}
-	return hasUpper && hasNumber && hasSpecial
-}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BoardController handles HTTP requests for the kanban boards of projects
+type BoardController struct {
+	service services.BoardService
+}
+
+// NewBoardController creates a new BoardController
+func NewBoardController(service services.BoardService) *BoardController {
+	return &BoardController{service: service}
+}
+
+// @Summary Get a project's board
+// @Description Returns the kanban board of a project the authenticated user is a member of: its columns in board order, each with the project's tasks of its status in manual order. Boards that were not configured have one column per status.
+// @Tags projects
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Board "Board with its columns and tasks"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/board [get]
+func (c *BoardController) GetBoard(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	board, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetBoard(uint(id), userID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, board)
+}
+
+// @Summary Configure a project's board
+// @Description Replaces the columns of a project's board. Columns are listed in board order and must cover every status exactly once; each has a name, a #RRGGBB color and an optional work-in-progress limit. Only the project owner can configure the board.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Param request body models.BoardColumnsRequest true "Columns of the board"
+// @Success 200 {array} models.BoardColumn "Columns of the board"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID or columns"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only the owner can configure the board"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/board [put]
+func (c *BoardController) SetColumns(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var request models.BoardColumnsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	columns, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).SetColumns(uint(id), userID, request.Columns)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can configure the board"})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, columns)
+}
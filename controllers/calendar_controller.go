@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarController handles iCalendar feed and import requests
+type CalendarController struct {
+	service services.CalendarService
+}
+
+// NewCalendarController creates a new CalendarController
+func NewCalendarController(service services.CalendarService) *CalendarController {
+	return &CalendarController{service: service}
+}
+
+// @Summary Create a calendar feed
+// @Description Creates a secret iCalendar feed URL for the authenticated user's tasks. The URL is shown only once; creating a new feed revokes the previous one.
+// @Tags calendar
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} models.CalendarFeedResponse "Feed created"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /calendar/feed [post]
+func (c *CalendarController) CreateFeed(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	token, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).CreateFeed(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	url := scheme + "://" + ctx.Request.Host + "/calendar/feed/" + token
+
+	ctx.JSON(http.StatusCreated, models.CalendarFeedResponse{URL: url, Token: token})
+}
+
+// @Summary Revoke the calendar feed
+// @Description Disables the authenticated user's feed URL
+// @Tags calendar
+// @Security ApiKeyAuth
+// @Success 204 "Feed revoked"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /calendar/feed [delete]
+func (c *CalendarController) RevokeFeed(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).RevokeFeed(userID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Get the calendar feed
+// @Description Serves the feed owner's tasks as iCalendar. The secret token in the URL replaces the bearer token.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token"
+// @Param type query string false "Component type: todo (VTODO for all tasks) or event (VEVENT for tasks with a due date)" default(todo)
+// @Success 200 {file} file "iCalendar feed"
+// @Failure 400 {object} models.ErrorResponse "Unsupported component type"
+// @Failure 403 {object} models.ErrorResponse "Account is disabled"
+// @Failure 404 {object} models.ErrorResponse "Feed not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /calendar/feed/{token} [get]
+func (c *CalendarController) GetFeed(ctx *gin.Context) {
+	component := ctx.DefaultQuery("type", services.CalendarTodos)
+	if component != services.CalendarTodos && component != services.CalendarEvents {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported component type"})
+		return
+	}
+
+	// Calendar apps often require the .ics extension
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+	feed, err := c.service.ResolveFeed(token)
+	if err != nil {
+		if err.Error() == "feed not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		} else if err.Error() == "account is disabled" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.Header("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	ctx.Status(http.StatusOK)
+
+	if err := c.service.ForOrganization(feed.OrganizationID).WriteFeed(feed.UserID, component, ctx.Writer); err != nil {
+		log.Printf("Calendar feed for user %d failed: %v", feed.UserID, err)
+		ctx.Abort()
+	}
+}
+
+// @Summary Import tasks from iCalendar
+// @Description Creates tasks from the VTODO components of an uploaded .ics file. Components whose UID matches an existing task update that task with the same checks as PUT /tasks/{id}.
+// @Tags calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "iCalendar file"
+// @Param dry_run formData bool false "Only validate the file"
+// @Success 200 {object} models.ImportReport "Dry run or rejected import report"
+// @Success 201 {object} models.ImportReport "Tasks imported"
+// @Failure 400 {object} models.ErrorResponse "Invalid file"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "The updates exceed a WIP limit"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/import/ics [post]
+func (c *CalendarController) ImportICS(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	dryRun := false
+	if raw := ctx.PostForm("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Could not read file"})
+		return
+	}
+	defer file.Close()
+
+	report, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).ImportICS(userID, file, dryRun)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.HasPrefix(err.Error(), "wip limit reached") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if report.Imported > 0 {
+		ctx.JSON(http.StatusCreated, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CustomFieldController handles HTTP requests for the custom fields of projects
+type CustomFieldController struct {
+	service services.CustomFieldService
+}
+
+// NewCustomFieldController creates a new CustomFieldController
+func NewCustomFieldController(service services.CustomFieldService) *CustomFieldController {
+	return &CustomFieldController{service: service}
+}
+
+// @Summary Get a project's custom fields
+// @Description Returns the custom fields of a project the authenticated user is a member of, in display order, with their validation rules
+// @Tags projects
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Success 200 {array} models.CustomField "Custom fields of the project"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/fields [get]
+func (c *CustomFieldController) GetFields(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	fields, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetFields(uint(id), userID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fields)
+}
+
+// @Summary Change a project's custom fields
+// @Description Replaces the custom fields of a project. Fields are listed in display order and identified by their key, which is derived from the name if left out. Fields that are left out are deleted together with their values on the project's tasks, and kept fields cannot change type. Only the project owner can change the fields.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Param request body models.CustomFieldsRequest true "Fields in display order"
+// @Success 200 {array} models.CustomField "New custom fields of the project"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID or fields"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only the owner can change the custom fields"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/fields [put]
+func (c *CustomFieldController) SetFields(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var request models.CustomFieldsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).SetFields(uint(id), userID, request.Fields)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can change the custom fields"})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fields)
+}
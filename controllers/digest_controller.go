@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// DigestController handles digest email settings and unsubscribe links
+type DigestController struct {
+	service services.DigestService
+}
+
+// NewDigestController creates a new DigestController
+func NewDigestController(service services.DigestService) *DigestController {
+	return &DigestController{service: service}
+}
+
+// @Summary Get digest settings
+// @Description Returns how often and when the user receives digest emails. Digests are off until the user chooses a frequency.
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.DigestSettings "Digest settings"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/digest [get]
+func (c *DigestController) GetSettings(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	settings, err := c.service.GetSettings(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update digest settings
+// @Description Chooses the digest frequency (off, daily or weekly), the local send time, the weekday of weekly digests and the time zone. Omitted fields keep their value.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.DigestSettingsRequest true "Digest settings to change"
+// @Success 200 {object} models.DigestSettings "Updated settings with the next send time"
+// @Failure 400 {object} models.ErrorResponse "Invalid frequency, send time, weekday or time zone"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/digest [put]
+func (c *DigestController) UpdateSettings(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.DigestSettingsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := c.service.UpdateSettings(userID, request)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// @Summary Unsubscribe from digests
+// @Description Opened from the unsubscribe link in a digest email, or posted by mail clients that support one-click unsubscribe (RFC 8058). Turns digests off without logging in.
+// @Tags notifications
+// @Produce json
+// @Param token query string true "Unsubscribe token from the email"
+// @Success 200 {object} models.MessageResponse "Digests turned off"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired unsubscribe link"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /digest/unsubscribe [get]
+// @Router /digest/unsubscribe [post]
+func (c *DigestController) Unsubscribe(ctx *gin.Context) {
+	if err := c.service.Unsubscribe(ctx.Query("token")); err != nil {
+		if err.Error() == "invalid or expired unsubscribe link" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired unsubscribe link"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "You will no longer receive digest emails"})
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationController handles email verification links
+type EmailVerificationController struct {
+	service services.EmailVerificationService
+}
+
+// NewEmailVerificationController creates a new EmailVerificationController
+func NewEmailVerificationController(service services.EmailVerificationService) *EmailVerificationController {
+	return &EmailVerificationController{service: service}
+}
+
+// @Summary Verify an email address
+// @Description Opened from the link in the verification email. Links sent for an email change switch the account to the new address.
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token from the email"
+// @Success 200 {object} models.MessageResponse "Email verified"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired verification link"
+// @Failure 409 {object} models.ErrorResponse "New address was registered by another account"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /email/verify [get]
+func (vc *EmailVerificationController) VerifyEmail(c *gin.Context) {
+	if err := vc.service.Verify(c.Query("token")); err != nil {
+		if err.Error() == "invalid or expired verification link" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		} else if err.Error() == "email address is already in use" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email address is already in use"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+// @Summary Resend the verification email
+// @Description Sends a new verification link to an unverified address. Only one email can be requested every two minutes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailResendRequest true "Account email"
+// @Success 202 {object} models.MessageResponse "Verification email sent if the address is unverified"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 429 {object} models.ErrorResponse "Requested too soon, see Retry-After"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /email/verify/resend [post]
+func (vc *EmailVerificationController) ResendVerification(c *gin.Context) {
+	var request models.EmailResendRequest
+	if err := c.ShouldBindJSON(&request); err != nil || !isValidEmail(request.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := vc.service.Resend(request.Email); err != nil {
+		var throttled *services.ThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not send verification email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address is registered and unverified, a verification link has been sent"})
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportController handles imports from other task trackers
+type ImportController struct {
+	service services.ImportService
+}
+
+// NewImportController creates a new ImportController
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// @Summary Import tasks from another tool
+// @Description Imports an uploaded export file: a Trello board export, a Todoist backup or a GitHub issues JSON array. Boards, projects and repositories become projects, labels become tags and lists, sections or issue states become statuses. Everything is created in a single transaction; if any item is invalid nothing is saved.
+// @Tags import
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source path string true "Source tool (trello, todoist, github)"
+// @Param file formData file true "Export file"
+// @Param project formData string false "Project for items that have none, e.g. the repository of a gh CLI export"
+// @Param dry_run formData bool false "Only validate the file"
+// @Success 200 {object} models.ImportReport "Dry run or rejected import report"
+// @Success 201 {object} models.ImportReport "Tasks imported"
+// @Failure 400 {object} models.ErrorResponse "Invalid file or source"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /import/{source} [post]
+func (c *ImportController) Import(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	source := ctx.Param("source")
+	if !services.IsValidImportSource(source) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported source"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	dryRun := false
+	if raw := ctx.PostForm("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Could not read file"})
+		return
+	}
+	defer file.Close()
+
+	report, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).Import(userID, source, file, ctx.PostForm("project"), dryRun)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if report.Imported > 0 {
+		ctx.JSON(http.StatusCreated, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationController handles HTTP requests for organization invitations
+type InvitationController struct {
+	service services.InvitationService
+}
+
+// NewInvitationController creates a new InvitationController
+func NewInvitationController(service services.InvitationService) *InvitationController {
+	return &InvitationController{service: service}
+}
+
+// parseInvitationID reads the ":invitationId" path parameter, responding with 400 if it is not a valid ID
+func parseInvitationID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("invitationId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary Invite someone to an organization
+// @Description Emails an invitation link that is valid for a week and can be used once. Owners and admins can invite members; only owners can invite owners and admins. A pending invitation to the same address is replaced.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param request body models.InvitationRequest true "Email and role"
+// @Success 201 {object} models.Invitation "Invitation sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid email or role"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 409 {object} models.ErrorResponse "User is already a member"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/invitations [post]
+func (c *InvitationController) Invite(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.InvitationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isValidEmail(request.Email) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	invitation, err := c.service.Invite(organizationID, userID, request.Email, request.Role)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, invitation)
+}
+
+// @Summary List pending invitations
+// @Description Lists the organization's invitations that have been neither accepted nor revoked and have not expired, newest first. Only owners and admins can see them.
+// @Tags organizations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Success 200 {array} models.Invitation "Pending invitations"
+// @Failure 400 {object} models.ErrorResponse "Invalid organization ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/invitations [get]
+func (c *InvitationController) ListPending(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	invitations, err := c.service.ListPending(organizationID, userID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+// @Summary Revoke an invitation
+// @Description Deletes an invitation so its link stops working
+// @Tags organizations
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param invitationId path int true "Invitation ID"
+// @Success 204 "Invitation revoked"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization or invitation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/invitations/{invitationId} [delete]
+func (c *InvitationController) Revoke(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	invitationID, ok := parseInvitationID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.Revoke(organizationID, userID, invitationID); err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Resend an invitation
+// @Description Emails the invitation again with a new link valid for another week; the previous link stops working
+// @Tags organizations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param invitationId path int true "Invitation ID"
+// @Success 200 {object} models.Invitation "Invitation sent again"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID or invitation already accepted"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization or invitation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/invitations/{invitationId}/resend [post]
+func (c *InvitationController) Resend(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	invitationID, ok := parseInvitationID(ctx)
+	if !ok {
+		return
+	}
+
+	invitation, err := c.service.Resend(organizationID, userID, invitationID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitation)
+}
+
+// @Summary Show an invitation
+// @Description Opened from the emailed invitation link without signing in. Returns the organization, address and role of a pending invitation; to join, sign in and post the token to /invitations/accept, or pass it as invitation_token to /register.
+// @Tags organizations
+// @Produce json
+// @Param token query string true "Invitation token from the email"
+// @Success 200 {object} models.Invitation "Pending invitation"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired invitation"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /invitations/accept [get]
+func (c *InvitationController) Show(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	invitation, err := c.service.Lookup(token)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitation)
+}
+
+// @Summary Accept an invitation
+// @Description Joins the organization with the invited role. The token from the emailed link can be used once, before it expires, by the user whose address it was sent to. People without an account pass the token as invitation_token to /register instead.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.InvitationAcceptRequest true "Invitation token"
+// @Success 200 {object} models.OrganizationMember "Invitation accepted"
+// @Failure 400 {object} models.ErrorResponse "Invalid, expired or someone else's invitation"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "User is already a member"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /invitations/accept [post]
+func (c *InvitationController) Accept(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.InvitationAcceptRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || request.Token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	member, err := c.service.Accept(request.Token, userID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationController handles HTTP requests for the notification inbox
+type NotificationController struct {
+	service services.NotificationService
+}
+
+// NewNotificationController creates a new NotificationController
+func NewNotificationController(service services.NotificationService) *NotificationController {
+	return &NotificationController{service: service}
+}
+
+// respondNotificationError maps notification service errors to HTTP responses
+func respondNotificationError(ctx *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err.Error() == "notification not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// @Summary List notifications
+// @Description Returns one page of the user's notifications, newest first, with the number of unread notifications in the inbox.
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Notifications per page (max 100)" default(20)
+// @Success 200 {object} models.NotificationPage "Page of notifications"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications [get]
+func (c *NotificationController) List(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter, err := models.ParseNotificationFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.service.List(userID, filter)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Count unread notifications
+// @Description Returns the number of unread notifications, e.g. for a badge.
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.UnreadCountResponse "Unread notifications"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications/unread-count [get]
+func (c *NotificationController) UnreadCount(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	count, err := c.service.UnreadCount(userID)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UnreadCountResponse{Unread: count})
+}
+
+// @Summary Mark a notification as read
+// @Description Marks one of the user's notifications as read. Marking a read notification again keeps the time it was first read.
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Param id path int true "Notification ID"
+// @Success 204 "Notification marked as read"
+// @Failure 400 {object} models.ErrorResponse "Invalid notification ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Notification not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications/{id}/read [post]
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := c.service.MarkRead(userID, uint(id)); err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Mark all notifications as read
+// @Description Marks every unread notification of the user as read.
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.NotificationsMarkedResponse "Number of notifications marked as read"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications/read-all [post]
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	marked, err := c.service.MarkAllRead(userID)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.NotificationsMarkedResponse{Marked: marked})
+}
+
+// @Summary Get notification preferences
+// @Description Returns whether each notification type (task_assigned, mentioned, task_updated, reminder) is on. All types are on by default.
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]bool "Notification types and whether they are on"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications/preferences [get]
+func (c *NotificationController) GetPreferences(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	preferences, err := c.service.GetPreferences(userID)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
+
+// @Summary Update notification preferences
+// @Description Turns notification types on or off. Types missing from the body keep their setting.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body map[string]bool true "Notification types to turn on (true) or off (false)"
+// @Success 200 {object} map[string]bool "Updated preferences"
+// @Failure 400 {object} models.ErrorResponse "Unknown notification type"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /notifications/preferences [put]
+func (c *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request map[string]bool
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := c.service.UpdatePreferences(userID, request)
+	if err != nil {
+		respondNotificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
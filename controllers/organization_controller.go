@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationController handles HTTP requests for organizations and their members
+type OrganizationController struct {
+	service     services.OrganizationService
+	authService services.AuthService
+}
+
+// NewOrganizationController creates a new OrganizationController
+func NewOrganizationController(service services.OrganizationService, authService services.AuthService) *OrganizationController {
+	return &OrganizationController{service: service, authService: authService}
+}
+
+// respondOrganizationError maps organization service errors to HTTP responses
+func respondOrganizationError(ctx *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err.Error() == "organization not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case err.Error() == "user not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case err.Error() == "member not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case err.Error() == "invitation not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+	case err.Error() == "forbidden":
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Your role in the organization does not allow this"})
+	case err.Error() == "user is already a member":
+		ctx.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseOrganizationID reads the ":id" path parameter, responding with 400 if it is not a valid ID
+func parseOrganizationID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary Create an organization
+// @Description Creates an organization; the authenticated user becomes its owner
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.OrganizationRequest true "Organization name"
+// @Success 201 {object} models.Organization "Organization created"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations [post]
+func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.OrganizationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := c.service.CreateOrganization(request.Name, userID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, organization)
+}
+
+// @Summary Get the user's organizations
+// @Description Lists the authenticated user's memberships with their organizations, oldest first. The first one is active when a request selects none.
+// @Tags organizations
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.OrganizationMember "List of memberships"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations [get]
+func (c *OrganizationController) GetOrganizations(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	memberships, err := c.service.GetUserOrganizations(userID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, memberships)
+}
+
+// @Summary Get an organization by ID
+// @Description Returns an organization with its members if the authenticated user is a member
+// @Tags organizations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Success 200 {object} models.Organization "Organization found"
+// @Failure 400 {object} models.ErrorResponse "Invalid organization ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id} [get]
+func (c *OrganizationController) GetOrganization(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	organization, err := c.service.GetOrganization(organizationID, userID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organization)
+}
+
+// @Summary Rename an organization
+// @Description Changes the name of an organization. Only owners and admins can rename it.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param request body models.OrganizationRequest true "New name"
+// @Success 200 {object} models.Organization "Organization renamed"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only owners and admins can rename the organization"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id} [put]
+func (c *OrganizationController) RenameOrganization(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.OrganizationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := c.service.Rename(organizationID, userID, request.Name)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organization)
+}
+
+// @Summary Add a member to an organization
+// @Description Adds a registered user to the organization. Owners and admins can add members; only owners can add admins and owners.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param request body models.OrganizationMemberRequest true "Member email and role"
+// @Success 201 {object} models.OrganizationMember "Member added"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization or user not found"
+// @Failure 409 {object} models.ErrorResponse "User is already a member"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members [post]
+func (c *OrganizationController) AddMember(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.OrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := c.service.AddMember(organizationID, userID, request.Email, request.Role)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, member)
+}
+
+// @Summary Change the role of a member
+// @Description Makes a member an owner, admin or member. Only owners can change roles, and the last owner cannot step down.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Param request body models.OrganizationRoleRequest true "New role"
+// @Success 200 {object} models.OrganizationMember "Role changed"
+// @Failure 400 {object} models.ErrorResponse "Invalid role or last owner"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only owners can change roles"
+// @Failure 404 {object} models.ErrorResponse "Organization or member not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members/{userId} [put]
+func (c *OrganizationController) ChangeMemberRole(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseUint(ctx.Param("userId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request models.OrganizationRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := c.service.ChangeMemberRole(organizationID, userID, uint(memberID), request.Role)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// @Summary Remove a member from an organization
+// @Description Removes a user from the organization together with their project memberships and calendar feed in it; projects they own are handed over. Owners and admins can remove others, only owners can remove owners, and every member can leave unless they are the last owner.
+// @Tags organizations
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Success 204 "Member removed"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID or last owner"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow this"
+// @Failure 404 {object} models.ErrorResponse "Organization or member not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members/{userId} [delete]
+func (c *OrganizationController) RemoveMember(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseUint(ctx.Param("userId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := c.service.RemoveMember(organizationID, userID, uint(memberID)); err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Issue a token for an organization
+// @Description Returns a JWT with the same scopes and expiry as the current one whose org claim makes the organization active, so clients need not send the X-Organization-ID header. API keys cannot be exchanged; send the header with them instead.
+// @Tags organizations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Organization ID"
+// @Success 200 {object} models.TokenResponse "Token for the organization"
+// @Failure 400 {object} models.ErrorResponse "Invalid organization ID or API key used"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/token [post]
+func (c *OrganizationController) IssueToken(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	organizationID, ok := parseOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if middleware.UsesAPIKey(ctx) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "API keys select the organization with the " + middleware.OrganizationHeader + " header"})
+		return
+	}
+
+	member, err := c.service.ResolveMembership(userID, organizationID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+	if member == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	// The new token ends when the current one does, so exchanges cannot extend a session
+	claims := services.TokenClaims{UserID: userID, Scopes: middleware.GetScopes(ctx)}
+	if issuedAt, ok := ctx.Get("issuedAt"); ok {
+		claims.IssuedAt = issuedAt.(time.Time)
+	}
+	if expiresAt, ok := ctx.Get("expiresAt"); ok {
+		claims.ExpiresAt = expiresAt.(time.Time)
+	}
+	token, err := c.authService.ReissueToken(claims, organizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"token": token})
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordController handles the forgotten password flow
+type PasswordController struct {
+	service services.PasswordResetService
+}
+
+// NewPasswordController creates a new PasswordController
+func NewPasswordController(service services.PasswordResetService) *PasswordController {
+	return &PasswordController{service: service}
+}
+
+// @Summary Request a password reset
+// @Description Emails a single-use reset link valid for one hour. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordForgotRequest true "Account email"
+// @Success 202 {object} models.MessageResponse "Reset email sent if the account exists"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /password/forgot [post]
+func (pc *PasswordController) ForgotPassword(c *gin.Context) {
+	var request models.PasswordForgotRequest
+	if err := c.ShouldBindJSON(&request); err != nil || !isValidEmail(request.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := pc.service.RequestReset(request.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not send reset email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a reset link has been sent"})
+}
+
+// @Summary Reset a password
+// @Description Sets a new password using the emailed reset token. The token can be used once, and every existing login token of the account is revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordResetRequest true "Reset token and new password"
+// @Success 200 {object} models.MessageResponse "Password changed"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data, expired token or password rejected by the policy"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /password/reset [post]
+func (pc *PasswordController) ResetPassword(c *gin.Context) {
+	var request models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := pc.service.ResetPassword(request.Token, request.Password); err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error()})
+		} else if err.Error() == "invalid or expired reset token" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectController handles HTTP requests for projects and their members
+type ProjectController struct {
+	service services.ProjectService
+}
+
+// NewProjectController creates a new ProjectController
+func NewProjectController(service services.ProjectService) *ProjectController {
+	return &ProjectController{service: service}
+}
+
+// @Summary Create a new project
+// @Description Create a project; the authenticated user becomes its owner
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body object{name=string,description=string} true "Project data"
+// @Success 201 {object} models.Project "Project created successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects [post]
+func (c *ProjectController) CreateProject(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project := &models.Project{Name: request.Name, Description: request.Description, OwnerID: userID}
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).CreateProject(project); err != nil {
+		if err.Error() == "project name cannot be empty" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, project)
+}
+
+// @Summary Get the user's projects
+// @Description Lists all projects the authenticated user is a member of
+// @Tags projects
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Project "List of projects"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects [get]
+func (c *ProjectController) GetProjects(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	projects, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetUserProjects(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, projects)
+}
+
+// @Summary Get a project by ID
+// @Description Returns a project with its members if the authenticated user is a member
+// @Tags projects
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project "Project found"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id} [get]
+func (c *ProjectController) GetProject(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	project, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetProject(uint(id), userID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, project)
+}
+
+// @Summary Add a member to a project
+// @Description Adds a member of the active organization to the project. Only the project owner can add members.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Param request body object{email=string,role=string} true "Member data"
+// @Success 201 {object} models.ProjectMember "Member added"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only the owner can manage members"
+// @Failure 404 {object} models.ErrorResponse "Project or user not found"
+// @Failure 409 {object} models.ErrorResponse "User is already a member"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/members [post]
+func (c *ProjectController) AddMember(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var request struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).AddMember(uint(id), userID, request.Email, request.Role)
+	if err != nil {
+		switch err.Error() {
+		case "project not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "forbidden":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can manage members"})
+		case "invalid role":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		case "user is not a member of this organization":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this organization"})
+		case "user is already a member":
+			ctx.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, member)
+}
+
+// @Summary Remove a member from a project
+// @Description Removes a user from the project. Only the project owner can remove members.
+// @Tags projects
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Success 204 "Member removed"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only the owner can manage members"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/members/{userId} [delete]
+func (c *ProjectController) RemoveMember(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+	memberID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).RemoveMember(uint(id), userID, uint(memberID)); err != nil {
+		switch err.Error() {
+		case "project not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case "forbidden":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can manage members"})
+		case "owner cannot be removed":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Owner cannot be removed"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SavedViewController handles HTTP requests for saved task views
+type SavedViewController struct {
+	service services.SavedViewService
+}
+
+// NewSavedViewController creates a new SavedViewController
+func NewSavedViewController(service services.SavedViewService) *SavedViewController {
+	return &SavedViewController{service: service}
+}
+
+// savedViewRequest is the request body for creating and updating views
+type savedViewRequest struct {
+	Name      string `json:"name"`
+	Query     string `json:"query"`
+	ProjectID *uint  `json:"project_id"`
+}
+
+// @Summary Create a saved view
+// @Description Save a named filter and sort combination. The query uses the same parameters as GET /tasks.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body object{name=string,query=string,project_id=int} true "View data"
+// @Success 201 {object} models.SavedView "View created successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "You are not a member of this project"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views [post]
+func (c *SavedViewController) CreateView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request savedViewRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view := &models.SavedView{Name: request.Name, Query: request.Query, ProjectID: request.ProjectID, UserID: userID}
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).CreateView(view); err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, view)
+}
+
+// @Summary Get saved views
+// @Description Lists the user's own views and views shared with the user's projects
+// @Tags views
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.SavedView "List of views"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views [get]
+func (c *SavedViewController) GetViews(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	views, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetUserViews(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, views)
+}
+
+// @Summary Get a saved view by ID
+// @Tags views
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "View ID"
+// @Success 200 {object} models.SavedView "View found"
+// @Failure 400 {object} models.ErrorResponse "Invalid view ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "View not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/{id} [get]
+func (c *SavedViewController) GetView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return
+	}
+
+	view, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetView(uint(id), userID)
+	if err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// @Summary Update a saved view
+// @Description Only the creator of a view can update it
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "View ID"
+// @Param request body object{name=string,query=string,project_id=int} true "View data"
+// @Success 200 {object} models.SavedView "View updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid request data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "View not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/{id} [put]
+func (c *SavedViewController) UpdateView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return
+	}
+
+	var request savedViewRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view := &models.SavedView{ID: uint(id), Name: request.Name, Query: request.Query, ProjectID: request.ProjectID}
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).UpdateView(view, userID); err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// @Summary Delete a saved view
+// @Description Only the creator of a view can delete it
+// @Tags views
+// @Security ApiKeyAuth
+// @Param id path int true "View ID"
+// @Success 204 "View deleted successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid view ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "View not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/{id} [delete]
+func (c *SavedViewController) DeleteView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return
+	}
+
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).DeleteView(uint(id), userID); err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Get the tasks of a saved view
+// @Description Applies the view's filter and sort to the authenticated user's tasks
+// @Tags views
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "View ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} models.TaskListResponse "Page of tasks"
+// @Failure 400 {object} models.ErrorResponse "Invalid view ID or pagination"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "View not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/{id}/tasks [get]
+func (c *SavedViewController) GetViewTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return
+	}
+
+	pagination, err := models.ParseTaskFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetViewTasks(uint(id), userID, pagination.Page, pagination.PageSize)
+	if err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Set the default view
+// @Description Marks a view as the default for GET /tasks
+// @Tags views
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "View ID"
+// @Success 200 {object} models.MessageResponse "Default view set"
+// @Failure 400 {object} models.ErrorResponse "Invalid view ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "View not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/{id}/default [post]
+func (c *SavedViewController) SetDefaultView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return
+	}
+
+	viewID := uint(id)
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).SetDefaultView(userID, &viewID); err != nil {
+		respondViewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Default view set"})
+}
+
+// @Summary Clear the default view
+// @Description GET /tasks returns the plain task list again
+// @Tags views
+// @Security ApiKeyAuth
+// @Success 204 "Default view cleared"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /views/default [delete]
+func (c *SavedViewController) ClearDefaultView(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).SetDefaultView(userID, nil); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// respondViewError maps saved view service errors to HTTP responses
+func respondViewError(ctx *gin.Context, err error) {
+	switch {
+	case err.Error() == "view not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+	case err.Error() == "forbidden":
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case err.Error() == "view name cannot be empty", strings.HasPrefix(err.Error(), "invalid view query"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/EmelinDanila/task-manager-api/middleware"
 	"github.com/EmelinDanila/task-manager-api/models"
@@ -13,6 +14,7 @@ import (
 // TaskController handles HTTP requests for task management
 type TaskController struct {
 	Service services.TaskService
+	Views   services.SavedViewService // Optional, provides the user's default view
 }
 
 // NewTaskController sets up the task routes
@@ -27,15 +29,16 @@ func NewTaskController(router *gin.Engine, service services.TaskService) {
 }
 
 // @Summary Create a new task
-// @Description Create a new task for the authenticated user
+// @Description Create a new task for the authenticated user, optionally assigned to members of its project, or of the organization for tasks outside projects
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param request body object{title=string,description=string,status=string} true "Task data"
+// @Param request body object{title=string,description=string,status=string,project_id=int,due_date=string,priority=string,important=bool,urgent=bool,effort_minutes=int,custom_fields=object,assignee_ids=[]int} true "Task data"
 // @Success 201 {object} models.TaskResponse "Task created successfully"
 // @Failure 400 {object} models.ErrorResponse "Invalid request data"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden: You are not a member of this project"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /tasks [post]
 func (c *TaskController) CreateTask(ctx *gin.Context) {
@@ -52,9 +55,19 @@ func (c *TaskController) CreateTask(ctx *gin.Context) {
 	}
 
 	task.UserID = userID
+	// The organization, the position and the iCalendar UID are set by the server
+	task.OrganizationID = 0
+	task.Rank = ""
+	task.ICalUID = ""
 
-	if err := c.Service.CreateTask(&task); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).CreateTask(&task); err != nil {
+		if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this project"})
+		} else if err.Error() == "task title cannot be empty" || strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -62,7 +75,7 @@ func (c *TaskController) CreateTask(ctx *gin.Context) {
 }
 
 // @Summary Get a task by ID
-// @Description Retrieves a specific task by ID for the authenticated user
+// @Description Retrieves a specific task by ID if the authenticated user created it or is assigned to it
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -88,7 +101,7 @@ func (c *TaskController) GetTaskByID(ctx *gin.Context) {
 		return
 	}
 
-	task, err := c.Service.GetTaskByID(uint(id), userID)
+	task, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).GetTaskByID(uint(id), userID)
 	if err != nil {
 		if err.Error() == "task not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
@@ -104,11 +117,29 @@ func (c *TaskController) GetTaskByID(ctx *gin.Context) {
 }
 
 // @Summary Get all tasks for the authenticated user
+// @Description Returns a page of the tasks the user created, is assigned to or watches. Without filter or sort parameters the user's default view is applied. Together with project_id, tasks can be filtered by the project's custom fields with cf.<key>=value, or cf.<key>.gte and cf.<key>.lte for number and date fields, and sorted with sort=cf.<key>.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param status query string false "Filter by status"
+// @Param status_category query string false "Filter by status category (todo, doing, done)"
+// @Param q query string false "Search in title and description"
+// @Param project_id query int false "Filter by project"
+// @Param assignee_id query int false "Filter by assignee"
+// @Param watcher_id query int false "Filter by watcher"
+// @Param priority query string false "Filter by priority (P0 to P4)"
+// @Param important query bool false "Filter by importance"
+// @Param urgent query bool false "Filter by urgency"
+// @Param due_before query string false "Due before (RFC3339)"
+// @Param due_after query string false "Due after (RFC3339)"
+// @Param overdue query bool false "Only overdue tasks"
+// @Param sort query string false "Sort field (created_at, updated_at, title, status, due_date, priority, effort, rank, or cf.<key> with a project_id)"
+// @Param order query string false "Sort order (asc, desc)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size (max 100)"
 // @Success 200 {object} models.TaskListResponse "List of tasks for the authenticated user"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /tasks [get]
@@ -119,28 +150,54 @@ func (c *TaskController) GetAllTasks(ctx *gin.Context) {
 		return
 	}
 
-	tasks, err := c.Service.GetUserTasks(userID)
+	query := ctx.Request.URL.Query()
+	filter, err := models.ParseTaskFilter(query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, tasks)
+	// Apply the default view when the request does not select tasks itself
+	if c.Views != nil && !models.HasFilterParams(query) {
+		defaultFilter, err := c.Views.ForOrganization(middleware.GetOrganizationID(ctx)).GetDefaultFilter(userID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if defaultFilter != nil {
+			defaultFilter.Page = filter.Page
+			defaultFilter.PageSize = filter.PageSize
+			filter = *defaultFilter
+		}
+	}
+
+	page, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).ListTasks(userID, filter)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
 }
 
 // @Summary Update an existing task
-// @Description Update a task only if the authenticated user is the owner of the task
+// @Description Update a task if the authenticated user created it or is assigned to it. Fields that are left out keep their current value; project_id 0 takes the task out of its project and a null due_date clears it. Assignees of a task moved to another project must be members of that project. Moving a task to a board column at its WIP limit fails unless override_wip is set. Custom field values are merged into the task's values, and null clears a field.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "Task ID"
-// @Param request body object{title=string,description=string,status=string} true "Updated task data"
+// @Param request body models.TaskUpdateRequest true "Fields to change"
 // @Success 200 {object} models.TaskResponse "Task updated successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid task ID or request data"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID, request data or assignee"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden: You cannot update another user's task"
 // @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 409 {object} models.ErrorResponse "Board column is at its WIP limit"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /tasks/{id} [put]
 func (c *TaskController) UpdateTask(ctx *gin.Context) {
@@ -156,19 +213,22 @@ func (c *TaskController) UpdateTask(ctx *gin.Context) {
 		return
 	}
 
-	var task models.Task
-	if err := ctx.ShouldBindJSON(&task); err != nil {
+	var request models.TaskUpdateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	task.ID = uint(id)
-
-	if err := c.Service.UpdateTask(&task, userID); err != nil {
+	task, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).UpdateTask(uint(id), userID, request)
+	if err != nil {
 		if err.Error() == "forbidden" {
 			ctx.JSON(http.StatusForbidden, gin.H{"error": "You cannot update another user's task"})
 		} else if err.Error() == "task not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else if strings.HasPrefix(err.Error(), "wip limit reached") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -179,7 +239,7 @@ func (c *TaskController) UpdateTask(ctx *gin.Context) {
 }
 
 // @Summary Delete a task
-// @Description Delete a task only if the authenticated user is the owner of the task
+// @Description Delete a task only if the authenticated user created it
 // @Tags tasks
 // @Security ApiKeyAuth
 // @Param id path int true "Task ID"
@@ -203,7 +263,7 @@ func (c *TaskController) DeleteTask(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.Service.DeleteTask(uint(id), userID); err != nil {
+	if err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).DeleteTask(uint(id), userID); err != nil {
 		if err.Error() == "forbidden" {
 			ctx.JSON(http.StatusForbidden, gin.H{"error": "You cannot delete another user's task"})
 		} else if err.Error() == "task not found" {
@@ -216,3 +276,397 @@ func (c *TaskController) DeleteTask(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// @Summary Assign a task
+// @Description Replaces the assignees of a task. The creator and the current assignees can reassign it. Assignees must be members of the task's project, or of the organization for tasks outside projects. Every change is recorded in the assignment history.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Param request body models.TaskAssigneesRequest true "New assignees"
+// @Success 200 {object} models.TaskResponse "Task with its new assignees"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID, request data or assignee"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden: You cannot reassign another user's task"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/assignees [put]
+func (c *TaskController) SetAssignees(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var request models.TaskAssigneesRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).SetAssignees(uint(id), userID, request.UserIDs)
+	if err != nil {
+		if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You cannot reassign another user's task"})
+		} else if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+// @Summary Get the assignment history of a task
+// @Description Lists who was assigned to and unassigned from the task, and by whom, oldest first
+// @Tags tasks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Success 200 {array} models.TaskAssignmentChange "Assignment history"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/assignments [get]
+func (c *TaskController) GetAssignmentHistory(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	changes, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).GetAssignmentHistory(uint(id), userID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
+
+// @Summary Get the tasks assigned to the authenticated user
+// @Description Returns a page of the tasks assigned to the user in the active organization, across all projects. Accepts the filter, sort and pagination parameters of GET /tasks, except assignee_id.
+// @Tags tasks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "Filter by status"
+// @Param status_category query string false "Filter by status category (todo, doing, done)"
+// @Param q query string false "Search in title and description"
+// @Param project_id query int false "Filter by project"
+// @Param overdue query bool false "Only overdue tasks"
+// @Param sort query string false "Sort field (created_at, updated_at, title, status, due_date, priority, effort, rank, or cf.<key> with a project_id)"
+// @Param order query string false "Sort order (asc, desc)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} models.TaskListResponse "Tasks assigned to the authenticated user"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/assigned [get]
+func (c *TaskController) GetAssignedTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter, err := models.ParseTaskFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).ListAssigned(userID, filter)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Watch a task
+// @Description Follows a task to be notified when it changes. Users can watch the tasks they see and the tasks of projects they are members of; watched tasks become visible to them. Creators and assignees watch their tasks automatically. Watching a task again changes nothing.
+// @Tags tasks
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Success 204 "Watching the task"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/watch [post]
+func (c *TaskController) WatchTask(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).WatchTask(uint(id), userID); err != nil {
+		if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Stop watching a task
+// @Description Stops notifications about changes to a task. Creators and assignees keep access to the task.
+// @Tags tasks
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Success 204 "No longer watching the task"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/watch [delete]
+func (c *TaskController) UnwatchTask(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).UnwatchTask(uint(id), userID); err != nil {
+		if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Get the tasks the authenticated user watches
+// @Description Returns a page of the tasks the user watches in the active organization. Accepts the filter, sort and pagination parameters of GET /tasks, except watcher_id.
+// @Tags tasks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "Filter by status"
+// @Param status_category query string false "Filter by status category (todo, doing, done)"
+// @Param q query string false "Search in title and description"
+// @Param project_id query int false "Filter by project"
+// @Param assignee_id query int false "Filter by assignee"
+// @Param overdue query bool false "Only overdue tasks"
+// @Param sort query string false "Sort field (created_at, updated_at, title, status, due_date, priority, effort, rank, or cf.<key> with a project_id)"
+// @Param order query string false "Sort order (asc, desc)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size (max 100)"
+// @Success 200 {object} models.TaskListResponse "Tasks the authenticated user watches"
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /me/watching [get]
+func (c *TaskController) GetWatchingTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter, err := models.ParseTaskFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).ListWatching(userID, filter)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary Set the blockers of a task
+// @Description Replaces the tasks that must be completed before this one. The creator and the assignees can change them. Blockers must be tasks the user can see and must not form a cycle.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Param request body models.TaskBlockersRequest true "New blockers"
+// @Success 200 {object} models.TaskResponse "Task with its new blockers"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID, request data or blocker"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden: You cannot change another user's task"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/blockers [put]
+func (c *TaskController) SetBlockers(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var request models.TaskBlockersRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).SetBlockers(uint(id), userID, request.TaskIDs)
+	if err != nil {
+		if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You cannot change another user's task"})
+		} else if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+// @Summary Get the tasks to do next
+// @Description Ranks the open tasks assigned to the user, and those the user created without assigning them, in the active organization. The score adds points for priority (P0 40 to P4 0), due date (overdue 40, within 24 hours 30, 3 days 20, 7 days 10), importance (15), urgency (10) and 5 for each open task waiting on the task (at most 20). Tasks waiting for open blockers are left out. Ties go to the earlier due date, then the smaller effort estimate.
+// @Tags tasks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Number of tasks (default 10, max 50)"
+// @Success 200 {array} models.RankedTask "Tasks with their scores, best first"
+// @Failure 400 {object} models.ErrorResponse "Invalid limit"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/next [get]
+func (c *TaskController) GetNextTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit := models.DefaultNextTasks
+	if raw := ctx.Query("limit"); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil || l < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = l
+	}
+
+	tasks, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).NextTasks(userID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+// @Summary Move a task in its list
+// @Description Places a task between two tasks of its list, the tasks of its project (or without a project) with the same status, and optionally moves it to another status. Only the moved task changes. Without anchors the task goes to the end of the list. Sort by rank to get the manual order. Moving to a board column at its WIP limit fails unless override_wip is set.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task ID"
+// @Param request body models.TaskMoveRequest true "Anchors and target status"
+// @Success 200 {object} models.TaskResponse "Task with its new rank"
+// @Failure 400 {object} models.ErrorResponse "Invalid task ID, status or anchors"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden: You cannot move another user's task"
+// @Failure 404 {object} models.ErrorResponse "Task not found"
+// @Failure 409 {object} models.ErrorResponse "Board column is at its WIP limit"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/{id}/move [post]
+func (c *TaskController) MoveTask(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var request models.TaskMoveRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := c.Service.ForOrganization(middleware.GetOrganizationID(ctx)).MoveTask(uint(id), userID, request)
+	if err != nil {
+		if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You cannot move another user's task"})
+		} else if err.Error() == "task not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else if strings.HasPrefix(err.Error(), "wip limit reached") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// transferContentTypes maps transfer formats to response content types
+var transferContentTypes = map[string]string{
+	services.FormatCSV:    "text/csv; charset=utf-8",
+	services.FormatJSON:   "application/json; charset=utf-8",
+	services.FormatNDJSON: "application/x-ndjson; charset=utf-8",
+}
+
+// TaskTransferController handles task export and import requests
+type TaskTransferController struct {
+	service services.TaskTransferService
+}
+
+// NewTaskTransferController creates a new TaskTransferController
+func NewTaskTransferController(service services.TaskTransferService) *TaskTransferController {
+	return &TaskTransferController{service: service}
+}
+
+// @Summary Export tasks
+// @Description Streams all tasks the authenticated user created as CSV, a JSON array or newline-delimited JSON. Tasks the user is only assigned to or watches are not included. JSON and NDJSON tasks include their tags, assignees and blockers like GET /tasks; CSV leaves them out.
+// @Tags tasks
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Security ApiKeyAuth
+// @Param format query string false "Export format (csv, json, ndjson)" default(json)
+// @Success 200 {file} file "Exported tasks"
+// @Failure 400 {object} models.ErrorResponse "Unsupported format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Router /tasks/export [get]
+func (c *TaskTransferController) ExportTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", services.FormatJSON)
+	if !services.IsValidTransferFormat(format) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format"})
+		return
+	}
+
+	ctx.Header("Content-Type", transferContentTypes[format])
+	ctx.Header("Content-Disposition", `attachment; filename="tasks.`+format+`"`)
+	ctx.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure can only cut the stream short
+	if err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).Export(userID, format, ctx.Writer); err != nil {
+		log.Printf("Task export for user %d failed: %v", userID, err)
+		ctx.Abort()
+	}
+}
+
+// @Summary Import tasks
+// @Description Imports tasks from an uploaded CSV, JSON or NDJSON file in a single transaction. Rows are validated first; if any row is invalid nothing is saved and the report lists the errors.
+// @Tags tasks
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "File with tasks"
+// @Param format formData string false "File format (csv, json, ndjson); defaults to the file extension"
+// @Param mapping formData string false "JSON object mapping task fields to source columns, e.g. {\"title\":\"Name\"}"
+// @Param dry_run formData bool false "Only validate the file"
+// @Success 200 {object} models.ImportReport "Dry run or rejected import report"
+// @Success 201 {object} models.ImportReport "Tasks imported"
+// @Failure 400 {object} models.ErrorResponse "Invalid file, format or mapping"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /tasks/import [post]
+func (c *TaskTransferController) ImportTasks(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	format := ctx.PostForm("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if !services.IsValidTransferFormat(format) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format"})
+		return
+	}
+
+	mapping := map[string]string{}
+	if raw := ctx.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Mapping must be a JSON object of strings"})
+			return
+		}
+	}
+
+	dryRun := false
+	if raw := ctx.PostForm("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Could not read file"})
+		return
+	}
+	defer file.Close()
+
+	report, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).Import(userID, format, file, mapping, dryRun)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if report.Imported > 0 {
+		ctx.JSON(http.StatusCreated, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
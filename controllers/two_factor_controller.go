@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorController handles two-factor authentication settings
+type TwoFactorController struct {
+	service services.TwoFactorService
+}
+
+// NewTwoFactorController creates a new TwoFactorController
+func NewTwoFactorController(service services.TwoFactorService) *TwoFactorController {
+	return &TwoFactorController{service: service}
+}
+
+// respondTwoFactorError maps two-factor service errors to HTTP responses
+func respondTwoFactorError(ctx *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "two-factor authentication is"):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// @Summary Start two-factor setup
+// @Description Creates a new TOTP secret and otpauth URI for an authenticator app. Two-factor authentication is enabled only after /2fa/confirm.
+// @Tags 2fa
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.TOTPEnrollment "Secret and otpauth URI"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "Two-factor authentication is already enabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /2fa/enroll [post]
+func (c *TwoFactorController) Enroll(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	enrollment, err := c.service.Enroll(userID)
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Confirm two-factor setup
+// @Description Enables two-factor authentication with a code from the authenticator app and returns ten one-time recovery codes. The codes are shown only once.
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} models.RecoveryCodesResponse "Two-factor authentication enabled"
+// @Failure 400 {object} models.ErrorResponse "Invalid code"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "Not set up or already enabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /2fa/confirm [post]
+func (c *TwoFactorController) Confirm(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := c.service.Confirm(userID, request.Code)
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Turns off two-factor authentication after checking a TOTP or recovery code. Remaining recovery codes are deleted.
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} models.MessageResponse "Two-factor authentication disabled"
+// @Failure 400 {object} models.ErrorResponse "Invalid code"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "Two-factor authentication is not enabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /2fa/disable [post]
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.Disable(userID, request.Code); err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
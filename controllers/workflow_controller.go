@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkflowController handles HTTP requests for the status workflows of projects
+type WorkflowController struct {
+	service services.WorkflowService
+}
+
+// NewWorkflowController creates a new WorkflowController
+func NewWorkflowController(service services.WorkflowService) *WorkflowController {
+	return &WorkflowController{service: service}
+}
+
+// @Summary Get a project's workflow
+// @Description Returns the statuses of a project the authenticated user is a member of, in workflow order, with their categories and allowed transitions. Projects without custom statuses use Pending, In Progress and Completed with every transition allowed.
+// @Tags projects
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Workflow "Workflow of the project"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/workflow [get]
+func (c *WorkflowController) GetWorkflow(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	workflow, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).GetWorkflow(uint(id), userID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, workflow)
+}
+
+// @Summary Change a project's workflow
+// @Description Replaces the statuses of a project. Statuses are listed in workflow order, new tasks start in the first one, and at least one must be in the done category. Tasks of removed statuses move to the status given in status_mapping, or else to the first new status of the same category. Only the project owner can change the workflow.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Project ID"
+// @Param request body models.WorkflowRequest true "Statuses and status mapping"
+// @Success 200 {object} models.Workflow "New workflow of the project"
+// @Failure 400 {object} models.ErrorResponse "Invalid project ID, statuses or status mapping"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only the owner can change the workflow"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /projects/{id}/workflow [put]
+func (c *WorkflowController) SetWorkflow(ctx *gin.Context) {
+	userID, exists := middleware.GetUserID(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var request models.WorkflowRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workflow, err := c.service.ForOrganization(middleware.GetOrganizationID(ctx)).SetWorkflow(uint(id), userID, request)
+	if err != nil {
+		if err.Error() == "project not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else if err.Error() == "forbidden" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can change the workflow"})
+		} else if strings.HasPrefix(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, workflow)
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/EmelinDanila/task-manager-api/config"
 	"github.com/EmelinDanila/task-manager-api/migrations"
+	"github.com/EmelinDanila/task-manager-api/repository"
 	"github.com/EmelinDanila/task-manager-api/routes"
 	"github.com/gin-gonic/gin"
 )
@@ -42,9 +44,16 @@ func main() {
 
 	migrations.Migrate(db.GetDB())
 
+	// Tenant separation: statements on organization data are scoped to the active organization
+	tenantScope := repository.TenantScope{RowLevelSecurity: os.Getenv("TENANT_RLS") == "true"}
+	if err := db.GetDB().Use(tenantScope); err != nil {
+		log.Fatalf("Could not register tenant scope: %v", err)
+	}
+
 	router := gin.Default()
 
-	routes.SetupRoutes(router, db.GetDB())
+	jobs := routes.SetupRoutes(router, db.GetDB())
+	jobs.Start()
 
 	if err := router.Run(":8080"); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditAdmin creates a Gin middleware that records every request to the admin routes in the
+// audit log once it has been handled, including rejected ones. The user in the ":id" path
+// parameter is the target unless the handler sets one with SetAuditTarget.
+// It must run after AuthMiddleware.
+func AuditAdmin(audit services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		actorID, _ := GetUserID(c)
+		entry := &models.AuditEntry{
+			ActorID: actorID,
+			Action:  c.Request.Method + " " + c.FullPath(),
+			Status:  c.Writer.Status(),
+			IP:      c.ClientIP(),
+			Details: map[string]string{},
+		}
+		for _, param := range c.Params {
+			entry.Details[param.Key] = param.Value
+		}
+		for key, values := range c.Request.URL.Query() {
+			entry.Details[key] = values[0]
+		}
+		if details, ok := c.Get("auditDetails"); ok {
+			for key, value := range details.(map[string]string) {
+				entry.Details[key] = value
+			}
+		}
+		if len(entry.Details) == 0 {
+			entry.Details = nil
+		}
+
+		if target, ok := c.Get("auditTarget"); ok {
+			targetID := target.(uint)
+			entry.TargetUserID = &targetID
+		} else if id, err := strconv.ParseUint(c.Param("id"), 10, 64); err == nil {
+			targetID := uint(id)
+			entry.TargetUserID = &targetID
+		}
+
+		// The response is already sent, so a failure can only be logged
+		if err := audit.Record(entry); err != nil {
+			log.Printf("Could not record admin action %q of user %d: %v", entry.Action, actorID, err)
+		}
+	}
+}
+
+// SetAuditTarget sets the user an admin action is about, for actions without an ":id" path parameter
+func SetAuditTarget(c *gin.Context, userID uint) {
+	c.Set("auditTarget", userID)
+}
+
+// AddAuditDetail adds a value from the request body to the audit log entry of an admin action
+func AddAuditDetail(c *gin.Context, key, value string) {
+	details, ok := c.Get("auditDetails")
+	if !ok {
+		details = map[string]string{}
+		c.Set("auditDetails", details)
+	}
+	details.(map[string]string)[key] = value
+}
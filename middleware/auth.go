@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -8,8 +9,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware creates a Gin middleware for authentication
-func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
+// AuthMiddleware creates a Gin middleware for authentication.
+// The bearer token may be a JWT or, when apiKeyService is set, an API key.
+func AuthMiddleware(authService services.AuthService, apiKeyService services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract the Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -28,16 +30,37 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		// API keys carry their own scopes
+		if apiKeyService != nil && services.IsAPIKey(token) {
+			key, err := apiKeyService.Authenticate(token)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+				c.Abort()
+				return
+			}
+			c.Set("userID", key.UserID)
+			c.Set("apiKeyID", key.ID)
+			c.Set("scopes", key.Scopes)
+			c.Next()
+			return
+		}
+
 		// Verify the token
-		userID, err := authService.VerifyToken(token)
+		claims, err := authService.VerifyTokenClaims(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Set the user ID in the context for later use
-		c.Set("userID", userID)
+		// Set the user ID, scopes, lifetime and selected organization in the context for later use
+		c.Set("userID", claims.UserID)
+		c.Set("scopes", claims.Scopes)
+		c.Set("issuedAt", claims.IssuedAt)
+		c.Set("expiresAt", claims.ExpiresAt)
+		if claims.OrganizationID != 0 {
+			c.Set("tokenOrganizationID", claims.OrganizationID)
+		}
 		c.Next()
 	}
 }
@@ -50,3 +73,61 @@ func GetUserID(c *gin.Context) (uint, bool) {
 	}
 	return userID.(uint), true
 }
+
+// UsesAPIKey reports whether the request was authenticated with an API key
+func UsesAPIKey(c *gin.Context) bool {
+	_, exists := c.Get("apiKeyID")
+	return exists
+}
+
+// GetScopes retrieves the scopes of the current token from the Gin context
+func GetScopes(c *gin.Context) []string {
+	scopes, exists := c.Get("scopes")
+	if !exists {
+		return nil
+	}
+	return scopes.([]string)
+}
+
+// HasScope reports whether the current token was granted the scope
+func HasScope(c *gin.Context, scope string) bool {
+	for _, s := range GetScopes(c) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScopes creates a Gin middleware that allows the request only if the token
+// has every given scope. It must run after AuthMiddleware.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	required := strings.Join(scopes, " ")
+	return func(c *gin.Context) {
+		for _, scope := range scopes {
+			if !HasScope(c, scope) {
+				// RFC 6750: tell the client which scope is missing
+				c.Header("WWW-Authenticate", fmt.Sprintf(
+					`Bearer error="insufficient_scope", scope="%s", error_description="The token requires the %s scope"`,
+					required, scope))
+				c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient scope"})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireLoginToken creates a Gin middleware that rejects requests authenticated with an API
+// key, for routes that only the signed-in user may use. It must run after AuthMiddleware.
+func RequireLoginToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if UsesAPIKey(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used for this request, log in instead"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoginBodySize limits how much of a login request is buffered to read the account
+const maxLoginBodySize = 1 << 20
+
+// LoginAccount finds the account a login request is for in its body, or returns an empty
+// string if there is none
+type LoginAccount func(body []byte) string
+
+// EmailAccount reads the account of a password login from the email in its body
+func EmailAccount(body []byte) string {
+	var credentials struct {
+		Email string `json:"email"`
+	}
+	_ = json.Unmarshal(body, &credentials)
+	return credentials.Email
+}
+
+// MFATokenAccount reads the account of a second-factor login from the user its mfa_token was
+// issued to, so wrong codes count against the account and not only the client IP
+func MFATokenAccount(twoFactor services.TwoFactorService) LoginAccount {
+	return func(body []byte) string {
+		var request struct {
+			MFAToken string `json:"mfa_token"`
+		}
+		if json.Unmarshal(body, &request) != nil || request.MFAToken == "" {
+			return ""
+		}
+		return twoFactor.LoginAccount(request.MFAToken)
+	}
+}
+
+// LoginThrottle creates a Gin middleware that rejects logins for locked accounts and IPs
+// with 429 and a Retry-After header, and counts 401 responses as failed attempts.
+func LoginThrottle(service services.LoginThrottleService, account LoginAccount) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Read the account without consuming the body for the handler
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoginBodySize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		email := account(body)
+		ip := c.ClientIP()
+
+		wait, err := service.Check(email, ip)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check login attempts"})
+			c.Abort()
+			return
+		}
+		if wait > 0 {
+			tooManyAttempts(c, wait)
+			return
+		}
+
+		c.Next()
+
+		switch c.Writer.Status() {
+		case http.StatusUnauthorized:
+			if _, err := service.RecordFailure(email, ip); err != nil {
+				log.Printf("Could not record failed login: %v", err)
+			}
+		case http.StatusOK:
+			if err := service.RecordSuccess(email); err != nil {
+				log.Printf("Could not reset failed logins: %v", err)
+			}
+		}
+	}
+}
+
+// tooManyAttempts responds with 429 and the number of seconds to wait
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+	c.Abort()
+}
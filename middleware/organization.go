@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHeader selects the active organization of a request
+const OrganizationHeader = "X-Organization-ID"
+
+// ActiveOrganization creates a Gin middleware that selects the organization whose data the
+// request works on: the one named by the X-Organization-ID header, else the one named by the
+// token's org claim, else the user's oldest organization. Users who are not members of it are
+// rejected. It must run after AuthMiddleware.
+func ActiveOrganization(organizationService services.OrganizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		var organizationID uint
+		if header := c.GetHeader(OrganizationHeader); header != "" {
+			id, err := strconv.ParseUint(header, 10, 32)
+			if err != nil || id == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + OrganizationHeader + " header"})
+				c.Abort()
+				return
+			}
+			organizationID = uint(id)
+		} else if id, ok := c.Get("tokenOrganizationID"); ok {
+			organizationID = id.(uint)
+		}
+
+		member, err := organizationService.ResolveMembership(userID, organizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load organization"})
+			c.Abort()
+			return
+		}
+		if member == nil {
+			message := "You are not a member of this organization"
+			if organizationID == 0 {
+				message = "You are not a member of any organization"
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
+		c.Set("organizationID", member.OrganizationID)
+		c.Set("organizationRole", member.Role)
+		c.Next()
+	}
+}
+
+// GetOrganizationID retrieves the active organization from the Gin context. It is zero
+// outside of ActiveOrganization, which makes organization data inaccessible.
+func GetOrganizationID(c *gin.Context) uint {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		return 0
+	}
+	return organizationID.(uint)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// ActiveSession creates a Gin middleware that rejects login tokens and API keys of deleted
+// and disabled users, and tokens issued before the user's sessions were revoked, e.g. by a
+// password reset.
+// The loaded user is stored in the context. It must run after AuthMiddleware.
+func ActiveSession(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.FindByID(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load user"})
+			c.Abort()
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+		if user.DisabledAt != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+			c.Abort()
+			return
+		}
+
+		// API keys have no issue time and are revoked separately
+		if issuedAt, ok := c.Get("issuedAt"); ok && user.SessionsRevokedAt != nil {
+			if issuedAt.(time.Time).Unix() <= user.SessionsRevokedAt.Unix() {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked, please log in again"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("user", user)
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail creates a Gin middleware that only lets users with a verified
+// email address through. It must run after ActiveSession.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists || user.(*models.User).VerifiedAt == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin creates a Gin middleware that only lets administrators through.
+// It must run after ActiveSession.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists || user.(*models.User).Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -5,14 +5,158 @@ import (
 	"log"
 
 	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
 	"gorm.io/gorm"
 )
 
 // Migrate выполняет все миграции
 func Migrate(db *gorm.DB) {
+	// Creators and assignees of existing tasks start watching them when watchers are introduced
+	watchersExisted := db.Migrator().HasTable(&models.TaskWatcher{})
+	// Existing tasks get ranks in their creation order when manual ordering is introduced
+	ranksExisted := db.Migrator().HasColumn(&models.Task{}, "Rank")
+	// Existing tasks get the categories of their built-in statuses when workflows are introduced
+	categoriesExisted := db.Migrator().HasColumn(&models.Task{}, "StatusCategory")
+
 	// Автоматически создает таблицы на основе моделей
-	if err := db.AutoMigrate(&models.User{}, &models.Task{}); err != nil { // Проверяем ошибку непосредственно
+	if err := db.AutoMigrate(&models.User{}, &models.Organization{}, &models.OrganizationMember{}, &models.Invitation{}, &models.Task{}, &models.TaskAssignee{}, &models.TaskAssignmentChange{}, &models.TaskWatcher{}, &models.TaskBlocker{}, &models.Notification{}, &models.NotificationPreference{}, &models.DigestSettings{}, &models.Project{}, &models.ProjectMember{}, &models.BoardColumn{}, &models.WorkflowStatus{}, &models.WorkflowTransition{}, &models.CustomField{}, &models.SavedView{}, &models.CalendarFeed{}, &models.Tag{}, &models.APIKey{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.ErasureRecord{}, &models.AuditEntry{}); err != nil { // Проверяем ошибку непосредственно
 		log.Fatalf("Migration failed: %v", err)
 	}
+	if err := migrateOrganizations(db); err != nil {
+		log.Fatalf("Organization migration failed: %v", err)
+	}
+	if !watchersExisted {
+		if err := migrateWatchers(db); err != nil {
+			log.Fatalf("Watcher migration failed: %v", err)
+		}
+	}
+	if !ranksExisted {
+		if err := migrateRanks(db); err != nil {
+			log.Fatalf("Rank migration failed: %v", err)
+		}
+	}
+	if !categoriesExisted {
+		if err := migrateStatusCategories(db); err != nil {
+			log.Fatalf("Status category migration failed: %v", err)
+		}
+	}
 	fmt.Println("Database migration completed successfully!")
 }
+
+// organizationTables hold organization data and are protected by row-level security
+var organizationTables = []string{"tasks", "projects", "project_members", "tags", "calendar_feeds", "invitations", "task_assignees", "task_assignment_changes", "task_watchers", "task_blockers", "board_columns", "workflow_statuses", "workflow_transitions", "custom_fields"}
+
+// firstOrganization selects the oldest organization of the user in the given column of the outer query
+func firstOrganization(userColumn string) string {
+	return "(SELECT m.organization_id FROM organization_members m WHERE m.user_id = " + userColumn +
+		" ORDER BY m.created_at, m.id LIMIT 1)"
+}
+
+// migrateOrganizations moves data created before organizations existed into them and
+// installs the row-level security policies. Every step can be run again safely.
+func migrateOrganizations(db *gorm.DB) error {
+	// Tag names and calendar feeds used to be unique per user, now per user and organization
+	for _, index := range []string{"idx_user_tag", "idx_calendar_feeds_user_id"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			return err
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Every user gets a personal organization
+		var userIDs []uint
+		err := tx.Raw("SELECT id FROM users WHERE id NOT IN (SELECT user_id FROM organization_members) ORDER BY id").Scan(&userIDs).Error
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			organization := &models.Organization{Name: repository.PersonalOrganizationName}
+			if err := tx.Omit("Members").Create(organization).Error; err != nil {
+				return err
+			}
+			owner := &models.OrganizationMember{OrganizationID: organization.ID, UserID: userID, Role: models.OrganizationRoleOwner}
+			if err := tx.Omit("Organization").Create(owner).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, statement := range []string{
+			// Projects move to their owner's organization, and their members join it
+			"UPDATE projects SET organization_id = " + firstOrganization("projects.owner_id") +
+				" WHERE COALESCE(organization_id, 0) = 0",
+			"INSERT INTO organization_members (organization_id, user_id, role, created_at) " +
+				"SELECT DISTINCT projects.organization_id, project_members.user_id, 'member', NOW() " +
+				"FROM project_members JOIN projects ON projects.id = project_members.project_id " +
+				"ON CONFLICT DO NOTHING",
+			"UPDATE project_members SET organization_id = projects.organization_id FROM projects " +
+				"WHERE projects.id = project_members.project_id AND COALESCE(project_members.organization_id, 0) = 0",
+			// Tasks follow their project, everything else its user
+			"UPDATE tasks SET organization_id = projects.organization_id FROM projects " +
+				"WHERE projects.id = tasks.project_id AND COALESCE(tasks.organization_id, 0) = 0",
+			"UPDATE tasks SET organization_id = " + firstOrganization("tasks.user_id") + " WHERE COALESCE(organization_id, 0) = 0",
+			"UPDATE tags SET organization_id = " + firstOrganization("tags.user_id") + " WHERE COALESCE(organization_id, 0) = 0",
+			"UPDATE calendar_feeds SET organization_id = " + firstOrganization("calendar_feeds.user_id") + " WHERE COALESCE(organization_id, 0) = 0",
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Backstop for the repository scoping: once a transaction sets app.organization_id,
+	// Postgres only shows and accepts rows of that organization. Sessions that do not set
+	// it, such as migrations and system-wide jobs, are not restricted.
+	const organizationSetting = "NULLIF(current_setting('app.organization_id', true), '')"
+	policy := organizationSetting + " IS NULL OR organization_id = " + organizationSetting + "::bigint"
+	for _, table := range organizationTables {
+		for _, statement := range []string{
+			"ALTER TABLE " + table + " ENABLE ROW LEVEL SECURITY",
+			"ALTER TABLE " + table + " FORCE ROW LEVEL SECURITY",
+			"DROP POLICY IF EXISTS tenant_isolation ON " + table,
+			"CREATE POLICY tenant_isolation ON " + table + " USING (" + policy + ") WITH CHECK (" + policy + ")",
+		} {
+			if err := db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateWatchers lets the creators and assignees of existing tasks watch them. It runs once,
+// when the watchers table is created, so users who stop watching later are not added back.
+func migrateWatchers(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			"INSERT INTO task_watchers (task_id, user_id, organization_id, created_at) " +
+				"SELECT id, user_id, organization_id, NOW() FROM tasks WHERE user_id <> 0 AND deleted_at IS NULL " +
+				"ON CONFLICT DO NOTHING",
+			"INSERT INTO task_watchers (task_id, user_id, organization_id, created_at) " +
+				"SELECT task_id, user_id, organization_id, NOW() FROM task_assignees " +
+				"ON CONFLICT DO NOTHING",
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateRanks gives existing tasks evenly spread ranks, ordered by creation within each
+// project and status.
+func migrateRanks(db *gorm.DB) error {
+	_, err := repository.NewTaskRepository(repository.AllOrganizations(db)).RebalanceLongRanks()
+	return err
+}
+
+// migrateStatusCategories gives existing tasks the category of their status. Before custom
+// workflows, In Progress was the only status being worked on and Completed the only done one.
+func migrateStatusCategories(db *gorm.DB) error {
+	return db.Exec("UPDATE tasks SET status_category = CASE status WHEN ? THEN ? WHEN ? THEN ? ELSE ? END",
+		models.StatusInProgress, models.StatusCategoryDoing, models.StatusCompleted, models.StatusCategoryDone, models.StatusCategoryTodo).Error
+}
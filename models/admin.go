@@ -0,0 +1,154 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// UserFilter describes searching and pagination options for the admin user list
+type UserFilter struct {
+	Search   string // Case-insensitive search in the email address
+	Role     string // Only users with this role
+	Disabled *bool  // Only disabled or only active users
+	Page     int    // 1-based page number
+	PageSize int    // Number of users per page
+}
+
+// UserPage is the pagination envelope of the admin user list
+type UserPage struct {
+	Users    []User `json:"users"`
+	Total    int64  `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+// ParseUserFilter builds a UserFilter from URL query values such as "q=example.com&role=admin&disabled=false&page=2"
+func ParseUserFilter(values url.Values) (UserFilter, error) {
+	filter := UserFilter{Search: values.Get("q"), Role: values.Get("role")}
+
+	if filter.Role != "" && !IsValidRole(filter.Role) {
+		return filter, errors.New("role must be user or admin")
+	}
+
+	if raw := values.Get("disabled"); raw != "" {
+		disabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("disabled must be true or false")
+		}
+		filter.Disabled = &disabled
+	}
+
+	page, pageSize, err := parsePagination(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.Page, filter.PageSize = page, pageSize
+	return filter, nil
+}
+
+// Offset returns the number of rows to skip for the current page
+func (f UserFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// RoleChangeRequest is the body of PUT /admin/users/{id}/role
+type RoleChangeRequest struct {
+	Role string `json:"role" example:"admin"` // user or admin
+}
+
+// UserStats counts users for the admin statistics
+type UserStats struct {
+	Total     int64 `json:"total"`
+	Admins    int64 `json:"admins"`
+	Disabled  int64 `json:"disabled"`
+	Verified  int64 `json:"verified"`
+	TwoFactor int64 `json:"two_factor"`
+}
+
+// TaskStats counts tasks of all users for the admin statistics
+type TaskStats struct {
+	Total            int64            `json:"total"`
+	ByStatus         map[string]int64 `json:"by_status"`
+	ByCategory       map[string]int64 `json:"by_category"`
+	Overdue          int64            `json:"overdue"`
+	CreatedLast7Days int64            `json:"created_last_7_days"`
+}
+
+// SystemStats is the response of GET /admin/stats
+type SystemStats struct {
+	Users    UserStats `json:"users"`
+	Tasks    TaskStats `json:"tasks"`
+	Projects int64     `json:"projects"`
+}
+
+// AuditEntry records a request to an admin endpoint
+// @Description Audit log entry of an administrator action.
+// @property ActorID uint "ID of the administrator"
+// @property Action string "Method and route, e.g. POST /admin/users/:id/disable"
+// @property TargetUserID uint "ID of the user the action was about (optional)"
+// @property Details object "Path and query parameters and action-specific values"
+// @property Status int "HTTP status of the response"
+// @property IP string "Client IP of the administrator"
+type AuditEntry struct {
+	ID           uint              `gorm:"primaryKey" json:"id"`
+	ActorID      uint              `gorm:"index;not null" json:"actor_id"`
+	Action       string            `gorm:"index;not null" json:"action"`
+	TargetUserID *uint             `gorm:"index" json:"target_user_id"`
+	Details      map[string]string `gorm:"serializer:json;type:text" json:"details,omitempty"`
+	Status       int               `json:"status"`
+	IP           string            `json:"ip"`
+	CreatedAt    time.Time         `gorm:"index" json:"created_at"`
+}
+
+// AuditFilter describes filtering and pagination options for the audit log
+type AuditFilter struct {
+	ActorID      *uint // Only actions of this administrator
+	TargetUserID *uint // Only actions about this user
+	Page         int   // 1-based page number
+	PageSize     int   // Number of entries per page
+}
+
+// AuditPage is the pagination envelope of the audit log
+type AuditPage struct {
+	Entries  []AuditEntry `json:"entries"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// ParseAuditFilter builds an AuditFilter from URL query values such as "actor_id=1&target_user_id=7&page=2"
+func ParseAuditFilter(values url.Values) (AuditFilter, error) {
+	var filter AuditFilter
+
+	if raw := values.Get("actor_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid actor_id")
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+
+	if raw := values.Get("target_user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid target_user_id")
+		}
+		targetID := uint(id)
+		filter.TargetUserID = &targetID
+	}
+
+	page, pageSize, err := parsePagination(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.Page, filter.PageSize = page, pageSize
+	return filter, nil
+}
+
+// Offset returns the number of rows to skip for the current page
+func (f AuditFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
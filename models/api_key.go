@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// APIKey is a personal access token for scripts and CI.
+// Only the SHA-256 hash of the key is stored; Prefix lets users tell keys apart.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"not null" json:"prefix"`
+	KeyHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes     []string   `gorm:"serializer:json;type:text" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Expired reports whether the key has an expiry date in the past
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// APIKeyCreateRequest is the body of POST /api-keys
+type APIKeyCreateRequest struct {
+	Name          string   `json:"name" example:"CI pipeline"`
+	Scopes        []string `json:"scopes" example:"tasks:read,tasks:write"`
+	ExpiresInDays int      `json:"expires_in_days" example:"90"` // 0 means the key never expires
+}
+
+// APIKeyCreateResponse is returned once when a key is created; Key is never shown again
+type APIKeyCreateResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
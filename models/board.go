@@ -0,0 +1,91 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// MaxBoardColumnName is the maximum length of a board column name
+const MaxBoardColumnName = 50
+
+// boardColorPattern matches colors written as #RRGGBB
+var boardColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// defaultColumnColors are the colors of columns without a configured color, by status category
+var defaultColumnColors = map[string]string{
+	StatusCategoryTodo:  "#9e9e9e",
+	StatusCategoryDoing: "#2196f3",
+	StatusCategoryDone:  "#4caf50",
+}
+
+// BoardColumn is a column of a project's kanban board. Each column shows the project's tasks
+// with one status.
+// @Description Column of a project's kanban board, mapped to a task status.
+// @property Status string "Task status shown in the column"
+// @property Name string "Display name of the column"
+// @property Position int "Position of the column on the board, from 0"
+// @property Color string "Color of the column as #RRGGBB"
+// @property WIPLimit int "Maximum number of tasks in the column, null for no limit"
+type BoardColumn struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	ProjectID      uint      `gorm:"uniqueIndex:idx_board_column" json:"project_id"`
+	Status         string    `gorm:"uniqueIndex:idx_board_column;not null" json:"status"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	Name           string    `gorm:"not null" json:"name"`
+	Position       int       `gorm:"not null;default:0" json:"position"`
+	Color          string    `gorm:"not null;default:''" json:"color"`
+	WIPLimit       *int      `json:"wip_limit"`
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
+}
+
+// OrganizationScoped marks board columns as belonging to an organization
+func (BoardColumn) OrganizationScoped() {}
+
+// DefaultBoardColumn returns the column of a status on boards where it was not configured:
+// named after the status, colored after its category and without a work-in-progress limit
+func DefaultBoardColumn(projectID uint, status WorkflowStatus) BoardColumn {
+	return BoardColumn{ProjectID: projectID, Status: status.Name, Name: status.Name, Position: status.Position, Color: DefaultColumnColor(status.Category)}
+}
+
+// DefaultColumnColor returns the color of columns of the status category without a configured color
+func DefaultColumnColor(category string) string {
+	return defaultColumnColors[category]
+}
+
+// IsValidColumnColor reports whether the color is written as #RRGGBB
+func IsValidColumnColor(color string) bool {
+	return boardColorPattern.MatchString(color)
+}
+
+// BoardColumnRequest configures one column in PUT /projects/{id}/board
+type BoardColumnRequest struct {
+	Status   string `json:"status" example:"In Progress"` // Task status shown in the column
+	Name     string `json:"name" example:"Doing"`         // Display name; empty for the status
+	Color    string `json:"color" example:"#2196f3"`      // #RRGGBB; empty for the default color
+	WIPLimit *int   `json:"wip_limit" example:"3"`        // Maximum number of tasks; null for no limit
+}
+
+// BoardColumnsRequest is the body of PUT /projects/{id}/board. The columns are listed in
+// board order and must cover every status of the project's workflow exactly once.
+type BoardColumnsRequest struct {
+	Columns []BoardColumnRequest `json:"columns"`
+}
+
+// BoardColumnTasks is a column of a board with its tasks in manual order
+// @Description Board column with its tasks in manual order.
+// @property TaskCount int "Number of tasks in the column"
+// @property OverLimit bool "Whether the column holds more tasks than its WIP limit"
+// @property Tasks []Task "Tasks of the column, ordered by rank"
+type BoardColumnTasks struct {
+	BoardColumn
+	TaskCount int    `json:"task_count"`
+	OverLimit bool   `json:"over_limit"`
+	Tasks     []Task `json:"tasks"`
+}
+
+// Board is a project's kanban board returned by GET /projects/{id}/board
+type Board struct {
+	ProjectID uint               `json:"project_id"`
+	Columns   []BoardColumnTasks `json:"columns"`
+}
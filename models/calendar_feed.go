@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CalendarFeed is a secret, revocable URL that serves a user's tasks of one organization
+// as iCalendar. Only the SHA-256 hash of the token is stored.
+type CalendarFeed struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_calendar_feed_user" json:"user_id"`
+	OrganizationID uint      `gorm:"uniqueIndex:idx_calendar_feed_user" json:"organization_id"`
+	TokenHash      string    `gorm:"uniqueIndex;not null" json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationScoped marks calendar feeds as belonging to an organization
+func (CalendarFeed) OrganizationScoped() {}
+
+// CalendarFeedResponse is returned once when a feed is created
+type CalendarFeedResponse struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
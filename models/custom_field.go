@@ -0,0 +1,128 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// Custom field types
+const (
+	FieldTypeText        = "text"
+	FieldTypeNumber      = "number"
+	FieldTypeDate        = "date"
+	FieldTypeSelect      = "select"
+	FieldTypeMultiSelect = "multi_select"
+	FieldTypeUser        = "user"
+	FieldTypeCheckbox    = "checkbox"
+)
+
+// CustomFieldTypes lists all valid custom field types
+var CustomFieldTypes = []string{FieldTypeText, FieldTypeNumber, FieldTypeDate, FieldTypeSelect, FieldTypeMultiSelect, FieldTypeUser, FieldTypeCheckbox}
+
+// IsValidCustomFieldType reports whether the type is one of CustomFieldTypes
+func IsValidCustomFieldType(fieldType string) bool {
+	for _, t := range CustomFieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits of custom fields
+const (
+	MaxCustomFields    = 30   // Fields per project
+	MaxCustomFieldName = 50   // Characters in a field name
+	MaxFieldOptions    = 50   // Options of a select field
+	MaxFieldOption     = 50   // Characters in an option
+	MaxTextFieldLength = 1000 // Characters in a text value
+)
+
+// FieldDateLayout is the format of date field values
+const FieldDateLayout = "2006-01-02"
+
+// CustomFieldPrefix starts the query parameters and sort keys of custom fields, e.g. "cf.customer"
+const CustomFieldPrefix = "cf."
+
+// customFieldKeyPattern matches keys such as "story_points"
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// IsValidCustomFieldKey reports whether the key starts with a lowercase letter and has at most
+// 40 lowercase letters, digits and underscores
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+// CustomField defines a field the tasks of a project can have. Values are stored on the
+// tasks under the field's key.
+// @Description Custom field of a project's tasks with its validation rules.
+// @property Key string "Key of the field in the tasks' custom_fields, e.g. story_points"
+// @property Name string "Display name of the field"
+// @property Type string "text, number, date, select, multi_select, user or checkbox"
+// @property Required bool "Whether new tasks need a value"
+// @property Options []string "Options of select and multi_select fields"
+// @property Min number "Smallest value of a number field (optional)"
+// @property Max number "Largest value of a number field (optional)"
+// @property MaxLength int "Maximum length of a text field, 1000 by default"
+// @property Position int "Position of the field, from 0"
+type CustomField struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	ProjectID      uint      `gorm:"uniqueIndex:idx_custom_field" json:"-"`
+	Key            string    `gorm:"uniqueIndex:idx_custom_field;not null" json:"key"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	Name           string    `gorm:"not null" json:"name"`
+	Type           string    `gorm:"not null" json:"type"`
+	Required       bool      `gorm:"not null;default:false" json:"required"`
+	Options        []string  `gorm:"serializer:json;type:text" json:"options,omitempty"`
+	Min            *float64  `json:"min,omitempty"`
+	Max            *float64  `json:"max,omitempty"`
+	MaxLength      *int      `json:"max_length,omitempty"`
+	Position       int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
+}
+
+// OrganizationScoped marks custom fields as belonging to an organization
+func (CustomField) OrganizationScoped() {}
+
+// HasOption reports whether the option is one of the field's options
+func (f CustomField) HasOption(option string) bool {
+	for _, o := range f.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldValues holds the custom field values of a task by field key. Text, date and select
+// values are strings, numbers and users are numbers, multi_select values are lists of
+// strings and checkboxes are booleans.
+type FieldValues map[string]interface{}
+
+// CustomFieldRequest defines one field in PUT /projects/{id}/fields
+type CustomFieldRequest struct {
+	Key       string   `json:"key" example:"story_points"` // Derived from the name if empty
+	Name      string   `json:"name" example:"Story points"`
+	Type      string   `json:"type" example:"number"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options"`                 // select and multi_select only
+	Min       *float64 `json:"min"`                     // number only
+	Max       *float64 `json:"max" example:"100"`       // number only
+	MaxLength *int     `json:"max_length" example:"80"` // text only
+}
+
+// CustomFieldsRequest is the body of PUT /projects/{id}/fields. The fields are listed in
+// display order; fields left out are deleted together with their values.
+type CustomFieldsRequest struct {
+	Fields []CustomFieldRequest `json:"fields"`
+}
+
+// CustomFieldFilter selects tasks by the value of a custom field, given as a query parameter
+// such as "cf.customer=Acme" or "cf.story_points.gte=3"
+type CustomFieldFilter struct {
+	Key   string // Key of the field
+	Op    string // "eq", "gte" or "lte"
+	Value string // Value as given in the query
+	Type  string // Type of the field, set once the project's fields are known
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Digest frequencies
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Defaults of digest settings; digests are off until the user chooses a frequency
+const (
+	DefaultDigestSendTime = "08:00"
+	DefaultDigestWeekday  = time.Monday
+	DefaultDigestTimeZone = "UTC"
+)
+
+// DigestSettings is when a user receives digest emails
+// @Description Digest email settings of the current user.
+// @property Frequency string "off, daily or weekly"
+// @property SendTime string "Local time of day the digest is sent, HH:MM"
+// @property Weekday int "Day of the week weekly digests are sent, 0 (Sunday) to 6 (Saturday)"
+// @property TimeZone string "IANA time zone of SendTime, e.g. Europe/Berlin"
+// @property NextSendAt string "When the next digest will be sent, null while digests are off"
+// @property LastSentAt string "When the last digest was sent"
+type DigestSettings struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	UserID     uint       `gorm:"uniqueIndex" json:"-"`
+	Frequency  string     `gorm:"not null" json:"frequency"`
+	SendTime   string     `gorm:"not null" json:"send_time"`
+	Weekday    int        `gorm:"not null" json:"weekday"`
+	TimeZone   string     `gorm:"not null" json:"time_zone"`
+	NextSendAt *time.Time `gorm:"index" json:"next_send_at"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// DigestSettingsRequest is the body of PUT /me/digest; omitted fields keep their value
+type DigestSettingsRequest struct {
+	Frequency *string `json:"frequency" example:"daily"`
+	SendTime  *string `json:"send_time" example:"08:00"`
+	Weekday   *int    `json:"weekday" example:"1"`
+	TimeZone  *string `json:"time_zone" example:"Europe/Berlin"`
+}
+
+// Digest is the content of a digest email: the user's tasks across all organizations
+type Digest struct {
+	Since     time.Time // Start of the period the digest covers
+	Until     time.Time // End of the period, when the digest is compiled
+	DueSoon   []Task    // Open tasks due before the next digest
+	Overdue   []Task    // Open tasks past their due date
+	Assigned  []Task    // Tasks assigned to the user during the period
+	Completed []Task    // Tasks completed during the period
+}
+
+// IsEmpty reports whether the digest has nothing to report
+func (d Digest) IsEmpty() bool {
+	return len(d.DueSoon)+len(d.Overdue)+len(d.Assigned)+len(d.Completed) == 0
+}
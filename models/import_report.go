@@ -0,0 +1,18 @@
+package models
+
+// ImportRowError describes why a single imported row was rejected
+type ImportRowError struct {
+	Row   int    `json:"row"` // 1-based row number, header excluded
+	Error string `json:"error"`
+}
+
+// ImportReport summarizes the result of a task import
+type ImportReport struct {
+	Total    int              `json:"total"`              // Number of rows read
+	Imported int              `json:"imported"`           // Number of tasks created (0 for dry runs and failed imports)
+	Skipped  int              `json:"skipped,omitempty"`  // Items ignored by the importer, e.g. archived cards or pull requests
+	Projects []string         `json:"projects,omitempty"` // Projects the tasks were imported into
+	Tags     []string         `json:"tags,omitempty"`     // Tags attached to the imported tasks
+	DryRun   bool             `json:"dry_run"`
+	Errors   []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Invitation asks someone, by email, to join an organization with a role. The emailed token
+// can be used once until the invitation expires; only its SHA-256 hash is stored.
+// @Description Invitation to join an organization.
+// @property Email string "Address the invitation was sent to"
+// @property Role string "Role the invitee gets: owner, admin or member"
+// @property InvitedBy uint "ID of the member who sent the invitation"
+// @property ExpiresAt string "Timestamp after which the invitation can no longer be accepted"
+// @property AcceptedAt string "Timestamp when the invitation was accepted, null while pending"
+type Invitation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrganizationID uint       `gorm:"index" json:"organization_id"`
+	Email          string     `gorm:"not null;index" json:"email"`
+	Role           string     `gorm:"not null" json:"role"`
+	TokenHash      string     `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedBy      uint       `gorm:"index" json:"invited_by"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	AcceptedBy     *uint      `json:"accepted_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// OrganizationScoped marks invitations as belonging to an organization
+func (Invitation) OrganizationScoped() {}
+
+// InvitationRequest is the body of POST /organizations/{id}/invitations
+type InvitationRequest struct {
+	Email string `json:"email" example:"newcomer@example.com"`
+	Role  string `json:"role" example:"member"` // owner, admin or member; member by default
+}
+
+// InvitationAcceptRequest is the body of POST /invitations/accept
+type InvitationAcceptRequest struct {
+	Token string `json:"token" example:"3f2a..."`
+}
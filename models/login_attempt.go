@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LoginAttempt counts recent failed logins for an account or a client IP
+type LoginAttempt struct {
+	Key           string     `gorm:"primaryKey" json:"key"` // "account:<email>", "ip:<address>" or "mfa:<token ID>"
+	Failures      int        `gorm:"not null" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+// UnlockRequest is the body of POST /admin/users/unlock
+type UnlockRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+	IP    string `json:"ip" example:"203.0.113.7"` // Optional; also clears the lock of this address
+}
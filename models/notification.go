@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Notification types; users can turn each of them off
+const (
+	NotificationTaskAssigned = "task_assigned"
+	NotificationMentioned    = "mentioned"
+	NotificationTaskUpdated  = "task_updated"
+	NotificationReminder     = "reminder"
+)
+
+// NotificationTypes lists all notification types
+var NotificationTypes = []string{NotificationTaskAssigned, NotificationMentioned, NotificationTaskUpdated, NotificationReminder}
+
+// IsValidNotificationType reports whether the type is one of NotificationTypes
+func IsValidNotificationType(notificationType string) bool {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is an entry of a user's in-app inbox
+// @Description Notification about something that happened on a task.
+// @property Type string "task_assigned, mentioned, task_updated or reminder"
+// @property OrganizationID uint "ID of the organization the task belongs to"
+// @property TaskID uint "ID of the task the notification is about"
+// @property ActorID uint "ID of the user who caused the notification, null for reminders"
+// @property Message string "Human-readable summary"
+// @property ReadAt string "Timestamp when the notification was read, null while unread"
+type Notification struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"index;not null" json:"-"`
+	OrganizationID uint       `json:"organization_id"`
+	Type           string     `gorm:"not null" json:"type"`
+	TaskID         *uint      `gorm:"index" json:"task_id"`
+	ActorID        *uint      `json:"actor_id"`
+	Message        string     `gorm:"not null" json:"message"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+// NotificationPreference turns a notification type on or off for a user. Types without a
+// preference are on.
+type NotificationPreference struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	UserID    uint      `gorm:"uniqueIndex:idx_notification_preference" json:"-"`
+	Type      string    `gorm:"uniqueIndex:idx_notification_preference" json:"type"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationFilter describes filtering and pagination options for the inbox
+type NotificationFilter struct {
+	Unread   bool // Only unread notifications
+	Page     int  // 1-based page number
+	PageSize int  // Number of notifications per page
+}
+
+// NotificationPage is the pagination envelope of the inbox
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	Unread        int64          `json:"unread"` // Unread notifications in the whole inbox
+	Page          int            `json:"page"`
+	PageSize      int            `json:"page_size"`
+}
+
+// UnreadCountResponse is the body of GET /notifications/unread-count
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// ParseNotificationFilter builds a NotificationFilter from URL query values such as "unread=true&page=2"
+func ParseNotificationFilter(values url.Values) (NotificationFilter, error) {
+	var filter NotificationFilter
+
+	if raw := values.Get("unread"); raw != "" {
+		unread, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("unread must be true or false")
+		}
+		filter.Unread = unread
+	}
+
+	page, pageSize, err := parsePagination(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.Page, filter.PageSize = page, pageSize
+	return filter, nil
+}
+
+// Offset returns the number of rows to skip for the current page
+func (f NotificationFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// NotificationsMarkedResponse is the body of POST /notifications/read-all
+type NotificationsMarkedResponse struct {
+	Marked int64 `json:"marked"` // Notifications that were unread
+}
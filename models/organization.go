@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization member roles
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// IsValidOrganizationRole reports whether the role is owner, admin or member
+func IsValidOrganizationRole(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleAdmin || role == OrganizationRoleMember
+}
+
+// OrganizationScoped is implemented by models whose rows belong to an organization.
+// Statements on them are restricted to the active organization by the repository
+// layer, see repository.ForOrganization.
+type OrganizationScoped interface {
+	OrganizationScoped()
+}
+
+// Organization is a workspace whose tasks, projects and tags are invisible to other organizations
+// @Description Organization (workspace) grouping users and their data.
+// @property ID uint "Unique identifier for the organization"
+// @property Name string "Name of the organization"
+// @property Members []OrganizationMember "Members with their roles"
+type Organization struct {
+	ID        uint                 `gorm:"primaryKey" json:"id"`
+	Name      string               `gorm:"not null" json:"name"`
+	Members   []OrganizationMember `json:"members,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	DeletedAt gorm.DeletedAt       `gorm:"index" json:"-"`
+}
+
+// OrganizationMember links a user to an organization with a role
+// @Description Membership of a user in an organization.
+// @property Role string "owner, admin or member"
+// @property Organization Organization "The organization, in lists of the user's memberships"
+type OrganizationMember struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	OrganizationID uint          `gorm:"uniqueIndex:idx_organization_member" json:"organization_id"`
+	UserID         uint          `gorm:"uniqueIndex:idx_organization_member;index" json:"user_id"`
+	Role           string        `gorm:"not null;default:member" json:"role"` // Possible values: owner, admin, member
+	Organization   *Organization `json:"organization,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// OrganizationRequest is the body for creating or renaming an organization
+type OrganizationRequest struct {
+	Name string `json:"name" example:"Marketing"`
+}
+
+// OrganizationMemberRequest is the body of POST /organizations/{id}/members
+type OrganizationMemberRequest struct {
+	Email string `json:"email" example:"colleague@example.com"`
+	Role  string `json:"role" example:"member"` // owner, admin or member; member by default
+}
+
+// OrganizationRoleRequest is the body of PUT /organizations/{id}/members/{userId}
+type OrganizationRoleRequest struct {
+	Role string `json:"role" example:"admin"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use, expiring token emailed to a user who forgot their password.
+// Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PasswordForgotRequest is the body of POST /password/forgot
+type PasswordForgotRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// PasswordResetRequest is the body of POST /password/reset
+type PasswordResetRequest struct {
+	Token    string `json:"token" example:"3f2a..."`
+	Password string `json:"password" example:"NewStrongP@ssword1"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// PersonalData is what is stored about a user apart from their tasks, for data export
+type PersonalData struct {
+	User          User
+	Organizations []OrganizationMember     // The user's memberships with their organizations
+	Invitations   []Invitation             // Invitations sent to or by the user
+	Projects      []Project                // Projects the user is a member of, with their members
+	Tags          []Tag                    // The user's tags
+	TaskTags      []TaskTagLink            // Which of the user's tasks carry which tags
+	Assignments   []TaskAssignee           // Tasks the user is assigned to
+	AssignmentLog []TaskAssignmentChange   // Assignment changes of or by the user
+	Watching      []TaskWatcher            // Tasks the user watches
+	SavedViews    []SavedView              // Views created by the user
+	APIKeys       []APIKey                 // API key metadata; keys themselves are not stored
+	CalendarFeeds []CalendarFeed           // Calendar feed metadata; tokens themselves are not stored
+	Notifications []Notification           // The user's notification inbox
+	Preferences   []NotificationPreference // Notification types the user turned on or off
+	Digest        *DigestSettings          // Digest email settings, nil if never changed
+	AuditEntries  []AuditEntry             // Admin actions by or about the user
+}
+
+// TaskTagLink is a row of the task_tags join table
+type TaskTagLink struct {
+	TaskID uint `json:"task_id"`
+	TagID  uint `json:"tag_id"`
+}
+
+// ErasedMention replaces the email address of an erased user in the tasks that are kept
+const ErasedMention = "deleted-user"
+
+// ErasureRecord documents a right-to-erasure request. It is stored, and the account disabled,
+// when the erasure is requested; the erasure job completes it in the same transaction as the
+// erasure. It keeps no personal data besides the former user ID.
+// @Description Record of a right-to-erasure request.
+// @property UserID uint "ID of the erased user"
+// @property RequestedBy uint "ID of the user who asked for the erasure; equal to UserID for self-service"
+// @property Counts object "Rows deleted or pseudonymized per table, once completed"
+// @property RequestedAt string "Timestamp when the erasure was requested"
+// @property CompletedAt string "Timestamp when the erasure was completed; null while it is pending"
+// @property LastError string "Why the last attempt failed; the job tries again"
+type ErasureRecord struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	UserID      uint             `gorm:"index;not null" json:"user_id"`
+	RequestedBy uint             `gorm:"not null" json:"requested_by"`
+	Counts      map[string]int64 `gorm:"serializer:json;type:text" json:"counts"`
+	RequestedAt time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"requested_at"`
+	CompletedAt *time.Time       `gorm:"index" json:"completed_at"`
+	LastError   string           `json:"last_error,omitempty"`
+}
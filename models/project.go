@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Project member roles
+const (
+	ProjectRoleOwner  = "owner"
+	ProjectRoleMember = "member"
+)
+
+// Project represents a group of tasks shared between several users
+// @Description Project model grouping tasks and members.
+// @property ID uint "Unique identifier for the project"
+// @property Name string "Name of the project"
+// @property Description string "Description of the project"
+// @property OwnerID uint "ID of the user who created the project"
+// @property OrganizationID uint "ID of the organization the project belongs to"
+type Project struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	Name           string          `gorm:"not null" json:"name"`
+	Description    string          `json:"description"`
+	OwnerID        uint            `gorm:"index" json:"owner_id"`
+	OrganizationID uint            `gorm:"index" json:"organization_id"`
+	Members        []ProjectMember `json:"members,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// OrganizationScoped marks projects as belonging to an organization
+func (Project) OrganizationScoped() {}
+
+// ProjectMember links a user to a project with a role. It carries the project's
+// organization so that membership checks are restricted to the active organization.
+type ProjectMember struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ProjectID      uint      `gorm:"uniqueIndex:idx_project_member" json:"project_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_project_member" json:"user_id"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	Role           string    `gorm:"default:'member'" json:"role"` // Possible values: owner, member
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationScoped marks project memberships as belonging to an organization
+func (ProjectMember) OrganizationScoped() {}
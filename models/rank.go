@@ -0,0 +1,116 @@
+package models
+
+import "strings"
+
+// rankDigits are the digits of ranks, in byte order so ranks compare like strings
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength is the length from which ranks are rebalanced; repeated moves to the same
+// spot make ranks longer
+const MaxRankLength = 12
+
+// RankOrder orders tasks by rank byte by byte, whatever the database collation
+const RankOrder = `rank COLLATE "C"`
+
+// RankBetween returns a rank that sorts after lower and before upper, so a task can be
+// placed between two others by changing only its own rank. Ranks are fractions in base 36
+// written without "0." and trailing zeros; an empty lower is the start of the list and an
+// empty upper its end. It reports false if lower does not sort before upper.
+func RankBetween(lower, upper string) (string, bool) {
+	if !validRank(lower) || !validRank(upper) || (upper != "" && lower >= upper) {
+		return "", false
+	}
+	if upper == "" {
+		return rankAfter(lower), true
+	}
+	return rankMidpoint(lower, upper), true
+}
+
+// SpreadRanks returns n ranks of equal length spread evenly over the rank space, leaving room
+// for many moves between any two of them
+func SpreadRanks(n int) []string {
+	width, total := 1, int64(len(rankDigits))
+	for total < int64(n+1)*int64(len(rankDigits)) {
+		width++
+		total *= int64(len(rankDigits))
+	}
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := int64(i+1) * (total / int64(n+1))
+		digits := make([]byte, width)
+		for d := width - 1; d >= 0; d-- {
+			digits[d] = rankDigits[value%int64(len(rankDigits))]
+			value /= int64(len(rankDigits))
+		}
+		ranks[i] = strings.TrimRight(string(digits), "0")
+	}
+	return ranks
+}
+
+// rankAfter returns a short rank after the given one, used to append to a list
+func rankAfter(rank string) string {
+	for i := 0; i < len(rank); i++ {
+		if rank[i] != rankDigits[len(rankDigits)-1] {
+			return rank[:i] + string(rankDigits[strings.IndexByte(rankDigits, rank[i])+1])
+		}
+	}
+	return rank + string(rankDigits[len(rankDigits)/2])
+}
+
+// rankMidpoint returns a rank halfway between lower and a non-empty upper
+func rankMidpoint(lower, upper string) string {
+	// Skip the common prefix, reading missing digits of lower as zeros
+	n := 0
+	for n < len(upper) && rankDigitAt(lower, n) == upper[n] {
+		n++
+	}
+	if n > 0 {
+		return upper[:n] + rankMidpoint(suffix(lower, n), upper[n:])
+	}
+
+	digitLower := strings.IndexByte(rankDigits, rankDigitAt(lower, 0))
+	digitUpper := strings.IndexByte(rankDigits, upper[0])
+	if digitUpper-digitLower > 1 {
+		return string(rankDigits[(digitLower+digitUpper+1)/2])
+	}
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	return string(rankDigits[digitLower]) + rankAfter(suffix(lower, 1))
+}
+
+// rankDigitAt returns the digit of the rank at position i, or zero past its end
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return rankDigits[0]
+}
+
+// suffix returns the rank without its first n digits
+func suffix(rank string, n int) string {
+	if n >= len(rank) {
+		return ""
+	}
+	return rank[n:]
+}
+
+// validRank reports whether the rank only has rank digits and does not end with a zero
+func validRank(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, rankDigits[:1])
+}
+
+// TaskMoveRequest is the body of POST /tasks/{id}/move. The task is placed right after
+// after_id and right before before_id; both anchors must be in the task's project and
+// target status. Without anchors the task goes to the end of the list.
+type TaskMoveRequest struct {
+	AfterID     *uint  `json:"after_id" example:"12"`        // Task that comes right before the moved task
+	BeforeID    *uint  `json:"before_id" example:"15"`       // Task that comes right after the moved task
+	Status      string `json:"status" example:"In Progress"` // Status column to move to; empty keeps the status
+	OverrideWIP bool   `json:"override_wip" example:"false"` // Move even if the target column is at its WIP limit
+}
@@ -12,16 +12,35 @@ type ErrorResponse struct {
 
 // TaskResponse represents a single task response
 type TaskResponse struct {
-	ID          uint   `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	UserID      uint   `json:"user_id"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID             uint           `json:"id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Status         string         `json:"status"`
+	StatusCategory string         `json:"status_category"`
+	UserID         uint           `json:"user_id"` // Creator of the task
+	ProjectID      *uint          `json:"project_id"`
+	DueDate        string         `json:"due_date"`
+	Priority       string         `json:"priority"`
+	Important      bool           `json:"important"`
+	Urgent         bool           `json:"urgent"`
+	EffortMinutes  int            `json:"effort_minutes"`
+	Rank           string         `json:"rank"`
+	Assignees      []TaskAssignee `json:"assignees"`
+	BlockedBy      []TaskBlocker  `json:"blocked_by"`
+	CustomFields   FieldValues    `json:"custom_fields"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
 }
 
-// TaskListResponse represents a list of tasks response
+// TaskListResponse represents a paginated list of tasks response
 type TaskListResponse struct {
-	Tasks []TaskResponse `json:"tasks"`
+	Tasks    []TaskResponse `json:"tasks"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// MessageResponse represents a simple confirmation response
+type MessageResponse struct {
+	Message string `json:"message"`
 }
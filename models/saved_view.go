@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedView represents a named filter and sort combination, e.g. "My overdue tasks"
+// @Description Saved view storing task list query parameters.
+// @property ID uint "Unique identifier for the view"
+// @property Name string "Name of the view"
+// @property Query string "URL-encoded task list query, e.g. overdue=true&sort=due_date"
+// @property UserID uint "ID of the user who created the view"
+// @property ProjectID uint "ID of the project the view is shared with (optional)"
+type SavedView struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Query     string         `json:"query"` // Same parameters as GET /tasks
+	UserID    uint           `gorm:"index" json:"user_id"`
+	ProjectID *uint          `gorm:"index" json:"project_id"` // Members of this project can use the view
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Token scopes, carried by login tokens and API keys
+const (
+	ScopeTasksRead  = "tasks:read"  // Read tasks, projects and views
+	ScopeTasksWrite = "tasks:write" // Create, update, delete and import tasks, projects and views
+	ScopeAdmin      = "admin"       // Manage API keys, calendar feeds and account settings
+)
+
+// AllScopes lists every known scope; login tokens get all of them by default
+var AllScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeAdmin}
+
+// IsValidScope reports whether scope is a known scope
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
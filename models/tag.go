@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Tag is a user-defined label attached to tasks
+// @Description Tag model for labelling tasks.
+// @property ID uint "Unique identifier for the tag"
+// @property Name string "Name of the tag"
+// @property UserID uint "ID of the user who owns the tag"
+// @property OrganizationID uint "ID of the organization the tag belongs to"
+type Tag struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"not null;uniqueIndex:idx_organization_user_tag" json:"name"`
+	UserID         uint      `gorm:"uniqueIndex:idx_organization_user_tag" json:"user_id"`
+	OrganizationID uint      `gorm:"uniqueIndex:idx_organization_user_tag" json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationScoped marks tags as belonging to an organization
+func (Tag) OrganizationScoped() {}
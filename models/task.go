@@ -1,29 +1,117 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Task statuses
+const (
+	StatusPending    = "Pending"
+	StatusInProgress = "In Progress"
+	StatusCompleted  = "Completed"
+)
+
+// TaskStatuses lists all valid task statuses
+var TaskStatuses = []string{StatusPending, StatusInProgress, StatusCompleted}
+
+// IsValidTaskStatus reports whether the status is one of TaskStatuses
+func IsValidTaskStatus(status string) bool {
+	for _, s := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Task priorities, from the most (P0) to the least (P4) pressing
+const (
+	PriorityP0 = "P0"
+	PriorityP1 = "P1"
+	PriorityP2 = "P2"
+	PriorityP3 = "P3"
+	PriorityP4 = "P4"
+)
+
+// DefaultPriority is the priority of tasks created without one
+const DefaultPriority = PriorityP2
+
+// TaskPriorities lists all valid task priorities
+var TaskPriorities = []string{PriorityP0, PriorityP1, PriorityP2, PriorityP3, PriorityP4}
+
+// IsValidTaskPriority reports whether the priority is one of TaskPriorities
+func IsValidTaskPriority(priority string) bool {
+	for _, p := range TaskPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxEffortMinutes caps effort estimates at 30 working days of 8 hours
+const MaxEffortMinutes = 30 * 8 * 60
+
+// Eisenhower matrix quadrants, derived from a task's importance and urgency
+const (
+	QuadrantDo        = "do"        // Important and urgent
+	QuadrantSchedule  = "schedule"  // Important, not urgent
+	QuadrantDelegate  = "delegate"  // Urgent, not important
+	QuadrantEliminate = "eliminate" // Neither
+)
+
 // Task represents the task model
 // @Description Task model containing task details.
 // @property ID uint "Unique identifier for the task"
 // @property Title string "Title of the task"
 // @property Description string "Detailed description of the task"
-// @property Status string "Current status of the task (Pending, In Progress, Completed)"
-// @property UserID uint "ID of the user associated with the task"
+// @property Status string "Current status of the task: Pending, In Progress, Completed or a status of the project's workflow"
+// @property StatusCategory string "Category of the status: todo, doing or done"
+// @property UserID uint "ID of the user who created the task"
+// @property OrganizationID uint "ID of the organization the task belongs to"
+// @property ProjectID uint "ID of the project the task belongs to (optional)"
+// @property DueDate time.Time "Deadline of the task (optional)"
+// @property Priority string "Priority from P0 (most pressing) to P4, P2 by default"
+// @property Important bool "Whether the task is important (Eisenhower matrix)"
+// @property Urgent bool "Whether the task is urgent (Eisenhower matrix)"
+// @property EffortMinutes int "Estimated effort in minutes, 0 if not estimated"
+// @property BlockedBy []TaskBlocker "Tasks that must be completed before this one"
+// @property Rank string "Position of the task in its list (project and status); sorts as a string"
+// @property ICalUID string "UID of a task imported from iCalendar, kept for round-trips"
+// @property CustomFields object "Values of the project's custom fields by field key"
+// @property Tags []Tag "Tags attached to the task"
+// @property Assignees []TaskAssignee "Users the task is assigned to"
 // @property CreatedAt time.Time "Timestamp when the task was created"
 // @property UpdatedAt time.Time "Timestamp when the task was last updated"
 type Task struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"not null" json:"title"`
-	Description string         `json:"description"`
-	Status      string         `gorm:"default:'Pending'" json:"status"` // Possible values: Pending, In Progress, Completed
-	UserID      uint           `json:"user_id"`                         // Relationship with user (if provided)
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"` // Field for soft delete
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	Title          string         `gorm:"not null" json:"title"`
+	Description    string         `json:"description"`
+	Status         string         `gorm:"default:'Pending'" json:"status"`                 // Pending, In Progress, Completed or a status of the project's workflow
+	StatusCategory string         `gorm:"default:'todo';index" json:"status_category"`     // todo, doing or done
+	UserID         uint           `json:"user_id"`                                         // Creator of the task
+	OrganizationID uint           `gorm:"index" json:"organization_id"`                    // Organization the task belongs to
+	ProjectID      *uint          `gorm:"index" json:"project_id"`                         // Project the task belongs to (optional)
+	DueDate        *time.Time     `gorm:"index" json:"due_date"`                           // Deadline of the task (optional)
+	Priority       string         `gorm:"default:'P2';index" json:"priority"`              // P0 (most pressing) to P4
+	Important      bool           `gorm:"not null;default:false" json:"important"`         // Eisenhower importance
+	Urgent         bool           `gorm:"not null;default:false" json:"urgent"`            // Eisenhower urgency
+	EffortMinutes  int            `gorm:"not null;default:0" json:"effort_minutes"`        // Effort estimate, 0 if none
+	Rank           string         `gorm:"not null;default:'';index" json:"rank"`           // Manual order within the project and status
+	ICalUID        string         `gorm:"column:ical_uid;index" json:"ical_uid,omitempty"` // UID of a task imported from iCalendar
+	Tags           []Tag          `gorm:"many2many:task_tags" json:"tags"`
+	Assignees      []TaskAssignee `json:"assignees"`
+	BlockedBy      []TaskBlocker  `json:"blocked_by"`
+	CustomFields   FieldValues    `gorm:"serializer:json;type:jsonb;default:'{}'" json:"custom_fields"`
+	AssigneeIDs    []uint         `gorm:"-" json:"assignee_ids,omitempty"` // Users to assign on creation
+	OverrideWIP    bool           `gorm:"-" json:"override_wip,omitempty"` // Move past the board column's WIP limit
+	RemindedAt     *time.Time     `json:"-"`                               // When the due date reminder was sent
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"` // Field for soft delete
 }
 
 // TableName allows setting the table name for the Task model
@@ -32,11 +120,121 @@ func (Task) TableName() string {
 	return "tasks"
 }
 
+// OrganizationScoped marks tasks as belonging to an organization
+func (Task) OrganizationScoped() {}
+
+// Category returns the category of the task's status, falling back to the category of the
+// built-in status for tasks that were not saved yet
+func (t Task) Category() string {
+	if t.StatusCategory != "" {
+		return t.StatusCategory
+	}
+	return DefaultStatusCategory(t.Status)
+}
+
+// Quadrant returns the Eisenhower matrix quadrant of the task
+func (t Task) Quadrant() string {
+	switch {
+	case t.Important && t.Urgent:
+		return QuadrantDo
+	case t.Important:
+		return QuadrantSchedule
+	case t.Urgent:
+		return QuadrantDelegate
+	default:
+		return QuadrantEliminate
+	}
+}
+
 // BeforeCreate sets default values before creating a task
-// @Description Ensures the task status is set to 'Pending' if not provided.
+// @Description Ensures the task status is set to 'Pending' and the priority to 'P2' if not provided.
 func (t *Task) BeforeCreate(tx *gorm.DB) (err error) {
 	if t.Status == "" {
-		t.Status = "Pending"
+		t.Status = StatusPending
+	}
+	if t.StatusCategory == "" {
+		t.StatusCategory = DefaultStatusCategory(t.Status)
+	}
+	if t.Priority == "" {
+		t.Priority = DefaultPriority
+	}
+	if t.CustomFields == nil {
+		t.CustomFields = FieldValues{}
 	}
 	return
 }
+
+// TaskUpdateRequest is the body of PUT /tasks/{id}. Fields that are left out keep their
+// current value; project_id 0 takes the task out of its project and a null due_date clears it.
+type TaskUpdateRequest struct {
+	Title         *string      `json:"title" example:"Write the report"`
+	Description   *string      `json:"description"`
+	Status        *string      `json:"status" example:"In Progress"`
+	ProjectID     *uint        `json:"project_id" example:"3"`
+	DueDate       OptionalTime `json:"due_date" swaggertype:"string" example:"2025-06-01T17:00:00Z"`
+	Priority      *string      `json:"priority" example:"P1"`
+	Important     *bool        `json:"important"`
+	Urgent        *bool        `json:"urgent"`
+	EffortMinutes *int         `json:"effort_minutes" example:"90"`
+	CustomFields  FieldValues  `json:"custom_fields"` // Merged into the task's values; null clears a field
+	OverrideWIP   bool         `json:"override_wip"`  // Move even if the target column is at its WIP limit
+}
+
+// Apply returns a copy of the task with the fields of the request set. Its custom fields are
+// the values of the request, which the task service merges into the current ones.
+func (r TaskUpdateRequest) Apply(task Task) Task {
+	if r.Title != nil {
+		task.Title = *r.Title
+	}
+	if r.Description != nil {
+		task.Description = *r.Description
+	}
+	if r.Status != nil {
+		task.Status = *r.Status
+	}
+	if r.ProjectID != nil {
+		task.ProjectID = nil
+		if *r.ProjectID != 0 {
+			projectID := *r.ProjectID
+			task.ProjectID = &projectID
+		}
+	}
+	if r.DueDate.Set {
+		task.DueDate = r.DueDate.Value
+	}
+	if r.Priority != nil {
+		task.Priority = *r.Priority
+	}
+	if r.Important != nil {
+		task.Important = *r.Important
+	}
+	if r.Urgent != nil {
+		task.Urgent = *r.Urgent
+	}
+	if r.EffortMinutes != nil {
+		task.EffortMinutes = *r.EffortMinutes
+	}
+	task.CustomFields = r.CustomFields
+	task.OverrideWIP = r.OverrideWIP
+	return task
+}
+
+// OptionalTime is a timestamp in a request that tells null apart from a missing field
+type OptionalTime struct {
+	Set   bool       // Whether the field was present
+	Value *time.Time // nil for null
+}
+
+// UnmarshalJSON records that the field was present and reads the timestamp, if any
+func (t *OptionalTime) UnmarshalJSON(data []byte) error {
+	t.Set, t.Value = true, nil
+	if string(data) == "null" {
+		return nil
+	}
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Value = &value
+	return nil
+}
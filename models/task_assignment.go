@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Assignment history actions
+const (
+	AssignmentAssigned   = "assigned"
+	AssignmentUnassigned = "unassigned"
+)
+
+// TaskAssignee links a task to a user who works on it. A task can have several assignees;
+// its creator is Task.UserID.
+type TaskAssignee struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	TaskID         uint      `gorm:"uniqueIndex:idx_task_assignee" json:"task_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_task_assignee;index" json:"user_id"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	AssignedBy     uint      `json:"assigned_by"`
+	CreatedAt      time.Time `json:"assigned_at"`
+}
+
+// OrganizationScoped marks task assignees as belonging to an organization
+func (TaskAssignee) OrganizationScoped() {}
+
+// TaskAssignmentChange records that a user was assigned to or unassigned from a task
+// @Description Entry of a task's assignment history.
+// @property UserID uint "ID of the assigned or unassigned user"
+// @property Action string "assigned or unassigned"
+// @property ChangedBy uint "ID of the user who made the change"
+type TaskAssignmentChange struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TaskID         uint      `gorm:"index" json:"task_id"`
+	UserID         uint      `gorm:"index" json:"user_id"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	Action         string    `gorm:"not null" json:"action"`
+	ChangedBy      uint      `gorm:"index" json:"changed_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationScoped marks assignment history as belonging to an organization
+func (TaskAssignmentChange) OrganizationScoped() {}
+
+// TaskAssigneesRequest is the body of PUT /tasks/{id}/assignees
+type TaskAssigneesRequest struct {
+	UserIDs []uint `json:"user_ids" example:"2,3"` // Replaces the current assignees; empty to unassign everyone
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// TaskBlocker records that a task cannot be done before another task is completed
+type TaskBlocker struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	TaskID         uint      `gorm:"uniqueIndex:idx_task_blocker" json:"task_id"`
+	BlockerID      uint      `gorm:"uniqueIndex:idx_task_blocker;index" json:"blocker_id"` // Task that must be completed first
+	OrganizationID uint      `gorm:"index" json:"-"`
+	CreatedBy      uint      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OrganizationScoped marks task blockers as belonging to an organization
+func (TaskBlocker) OrganizationScoped() {}
+
+// TaskBlockersRequest is the body of PUT /tasks/{id}/blockers
+type TaskBlockersRequest struct {
+	TaskIDs []uint `json:"task_ids" example:"4,7"` // Replaces the current blockers; empty to unblock the task
+}
+
+// Default and maximum number of tasks returned by GET /tasks/next
+const (
+	DefaultNextTasks = 10
+	MaxNextTasks     = 50
+)
+
+// RankedTask is an open task with the score that ranks it in GET /tasks/next
+// @Description Task suggested by GET /tasks/next, with the parts of its score.
+// @property Score int "Total score; higher means do it sooner"
+// @property PriorityScore int "Points for the task's priority"
+// @property DueScore int "Points for how soon the task is due"
+// @property EisenhowerScore int "Points for importance and urgency"
+// @property BlockingScore int "Points for the open tasks waiting on this one"
+// @property Quadrant string "Eisenhower quadrant: do, schedule, delegate or eliminate"
+// @property Blocks int "Number of open tasks this task blocks"
+type RankedTask struct {
+	Task            Task   `json:"task"`
+	Score           int    `json:"score"`
+	PriorityScore   int    `json:"priority_score"`
+	DueScore        int    `json:"due_score"`
+	EisenhowerScore int    `json:"eisenhower_score"`
+	BlockingScore   int    `json:"blocking_score"`
+	Quadrant        string `json:"quadrant"`
+	Blocks          int    `json:"blocks"`
+}
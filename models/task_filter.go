@@ -0,0 +1,293 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20  // Page size used when the client does not provide one
+	MaxPageSize     = 100 // Upper bound for the page size
+)
+
+// taskSortColumns maps public sort keys to database columns
+var taskSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"status":     "status",
+	"due_date":   "due_date",
+	"priority":   "priority",
+	"effort":     "effort_minutes",
+	"rank":       RankOrder,
+}
+
+// filterParams lists the query parameters that select or order tasks (pagination excluded)
+var filterParams = []string{"status", "status_category", "q", "tag", "project_id", "assignee_id", "watcher_id", "priority", "important", "urgent", "due_before", "due_after", "overdue", "sort", "order"}
+
+// TaskFilter describes filtering, sorting and pagination options for task lists
+type TaskFilter struct {
+	Status     string     // Exact status match
+	Category   string     // Only tasks whose status has this category
+	Search     string     // Case-insensitive search in title and description
+	Tag        string     // Only tasks with this tag name
+	ProjectID  *uint      // Only tasks of this project
+	AssigneeID *uint      // Only tasks assigned to this user
+	WatcherID  *uint      // Only tasks this user watches
+	Priority   string     // Exact priority match
+	Important  *bool      // Only important (true) or unimportant (false) tasks
+	Urgent     *bool      // Only urgent (true) or non-urgent (false) tasks
+	DueBefore  *time.Time // Due date strictly before this moment
+	DueAfter   *time.Time // Due date strictly after this moment
+	Overdue    bool       // Due date in the past and status not done
+	Sort       string     // One of the keys of taskSortColumns
+	Order      string     // "asc" or "desc"
+	Page       int        // 1-based page number
+	PageSize   int        // Number of tasks per page
+
+	// Conditions on custom fields, all of which must match, and the type of the custom field in
+	// Sort. Custom fields belong to a project, so their types are set once its fields are loaded.
+	CustomFields  []CustomFieldFilter
+	SortFieldType string
+}
+
+// TaskPage is the pagination envelope returned by task list endpoints
+type TaskPage struct {
+	Tasks    []Task `json:"tasks"`
+	Total    int64  `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+// ParseTaskFilter builds a TaskFilter from URL query values such as
+// "status=Pending&tag=urgent&overdue=true&sort=due_date&order=asc&page=2"
+func ParseTaskFilter(values url.Values) (TaskFilter, error) {
+	filter := TaskFilter{
+		Status:   values.Get("status"),
+		Category: values.Get("status_category"),
+		Search:   values.Get("q"),
+		Tag:      values.Get("tag"),
+		Priority: values.Get("priority"),
+		Sort:     values.Get("sort"),
+		Order:    values.Get("order"),
+	}
+
+	if filter.Category != "" && !IsValidStatusCategory(filter.Category) {
+		return filter, errors.New("status_category must be one of todo, doing, done")
+	}
+
+	if filter.Priority != "" && !IsValidTaskPriority(filter.Priority) {
+		return filter, errors.New("priority must be one of P0, P1, P2, P3, P4")
+	}
+
+	if raw := values.Get("project_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid project_id")
+		}
+		projectID := uint(id)
+		filter.ProjectID = &projectID
+	}
+
+	if raw := values.Get("assignee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid assignee_id")
+		}
+		assigneeID := uint(id)
+		filter.AssigneeID = &assigneeID
+	}
+
+	if raw := values.Get("watcher_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid watcher_id")
+		}
+		watcherID := uint(id)
+		filter.WatcherID = &watcherID
+	}
+
+	if raw := values.Get("important"); raw != "" {
+		important, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("important must be true or false")
+		}
+		filter.Important = &important
+	}
+
+	if raw := values.Get("urgent"); raw != "" {
+		urgent, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("urgent must be true or false")
+		}
+		filter.Urgent = &urgent
+	}
+
+	if raw := values.Get("due_before"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("due_before must be an RFC3339 timestamp")
+		}
+		filter.DueBefore = &t
+	}
+
+	if raw := values.Get("due_after"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.New("due_after must be an RFC3339 timestamp")
+		}
+		filter.DueAfter = &t
+	}
+
+	if raw := values.Get("overdue"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("overdue must be true or false")
+		}
+		filter.Overdue = overdue
+	}
+
+	customFields, err := parseCustomFieldFilters(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.CustomFields = customFields
+
+	if filter.Sort != "" {
+		_, builtIn := taskSortColumns[filter.Sort]
+		if _, custom := filter.CustomSortKey(); !builtIn && !custom {
+			return filter, errors.New("unsupported sort field")
+		}
+	}
+
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	page, pageSize, err := parsePagination(values)
+	if err != nil {
+		return filter, err
+	}
+	filter.Page, filter.PageSize = page, pageSize
+
+	filter.Normalize()
+	return filter, nil
+}
+
+// parseCustomFieldFilters reads the custom field conditions, "cf.<key>=value" for equal
+// values and "cf.<key>.gte=value" or "cf.<key>.lte=value" for ranges, in parameter order
+func parseCustomFieldFilters(values url.Values) ([]CustomFieldFilter, error) {
+	var params []string
+	for param := range values {
+		if strings.HasPrefix(param, CustomFieldPrefix) {
+			params = append(params, param)
+		}
+	}
+	sort.Strings(params)
+
+	var filters []CustomFieldFilter
+	for _, param := range params {
+		key, op, ranged := strings.Cut(strings.TrimPrefix(param, CustomFieldPrefix), ".")
+		if !ranged {
+			op = "eq"
+		}
+		if !IsValidCustomFieldKey(key) || (op != "eq" && op != "gte" && op != "lte") {
+			return nil, fmt.Errorf("unsupported custom field filter %q", param)
+		}
+		filters = append(filters, CustomFieldFilter{Key: key, Op: op, Value: values.Get(param)})
+	}
+	return filters, nil
+}
+
+// parsePagination reads page and page_size, applying the defaults and limits of task lists
+func parsePagination(values url.Values) (int, int, error) {
+	page, pageSize := 1, DefaultPageSize
+	if raw := values.Get("page"); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+		page = p
+	}
+	if raw := values.Get("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			return 0, 0, errors.New("page_size must be a positive integer")
+		}
+		pageSize = min(size, MaxPageSize)
+	}
+	return page, pageSize, nil
+}
+
+// HasFilterParams reports whether the query selects or orders tasks, ignoring pagination
+func HasFilterParams(values url.Values) bool {
+	for _, param := range filterParams {
+		if values.Get(param) != "" {
+			return true
+		}
+	}
+	for param := range values {
+		if strings.HasPrefix(param, CustomFieldPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize fills in default pagination and ordering values
+func (f *TaskFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+	if f.Sort == "" {
+		f.Sort = "created_at"
+	}
+	if f.Order == "" {
+		f.Order = "asc"
+	}
+}
+
+// CustomSortKey returns the key of the custom field the filter sorts by, if it sorts by one
+func (f TaskFilter) CustomSortKey() (string, bool) {
+	if _, ok := taskSortColumns[f.Sort]; ok {
+		return "", false
+	}
+	key := strings.TrimPrefix(f.Sort, CustomFieldPrefix)
+	return key, key != f.Sort && IsValidCustomFieldKey(key)
+}
+
+// UsesCustomFields reports whether the filter selects or sorts tasks by custom fields
+func (f TaskFilter) UsesCustomFields() bool {
+	_, ok := f.CustomSortKey()
+	return ok || len(f.CustomFields) > 0
+}
+
+// OrderClause returns a safe ORDER BY clause for the filter. Custom field sorts are built by
+// the repository.
+func (f TaskFilter) OrderClause() string {
+	column, ok := taskSortColumns[f.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	order := "asc"
+	if f.Order == "desc" {
+		order = "desc"
+	}
+	return column + " " + order + ", id " + order
+}
+
+// Offset returns the number of rows to skip for the current page
+func (f TaskFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
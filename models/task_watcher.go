@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TaskWatcher is a user who follows a task and is notified when it changes. Creators and
+// assignees watch their tasks automatically but can stop watching.
+type TaskWatcher struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	TaskID         uint      `gorm:"uniqueIndex:idx_task_watcher" json:"task_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_task_watcher;index" json:"user_id"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	CreatedAt      time.Time `json:"watching_since"`
+}
+
+// OrganizationScoped marks task watchers as belonging to an organization
+func (TaskWatcher) OrganizationScoped() {}
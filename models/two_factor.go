@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TOTPEnrollment is returned when two-factor authentication is being set up
+type TOTPEnrollment struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/Task%20Manager:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Task+Manager"`
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// RecoveryCodesResponse lists new recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeResponse is returned by /login when a second factor is required
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	MFAToken          string `json:"mfa_token"`
+}
+
+// LoginTwoFactorRequest is the body of POST /login/2fa
+type LoginTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" example:"123456"` // TOTP code or recovery code
+}
@@ -6,23 +6,83 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole reports whether the role is RoleUser or RoleAdmin
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
 // User represents the user model
 // @Description User model containing authentication details.
 // @property ID uint "Unique identifier for the user"
 // @property Email string "Email address of the user"
-// @property Password string "Encrypted password of the user"
+// @property Role string "user or admin"
+// @property DefaultViewID uint "ID of the saved view applied to GET /tasks by default"
+// @property SessionsRevokedAt string "Login tokens issued before this moment are rejected"
+// @property VerifiedAt string "Timestamp when the email address was verified"
+// @property PendingEmail string "New email address waiting for verification"
+// @property TOTPEnabledAt string "Timestamp when two-factor authentication was enabled"
+// @property DisabledAt string "Timestamp when an administrator disabled the account"
+// @property MustResetPassword bool "An administrator requires a password reset before the next login"
 // @property CreatedAt string "Timestamp when the user was created"
 // @property UpdatedAt string "Timestamp when the user was last updated"
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Email     string         `json:"email"`
-	Password  string         `json:"password"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Email              string         `json:"email"`
+	Password           string         `json:"-"`
+	Role               string         `gorm:"not null;default:user" json:"role"`
+	DefaultViewID      *uint          `json:"default_view_id"`
+	SessionsRevokedAt  *time.Time     `json:"-"`
+	VerifiedAt         *time.Time     `json:"verified_at"`
+	VerificationSentAt *time.Time     `json:"-"`
+	PendingEmail       string         `json:"pending_email,omitempty"`
+	TOTPSecret         string         `gorm:"column:totp_secret" json:"-"`
+	TOTPEnabledAt      *time.Time     `gorm:"column:totp_enabled_at" json:"totp_enabled_at"`
+	TOTPLastCounter    int64          `gorm:"column:totp_last_counter" json:"-"`
+	DisabledAt         *time.Time     `json:"disabled_at"`
+	MustResetPassword  bool           `gorm:"not null;default:false" json:"must_reset_password"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type UserRegisterRequest struct {
 	Email    string `json:"email" example:"user@example.com"`   // Email пользователя
 	Password string `json:"password" example:"StrongP@ssword1"` // Пароль пользователя
+	// Optional token of an invitation to the same address; the user joins its organization
+	InvitationToken string `json:"invitation_token,omitempty" example:"3f2a..."`
+}
+
+// UserLoginRequest is the body of POST /login
+type UserLoginRequest struct {
+	Email    string   `json:"email" example:"user@example.com"`
+	Password string   `json:"password" example:"StrongP@ssword1"`
+	Scopes   []string `json:"scopes" example:"tasks:read"` // Optional; defaults to every scope
+}
+
+// EmailResendRequest is the body of POST /email/verify/resend
+type EmailResendRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// PasswordChangeRequest is the body of PUT /me/password
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" example:"StrongP@ssword1"`
+	NewPassword     string `json:"new_password" example:"EvenStr0nger!Pass"`
+}
+
+// EmailChangeRequest is the body of PUT /me/email
+type EmailChangeRequest struct {
+	Email    string `json:"email" example:"new@example.com"`
+	Password string `json:"password" example:"StrongP@ssword1"` // Current password
+}
+
+// AccountDeleteRequest is the body of DELETE /me
+type AccountDeleteRequest struct {
+	Password string `json:"password" example:"StrongP@ssword1"` // Current password
 }
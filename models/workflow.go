@@ -0,0 +1,174 @@
+package models
+
+import "time"
+
+// Status categories tell what the statuses of a workflow mean: the task is still to do,
+// being worked on, or done
+const (
+	StatusCategoryTodo  = "todo"
+	StatusCategoryDoing = "doing"
+	StatusCategoryDone  = "done"
+)
+
+// StatusCategories lists all valid status categories
+var StatusCategories = []string{StatusCategoryTodo, StatusCategoryDoing, StatusCategoryDone}
+
+// IsValidStatusCategory reports whether the category is one of StatusCategories
+func IsValidStatusCategory(category string) bool {
+	for _, c := range StatusCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits of custom workflows
+const (
+	MaxWorkflowStatuses = 20
+	MaxStatusName       = 50
+)
+
+// defaultStatusCategories are the categories of the built-in statuses
+var defaultStatusCategories = map[string]string{
+	StatusPending:    StatusCategoryTodo,
+	StatusInProgress: StatusCategoryDoing,
+	StatusCompleted:  StatusCategoryDone,
+}
+
+// DefaultStatusCategory returns the category of a built-in status. Other statuses count as
+// to do.
+func DefaultStatusCategory(status string) string {
+	if category, ok := defaultStatusCategories[status]; ok {
+		return category
+	}
+	return StatusCategoryTodo
+}
+
+// WorkflowStatus is a status of a project's custom workflow
+// @Description Status of a workflow with the statuses tasks can move to from it.
+// @property Name string "Name of the status"
+// @property Category string "Category of the status: todo, doing or done"
+// @property Position int "Position of the status in the workflow, from 0; the first status is the initial one"
+// @property Transitions []string "Statuses tasks can move to from this one"
+type WorkflowStatus struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	ProjectID      uint      `gorm:"uniqueIndex:idx_workflow_status" json:"-"`
+	Name           string    `gorm:"uniqueIndex:idx_workflow_status;not null" json:"name"`
+	OrganizationID uint      `gorm:"index" json:"-"`
+	Category       string    `gorm:"not null" json:"category"`
+	Position       int       `gorm:"not null;default:0" json:"position"`
+	Transitions    []string  `gorm:"-" json:"transitions"`
+	CreatedAt      time.Time `json:"-"`
+}
+
+// OrganizationScoped marks workflow statuses as belonging to an organization
+func (WorkflowStatus) OrganizationScoped() {}
+
+// WorkflowTransition allows tasks of a project to move from one status to another
+type WorkflowTransition struct {
+	ID             uint   `gorm:"primaryKey"`
+	ProjectID      uint   `gorm:"uniqueIndex:idx_workflow_transition"`
+	FromStatus     string `gorm:"uniqueIndex:idx_workflow_transition;not null"`
+	ToStatus       string `gorm:"uniqueIndex:idx_workflow_transition;not null"`
+	OrganizationID uint   `gorm:"index"`
+}
+
+// OrganizationScoped marks workflow transitions as belonging to an organization
+func (WorkflowTransition) OrganizationScoped() {}
+
+// Workflow is the set of statuses the tasks of a project, or the tasks outside projects, go through
+// @Description Statuses of a project in workflow order, with their allowed transitions.
+// @property ProjectID uint "ID of the project"
+// @property Custom bool "Whether the project defined its own statuses"
+// @property Statuses []WorkflowStatus "Statuses in workflow order; new tasks start in the first one"
+type Workflow struct {
+	ProjectID *uint            `json:"project_id"`
+	Custom    bool             `json:"custom"`
+	Statuses  []WorkflowStatus `json:"statuses"`
+}
+
+// DefaultWorkflow returns the workflow of projects without custom statuses: Pending,
+// In Progress and Completed, with every transition allowed
+func DefaultWorkflow(projectID *uint) Workflow {
+	workflow := Workflow{ProjectID: projectID, Statuses: make([]WorkflowStatus, len(TaskStatuses))}
+	for i, status := range TaskStatuses {
+		var transitions []string
+		for _, other := range TaskStatuses {
+			if other != status {
+				transitions = append(transitions, other)
+			}
+		}
+		workflow.Statuses[i] = WorkflowStatus{Name: status, Category: DefaultStatusCategory(status), Position: i, Transitions: transitions}
+	}
+	return workflow
+}
+
+// Initial returns the status new tasks start in
+func (w Workflow) Initial() WorkflowStatus {
+	return w.Statuses[0]
+}
+
+// Status returns the status with the name
+func (w Workflow) Status(name string) (WorkflowStatus, bool) {
+	for _, status := range w.Statuses {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// Resolve returns the status a name stands for: the status with that name or, for the
+// built-in status names, the first status of their category. Clients that only know the
+// built-in statuses keep working with custom workflows this way.
+func (w Workflow) Resolve(name string) (WorkflowStatus, bool) {
+	if status, ok := w.Status(name); ok {
+		return status, true
+	}
+	category, ok := defaultStatusCategories[name]
+	if !ok {
+		return WorkflowStatus{}, false
+	}
+	return w.FirstOfCategory(category)
+}
+
+// FirstOfCategory returns the first status of the category
+func (w Workflow) FirstOfCategory(category string) (WorkflowStatus, bool) {
+	for _, status := range w.Statuses {
+		if status.Category == category {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// CanTransition reports whether tasks may move from one status to another. Tasks in a status
+// the workflow does not know may move to any status.
+func (w Workflow) CanTransition(from, to string) bool {
+	status, ok := w.Status(from)
+	if !ok || from == to {
+		return true
+	}
+	for _, next := range status.Transitions {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkflowStatusRequest defines one status in PUT /projects/{id}/workflow
+type WorkflowStatusRequest struct {
+	Name        string   `json:"name" example:"Review"`                  // Name of the status
+	Category    string   `json:"category" example:"doing"`               // todo, doing or done
+	Transitions []string `json:"transitions" example:"In Progress,Done"` // Statuses tasks can move to from this one
+}
+
+// WorkflowRequest is the body of PUT /projects/{id}/workflow. The statuses are listed in
+// workflow order. Tasks whose status is removed move to the status given in status_mapping,
+// or else to the first new status of the same category.
+type WorkflowRequest struct {
+	Statuses      []WorkflowStatusRequest `json:"statuses"`
+	StatusMapping map[string]string       `json:"status_mapping"`
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// AdminRepository defines the interface for administrator queries across all users
+type AdminRepository interface {
+	ListUsers(filter models.UserFilter) (*models.UserPage, error)
+	SetDisabled(userID uint, at *time.Time) error
+	SetRole(userID uint, role string) error
+	RequirePasswordReset(userID uint, at time.Time) error
+	GetStats(now time.Time) (*models.SystemStats, error)
+}
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository creates a new instance of AdminRepository whose statistics cover all organizations
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepository{db: AllOrganizations(db)}
+}
+
+// ListUsers retrieves one page of users matching the filter, ordered by ID
+func (r *adminRepository) ListUsers(filter models.UserFilter) (*models.UserPage, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Search != "" {
+		query = query.Where("email ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query = query.Where("disabled_at IS NOT NULL")
+		} else {
+			query = query.Where("disabled_at IS NULL")
+		}
+	}
+
+	page := &models.UserPage{Users: []models.User{}, Page: filter.Page, PageSize: filter.PageSize}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	err := query.Order("id").Offset(filter.Offset()).Limit(filter.PageSize).Find(&page.Users).Error
+	return page, err
+}
+
+// SetDisabled disables the account at the given time, or enables it again when at is nil
+func (r *adminRepository) SetDisabled(userID uint, at *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("disabled_at", at).Error
+}
+
+// SetRole changes the user's role
+func (r *adminRepository) SetRole(userID uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// RequirePasswordReset blocks logins until the user resets their password and revokes their sessions
+func (r *adminRepository) RequirePasswordReset(userID uint, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"must_reset_password": true,
+		"sessions_revoked_at": at,
+	}).Error
+}
+
+// GetStats counts users, tasks and projects of the whole system
+func (r *adminRepository) GetStats(now time.Time) (*models.SystemStats, error) {
+	stats := &models.SystemStats{}
+
+	err := r.db.Model(&models.User{}).Select(
+		"COUNT(*) AS total, " +
+			"COUNT(*) FILTER (WHERE role = 'admin') AS admins, " +
+			"COUNT(disabled_at) AS disabled, " +
+			"COUNT(verified_at) AS verified, " +
+			"COUNT(totp_enabled_at) AS two_factor").
+		Scan(&stats.Users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks struct {
+		Total   int64
+		Overdue int64
+		Recent  int64
+	}
+	err = r.db.Model(&models.Task{}).Select(
+		"COUNT(*) AS total, "+
+			"COUNT(*) FILTER (WHERE due_date < ? AND status_category <> ?) AS overdue, "+
+			"COUNT(*) FILTER (WHERE created_at >= ?) AS recent",
+		now, models.StatusCategoryDone, now.AddDate(0, 0, -7)).
+		Scan(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Tasks.Total = tasks.Total
+	stats.Tasks.Overdue = tasks.Overdue
+	stats.Tasks.CreatedLast7Days = tasks.Recent
+
+	var byStatus []struct {
+		Status         string
+		StatusCategory string
+		Count          int64
+	}
+	err = r.db.Model(&models.Task{}).Select("status, status_category, COUNT(*) AS count").
+		Group("status, status_category").Scan(&byStatus).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Tasks.ByStatus = make(map[string]int64, len(byStatus))
+	stats.Tasks.ByCategory = make(map[string]int64, len(models.StatusCategories))
+	for _, row := range byStatus {
+		stats.Tasks.ByStatus[row.Status] += row.Count
+		stats.Tasks.ByCategory[row.StatusCategory] += row.Count
+	}
+
+	if err := r.db.Model(&models.Project{}).Count(&stats.Projects).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
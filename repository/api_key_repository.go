@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the interface for API key database operations
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByUserID(userID uint, keys *[]models.APIKey) error
+	FindByHash(keyHash string) (*models.APIKey, error)
+	Delete(id uint, userID uint) (bool, error)
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository initializes a new instance of APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create adds a new API key
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByUserID returns the user's API keys, newest first
+func (r *apiKeyRepository) GetByUserID(userID uint, keys *[]models.APIKey) error {
+	return r.db.Where("user_id = ?", userID).Order("created_at desc, id desc").Find(keys).Error
+}
+
+// FindByHash returns the key with the given hash, or nil if it does not exist
+func (r *apiKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Delete removes one of the user's keys and reports whether it existed
+func (r *apiKeyRepository) Delete(id uint, userID uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// TouchLastUsed records when a key was last used
+func (r *apiKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository defines the interface for the administrator audit log
+type AuditRepository interface {
+	Create(entry *models.AuditEntry) error
+	List(filter models.AuditFilter) (*models.AuditPage, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create appends an entry to the audit log
+func (r *auditRepository) Create(entry *models.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// List retrieves one page of audit entries matching the filter, newest first
+func (r *auditRepository) List(filter models.AuditFilter) (*models.AuditPage, error) {
+	query := r.db.Model(&models.AuditEntry{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.TargetUserID != nil {
+		query = query.Where("target_user_id = ?", *filter.TargetUserID)
+	}
+
+	page := &models.AuditPage{Entries: []models.AuditEntry{}, Page: filter.Page, PageSize: filter.PageSize}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(filter.Offset()).Limit(filter.PageSize).Find(&page.Entries).Error
+	return page, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedRepository defines the interface for calendar feed database operations
+type CalendarFeedRepository interface {
+	Replace(feed *models.CalendarFeed) error
+	FindByTokenHash(tokenHash string) (*models.CalendarFeed, error)
+	DeleteByUserID(userID uint) error
+	ForOrganization(organizationID uint) CalendarFeedRepository
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedRepository initializes a new instance of CalendarFeedRepository
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// ForOrganization returns a repository restricted to the organization's feeds
+func (r *calendarFeedRepository) ForOrganization(organizationID uint) CalendarFeedRepository {
+	return &calendarFeedRepository{db: ForOrganization(r.db, organizationID)}
+}
+
+// Replace stores a new feed for the user, revoking the previous one
+func (r *calendarFeedRepository) Replace(feed *models.CalendarFeed) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", feed.UserID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(feed).Error
+	})
+}
+
+// FindByTokenHash returns the feed with the given token hash in any organization, or nil if it does not exist
+func (r *calendarFeedRepository) FindByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := AllOrganizations(r.db).Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// DeleteByUserID revokes the user's feed
+func (r *calendarFeedRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error
+}
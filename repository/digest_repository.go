@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// digestSectionLimit caps the number of tasks listed in each section of a digest
+const digestSectionLimit = 50
+
+// DigestRepository defines the interface for digest settings and digest content. It works
+// across organizations, since a digest covers all of a user's tasks.
+type DigestRepository interface {
+	GetSettings(userID uint) (*models.DigestSettings, error)
+	SaveSettings(settings *models.DigestSettings) error
+	GetDue(now time.Time) ([]models.DigestSettings, error)
+	MarkSent(userID uint, sentAt time.Time, next *time.Time) error
+	GetDigest(userID uint, since, until, dueUntil time.Time) (*models.Digest, error)
+}
+
+type digestRepository struct {
+	db *gorm.DB
+}
+
+// NewDigestRepository initializes a new instance of DigestRepository
+func NewDigestRepository(db *gorm.DB) DigestRepository {
+	return &digestRepository{db: AllOrganizations(db)}
+}
+
+// GetSettings returns the digest settings of a user, or nil if the user never changed them
+func (r *digestRepository) GetSettings(userID uint) (*models.DigestSettings, error) {
+	var settings models.DigestSettings
+	if err := r.db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the digest settings of the user
+func (r *digestRepository) SaveSettings(settings *models.DigestSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "send_time", "weekday", "time_zone", "next_send_at", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// GetDue returns the settings of the users whose next digest is due
+func (r *digestRepository) GetDue(now time.Time) ([]models.DigestSettings, error) {
+	var due []models.DigestSettings
+	err := r.db.Where("frequency <> ? AND next_send_at <= ?", models.DigestOff, now).Order("next_send_at, id").Find(&due).Error
+	return due, err
+}
+
+// MarkSent records that a digest was compiled for the user and when the next one is due
+func (r *digestRepository) MarkSent(userID uint, sentAt time.Time, next *time.Time) error {
+	return r.db.Model(&models.DigestSettings{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"last_sent_at": sentAt, "next_send_at": next}).Error
+}
+
+// GetDigest compiles the tasks the user created, is assigned to or watches: open tasks due
+// after until and up to dueUntil, overdue ones, and those assigned to the user or completed
+// between since and until.
+func (r *digestRepository) GetDigest(userID uint, since, until, dueUntil time.Time) (*models.Digest, error) {
+	digest := &models.Digest{Since: since, Until: until}
+	assigned := r.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userID)
+	watched := r.db.Model(&models.TaskWatcher{}).Select("task_id").Where("user_id = ?", userID)
+	visible := func() *gorm.DB {
+		return r.db.Model(&models.Task{}).
+			Where(r.db.Where("user_id = ?", userID).Or("id IN (?)", assigned).Or("id IN (?)", watched)).
+			Limit(digestSectionLimit)
+	}
+
+	err := visible().Where("status_category <> ? AND due_date > ? AND due_date <= ?", models.StatusCategoryDone, until, dueUntil).
+		Order("due_date, id").Find(&digest.DueSoon).Error
+	if err != nil {
+		return nil, err
+	}
+	err = visible().Where("status_category <> ? AND due_date <= ?", models.StatusCategoryDone, until).
+		Order("due_date, id").Find(&digest.Overdue).Error
+	if err != nil {
+		return nil, err
+	}
+	newlyAssigned := r.db.Model(&models.TaskAssignee{}).Select("task_id").
+		Where("user_id = ? AND created_at > ? AND created_at <= ?", userID, since, until)
+	err = r.db.Where("id IN (?)", newlyAssigned).Order("id").Limit(digestSectionLimit).Find(&digest.Assigned).Error
+	if err != nil {
+		return nil, err
+	}
+	err = visible().Where("status_category = ? AND updated_at > ? AND updated_at <= ?", models.StatusCategoryDone, since, until).
+		Order("updated_at DESC, id").Find(&digest.Completed).Error
+	if err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
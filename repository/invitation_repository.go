@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository defines the interface for organization invitation database operations
+type InvitationRepository interface {
+	Create(invitation *models.Invitation) error
+	GetByID(organizationID, id uint) (*models.Invitation, error)
+	GetPending(organizationID uint, now time.Time) ([]models.Invitation, error)
+	FindByTokenHash(tokenHash string) (*models.Invitation, error)
+	Renew(invitation *models.Invitation) error
+	Delete(organizationID, id uint) error
+	Accept(invitation *models.Invitation, member *models.OrganizationMember, at time.Time) (bool, error)
+	WithTx(tx *gorm.DB) InvitationRepository
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository initializes a new instance of InvitationRepository
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction
+func (r *invitationRepository) WithTx(tx *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: tx}
+}
+
+// Create stores a new invitation, replacing unaccepted ones for the same email in the organization
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	return ForOrganization(r.db, invitation.OrganizationID).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("LOWER(email) = LOWER(?) AND accepted_at IS NULL", invitation.Email).
+			Delete(&models.Invitation{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+}
+
+// GetByID returns an invitation of the organization, or nil if it does not exist
+func (r *invitationRepository) GetByID(organizationID, id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := ForOrganization(r.db, organizationID).First(&invitation, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPending returns the organization's unaccepted, unexpired invitations, newest first
+func (r *invitationRepository) GetPending(organizationID uint, now time.Time) ([]models.Invitation, error) {
+	invitations := []models.Invitation{}
+	err := ForOrganization(r.db, organizationID).
+		Where("accepted_at IS NULL AND expires_at > ?", now).
+		Order("created_at DESC, id DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// FindByTokenHash returns the invitation with the given token hash in any organization,
+// or nil if it does not exist
+func (r *invitationRepository) FindByTokenHash(tokenHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := AllOrganizations(r.db).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// Renew saves a new token hash and expiry, which invalidates the previous token
+func (r *invitationRepository) Renew(invitation *models.Invitation) error {
+	return ForOrganization(r.db, invitation.OrganizationID).Model(invitation).Updates(map[string]interface{}{
+		"token_hash": invitation.TokenHash,
+		"expires_at": invitation.ExpiresAt,
+	}).Error
+}
+
+// Delete removes an invitation of the organization
+func (r *invitationRepository) Delete(organizationID, id uint) error {
+	return ForOrganization(r.db, organizationID).Delete(&models.Invitation{}, id).Error
+}
+
+// Accept marks the invitation as accepted, adds the member and marks their email address as
+// verified, since the token was sent to it, in one transaction. It reports false if the
+// invitation was already accepted or has expired.
+func (r *invitationRepository) Accept(invitation *models.Invitation, member *models.OrganizationMember, at time.Time) (bool, error) {
+	accepted := false
+	err := ForOrganization(r.db, invitation.OrganizationID).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND expires_at > ?", invitation.ID, at).
+			Updates(map[string]interface{}{"accepted_at": at, "accepted_by": member.UserID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		accepted = true
+		if err := tx.Omit("Organization").Create(member).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND verified_at IS NULL", member.UserID).
+			Update("verified_at", at).Error
+	})
+	return accepted, err
+}
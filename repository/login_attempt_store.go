@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptStore keeps failed login counters. Failures older than the window
+// passed to Increment are forgotten.
+type LoginAttemptStore interface {
+	Get(key string) (*models.LoginAttempt, error)
+	Increment(key string, at time.Time, window time.Duration) (*models.LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository creates a LoginAttemptStore backed by Postgres, shared by all API instances
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptStore {
+	return &loginAttemptRepository{db: db}
+}
+
+// Get returns the counter for a key, or nil if there were no recent failures
+func (r *loginAttemptRepository) Get(key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := r.db.Where("key = ?", key).First(&attempt).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Increment atomically adds a failure, starting over if the last one is older than window
+func (r *loginAttemptRepository) Increment(key string, at time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		row := models.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", at.Add(-window)),
+				"last_failure_at": at,
+			}),
+		}).Create(&row).Error; err != nil {
+			return err
+		}
+		return tx.Where("key = ?", key).First(&attempt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Lock blocks logins for the key until the given time
+func (r *loginAttemptRepository) Lock(key string, until time.Time) error {
+	return r.db.Model(&models.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+// Reset forgets all failures and locks of the key
+func (r *loginAttemptRepository) Reset(key string) error {
+	return r.db.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// MemoryLoginAttemptStore keeps counters in process memory. It suits a single instance and tests.
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+// NewMemoryLoginAttemptStore creates an empty MemoryLoginAttemptStore
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{attempts: make(map[string]models.LoginAttempt)}
+}
+
+// Get returns the counter for a key, or nil if there were no recent failures
+func (s *MemoryLoginAttemptStore) Get(key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+// Increment adds a failure, starting over if the last one is older than window
+func (s *MemoryLoginAttemptStore) Increment(key string, at time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(at.Add(-window)) {
+		attempt = models.LoginAttempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	s.attempts[key] = attempt
+	return &attempt, nil
+}
+
+// Lock blocks logins for the key until the given time
+func (s *MemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt := s.attempts[key]
+	attempt.Key = key
+	attempt.LockedUntil = &until
+	s.attempts[key] = attempt
+	return nil
+}
+
+// Reset forgets all failures and locks of the key
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
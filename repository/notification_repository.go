@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository defines the interface for notification inbox database operations
+type NotificationRepository interface {
+	CreateBatch(notifications []models.Notification) error
+	GetPage(userID uint, filter models.NotificationFilter) (*models.NotificationPage, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID, id uint, at time.Time) (bool, error)
+	MarkAllRead(userID uint, at time.Time) (int64, error)
+	GetPreferences(userID uint) ([]models.NotificationPreference, error)
+	SetPreferences(userID uint, preferences map[string]bool) error
+	GetDisabledUsers(userIDs []uint, notificationType string) ([]uint, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository initializes a new instance of NotificationRepository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// CreateBatch adds several notifications in a single statement
+func (r *notificationRepository) CreateBatch(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// GetPage retrieves one page of a user's notifications, newest first, with the unread count
+func (r *notificationRepository) GetPage(userID uint, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	page := &models.NotificationPage{Notifications: []models.Notification{}, Page: filter.Page, PageSize: filter.PageSize}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	unread, err := r.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	page.Unread = unread
+	err = query.Order("created_at DESC, id DESC").Offset(filter.Offset()).Limit(filter.PageSize).Find(&page.Notifications).Error
+	return page, err
+}
+
+// CountUnread returns the number of unread notifications of a user
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a notification of the user as read, keeping the time it was first read.
+// It reports false if the user has no such notification.
+func (r *notificationRepository) MarkRead(userID, id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many there were
+func (r *notificationRepository) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// GetPreferences returns the notification types the user turned on or off
+func (r *notificationRepository) GetPreferences(userID uint) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Order("type").Find(&preferences).Error
+	return preferences, err
+}
+
+// SetPreferences turns the given notification types on or off for the user
+func (r *notificationRepository) SetPreferences(userID uint, preferences map[string]bool) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	rows := make([]models.NotificationPreference, 0, len(preferences))
+	for notificationType, enabled := range preferences {
+		rows = append(rows, models.NotificationPreference{UserID: userID, Type: notificationType, Enabled: enabled})
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+// GetDisabledUsers returns which of the users turned the notification type off
+func (r *notificationRepository) GetDisabledUsers(userIDs []uint, notificationType string) ([]uint, error) {
+	var disabled []uint
+	if len(userIDs) == 0 {
+		return disabled, nil
+	}
+	err := r.db.Model(&models.NotificationPreference{}).
+		Where("user_id IN ? AND type = ? AND enabled = ?", userIDs, notificationType, false).
+		Pluck("user_id", &disabled).Error
+	return disabled, err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository defines the interface for organization and membership database operations
+type OrganizationRepository interface {
+	Create(organization *models.Organization, ownerID uint) error
+	GetByID(id uint) (*models.Organization, error)
+	Update(organization *models.Organization) error
+	GetMemberships(userID uint) ([]models.OrganizationMember, error)
+	GetMember(organizationID, userID uint) (*models.OrganizationMember, error)
+	GetFirstMembership(userID uint) (*models.OrganizationMember, error)
+	CountOwners(organizationID uint) (int64, error)
+	AddMember(member *models.OrganizationMember) error
+	UpdateMemberRole(organizationID, userID uint, role string) error
+	RemoveMember(organizationID, userID uint) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository initializes a new instance of OrganizationRepository
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// Create adds a new organization together with its owner membership
+func (r *organizationRepository) Create(organization *models.Organization, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createOrganization(tx, organization, ownerID)
+	})
+}
+
+// createOrganization adds an organization and its owner membership inside a transaction
+func createOrganization(tx *gorm.DB, organization *models.Organization, ownerID uint) error {
+	if err := tx.Omit("Members").Create(organization).Error; err != nil {
+		return err
+	}
+	owner := &models.OrganizationMember{OrganizationID: organization.ID, UserID: ownerID, Role: models.OrganizationRoleOwner}
+	if err := tx.Omit("Organization").Create(owner).Error; err != nil {
+		return err
+	}
+	organization.Members = []models.OrganizationMember{*owner}
+	return nil
+}
+
+// GetByID retrieves an organization with its members
+func (r *organizationRepository) GetByID(id uint) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("organization_members.created_at, organization_members.id")
+	}).First(&organization, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// Update saves the organization's name
+func (r *organizationRepository) Update(organization *models.Organization) error {
+	return r.db.Model(organization).Update("name", organization.Name).Error
+}
+
+// GetMemberships retrieves the user's memberships with their organizations, oldest first
+func (r *organizationRepository) GetMemberships(userID uint) ([]models.OrganizationMember, error) {
+	memberships := []models.OrganizationMember{}
+	err := r.db.Preload("Organization").
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Order("organization_members.created_at, organization_members.id").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// GetMember returns the membership of a user in an organization, or nil if the user is not a member
+func (r *organizationRepository) GetMember(organizationID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.organization_id = ? AND organization_members.user_id = ?", organizationID, userID).
+		First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetFirstMembership returns the user's oldest membership, or nil if the user belongs to no organization
+func (r *organizationRepository) GetFirstMembership(userID uint) (*models.OrganizationMember, error) {
+	memberships, err := r.GetMemberships(userID)
+	if err != nil || len(memberships) == 0 {
+		return nil, err
+	}
+	return &memberships[0], nil
+}
+
+// CountOwners returns the number of owners of an organization
+func (r *organizationRepository) CountOwners(organizationID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrganizationRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// AddMember adds a user to an organization
+func (r *organizationRepository) AddMember(member *models.OrganizationMember) error {
+	return r.db.Omit("Organization").Create(member).Error
+}
+
+// UpdateMemberRole changes the role of a member
+func (r *organizationRepository) UpdateMemberRole(organizationID, userID uint, role string) error {
+	return r.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role).Error
+}
+
+// RemoveMember removes a user from an organization together with their project memberships
+// and calendar feed in it. Projects they own are handed over to the longest-standing other
+// project member, or to the longest-standing organization owner if nobody else is left;
+// their tasks stay in the organization.
+func (r *organizationRepository) RemoveMember(organizationID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		scoped := ForOrganization(tx, organizationID)
+		_, orphans, err := handOverProjects(scoped, userID)
+		if err != nil {
+			return err
+		}
+		if len(orphans) > 0 {
+			var owner models.OrganizationMember
+			err := tx.Where("organization_id = ? AND user_id <> ? AND role = ?", organizationID, userID, models.OrganizationRoleOwner).
+				Order("created_at, id").
+				First(&owner).Error
+			if err != nil {
+				return err
+			}
+			for _, project := range orphans {
+				if err := scoped.Model(&models.Project{}).Unscoped().Where("id = ?", project.ID).Update("owner_id", owner.UserID).Error; err != nil {
+					return err
+				}
+				member := &models.ProjectMember{ProjectID: project.ID, UserID: owner.UserID, Role: models.ProjectRoleOwner}
+				if err := scoped.Create(member).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := scoped.Where("user_id = ?", userID).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		if err := scoped.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&models.OrganizationMember{}).Error
+	})
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository defines the interface for password reset token database operations
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindByTokenHash(tokenHash string) (*models.PasswordResetToken, error)
+	Consume(token *models.PasswordResetToken, passwordHash string, at time.Time) (bool, error)
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository initializes a new instance of PasswordResetRepository
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create stores a new reset token, invalidating the user's unused ones
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", token.UserID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindByTokenHash returns the token with the given hash, or nil if it does not exist
+func (r *passwordResetRepository) FindByTokenHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume marks the token as used, sets the new password hash, revokes the user's sessions
+// and lifts a required reset in one transaction. It reports false if the token was already used.
+func (r *passwordResetRepository) Consume(token *models.PasswordResetToken, passwordHash string, at time.Time) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		consumed = true
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"sessions_revoked_at": at,
+			"must_reset_password": false,
+		}).Error
+	})
+	return consumed, err
+}
@@ -0,0 +1,459 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// PersonalDataRepository defines the interface for exporting and erasing everything stored about a user
+type PersonalDataRepository interface {
+	GetPersonalData(userID uint) (*models.PersonalData, error)
+	QueueErasure(record *models.ErasureRecord) error
+	GetPendingErasures() ([]models.ErasureRecord, error)
+	Erase(record *models.ErasureRecord) error
+	RecordErasureFailure(record *models.ErasureRecord, message string) error
+	GetErasureRecords() ([]models.ErasureRecord, error)
+}
+
+type personalDataRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonalDataRepository creates a new instance of PersonalDataRepository
+func NewPersonalDataRepository(db *gorm.DB) PersonalDataRepository {
+	return &personalDataRepository{db: AllOrganizations(db)}
+}
+
+// GetPersonalData loads the user's account and related records, except tasks, or nil if the user does not exist
+func (r *personalDataRepository) GetPersonalData(userID uint) (*models.PersonalData, error) {
+	// Empty lists are exported as [] rather than null
+	data := &models.PersonalData{
+		Organizations: []models.OrganizationMember{},
+		Invitations:   []models.Invitation{},
+		Projects:      []models.Project{},
+		Tags:          []models.Tag{},
+		TaskTags:      []models.TaskTagLink{},
+		Assignments:   []models.TaskAssignee{},
+		AssignmentLog: []models.TaskAssignmentChange{},
+		Watching:      []models.TaskWatcher{},
+		SavedViews:    []models.SavedView{},
+		APIKeys:       []models.APIKey{},
+		CalendarFeeds: []models.CalendarFeed{},
+		Notifications: []models.Notification{},
+		Preferences:   []models.NotificationPreference{},
+		AuditEntries:  []models.AuditEntry{},
+	}
+	if err := r.db.First(&data.User, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	err := r.db.Preload("Organization").Where("user_id = ?", userID).Order("id").Find(&data.Organizations).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Where("LOWER(email) = LOWER(?) OR invited_by = ?", data.User.Email, userID).Order("id").Find(&data.Invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Preload("Members").
+		Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ?", userID).
+		Order("projects.id").
+		Find(&data.Projects).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Tags).Error; err != nil {
+		return nil, err
+	}
+	err = r.db.Table("task_tags").
+		Select("task_tags.task_id, task_tags.tag_id").
+		Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+		Where("tasks.user_id = ? AND tasks.deleted_at IS NULL", userID).
+		Order("task_tags.task_id, task_tags.tag_id").
+		Scan(&data.TaskTags).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Assignments).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ? OR changed_by = ?", userID, userID).Order("id").Find(&data.AssignmentLog).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Watching).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.SavedViews).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.APIKeys).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.CalendarFeeds).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Notifications).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("type").Find(&data.Preferences).Error; err != nil {
+		return nil, err
+	}
+	var digest []models.DigestSettings
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&digest).Error; err != nil {
+		return nil, err
+	}
+	if len(digest) > 0 {
+		data.Digest = &digest[0]
+	}
+	if err := r.db.Where("actor_id = ? OR target_user_id = ?", userID, userID).Order("id").Find(&data.AuditEntries).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// QueueErasure disables the user's account, revokes their sessions and stores the pending
+// erasure record. If an erasure of the user is already pending, record is set to it instead.
+// It returns gorm.ErrRecordNotFound if the user does not exist.
+func (r *personalDataRepository) QueueErasure(record *models.ErasureRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", record.UserID).
+			Updates(map[string]interface{}{
+				"disabled_at":         gorm.Expr("COALESCE(disabled_at, ?)", record.RequestedAt),
+				"sessions_revoked_at": record.RequestedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var pending []models.ErasureRecord
+		if err := tx.Where("user_id = ? AND completed_at IS NULL", record.UserID).Limit(1).Find(&pending).Error; err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			*record = pending[0]
+			return nil
+		}
+		return tx.Create(record).Error
+	})
+}
+
+// GetPendingErasures returns the erasure records that are not completed yet, oldest first
+func (r *personalDataRepository) GetPendingErasures() ([]models.ErasureRecord, error) {
+	var records []models.ErasureRecord
+	err := r.db.Where("completed_at IS NULL").Order("id").Find(&records).Error
+	return records, err
+}
+
+// RecordErasureFailure stores why an attempt to complete the erasure failed
+func (r *personalDataRepository) RecordErasureFailure(record *models.ErasureRecord, message string) error {
+	return r.db.Model(record).Update("last_error", message).Error
+}
+
+// Erase hard-deletes the user and everything they own, including soft-deleted rows, and
+// completes the erasure record in the same transaction with the number of affected rows per
+// table. Tasks in projects shared with other members are kept without an author, but with
+// their title and description as project content; owned projects
+// that still have other members are handed over to the longest-standing member, organizations
+// the user is the only owner of are handed over the same way, invitations the user sent are
+// kept without the sender, and audit log entries are kept without their details and IP addresses.
+func (r *personalDataRepository) Erase(record *models.ErasureRecord) error {
+	userID := record.UserID
+	counts := map[string]int64{}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Select("id", "email").First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		transferred, orphans, err := handOverProjects(tx, userID)
+		if err != nil {
+			return err
+		}
+		counts["projects_transferred"] = transferred
+		for _, project := range orphans {
+			if err := deleteProject(tx, project.ID); err != nil {
+				return err
+			}
+			counts["projects_deleted"]++
+		}
+
+		transferred, emptyOrganizations, err := handOverOrganizations(tx, userID)
+		if err != nil {
+			return err
+		}
+		counts["organizations_transferred"] = transferred
+
+		// Tasks in projects that other members still use are pseudonymized
+		sharedProjects := tx.Model(&models.ProjectMember{}).Select("project_id").Where("user_id <> ?", userID)
+		result := tx.Model(&models.Task{}).Unscoped().
+			Where("user_id = ? AND project_id IN (?)", userID, sharedProjects).
+			Update("user_id", 0)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["tasks_pseudonymized"] = result.RowsAffected
+		// Kept tasks no longer mention the user's address
+		pattern := regexp.QuoteMeta(user.Email)
+		result = tx.Model(&models.Task{}).Unscoped().
+			Where("title ~* ? OR description ~* ?", pattern, pattern).
+			UpdateColumns(map[string]interface{}{
+				"title":       gorm.Expr("regexp_replace(title, ?, ?, 'gi')", pattern, models.ErasedMention),
+				"description": gorm.Expr("regexp_replace(description, ?, ?, 'gi')", pattern, models.ErasedMention),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_mentions_scrubbed"] = result.RowsAffected
+		// User fields of tasks no longer point to the user
+		var userFields []models.CustomField
+		if err := tx.Where("type = ?", models.FieldTypeUser).Find(&userFields).Error; err != nil {
+			return err
+		}
+		for _, field := range userFields {
+			result = tx.Model(&models.Task{}).Unscoped().
+				Where("project_id = ? AND custom_fields ->> ?::text = ?", field.ProjectID, field.Key, strconv.FormatUint(uint64(userID), 10)).
+				UpdateColumn("custom_fields", gorm.Expr("custom_fields - ?::text", field.Key))
+			if result.Error != nil {
+				return result.Error
+			}
+			counts["custom_field_values"] += result.RowsAffected
+		}
+
+		// Everything else of the user is deleted
+		userTasks := tx.Model(&models.Task{}).Unscoped().Select("id").Where("user_id = ?", userID)
+		userTags := tx.Model(&models.Tag{}).Select("id").Where("user_id = ?", userID)
+		result = tx.Exec("DELETE FROM task_tags WHERE task_id IN (?) OR tag_id IN (?)", userTasks, userTags)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_tags"] = result.RowsAffected
+
+		// Assignments to the user and to the user's tasks go; changes the user made to other
+		// tasks stay in their history without the author
+		result = tx.Where("user_id = ? OR task_id IN (?)", userID, userTasks).Delete(&models.TaskAssignee{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_assignees"] = result.RowsAffected
+		result = tx.Where("user_id = ? OR task_id IN (?)", userID, userTasks).Delete(&models.TaskAssignmentChange{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_assignment_changes"] = result.RowsAffected
+		result = tx.Model(&models.TaskAssignmentChange{}).Where("changed_by = ?", userID).Update("changed_by", 0)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_assignment_changes_pseudonymized"] = result.RowsAffected
+		if err := tx.Model(&models.TaskAssignee{}).Where("assigned_by = ?", userID).Update("assigned_by", 0).Error; err != nil {
+			return err
+		}
+		result = tx.Where("user_id = ? OR task_id IN (?)", userID, userTasks).Delete(&models.TaskWatcher{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_watchers"] = result.RowsAffected
+		// Blockers between the user's tasks and others go; those the user set on other tasks stay
+		result = tx.Where("task_id IN (?) OR blocker_id IN (?)", userTasks, userTasks).Delete(&models.TaskBlocker{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["task_blockers"] = result.RowsAffected
+		if err := tx.Model(&models.TaskBlocker{}).Where("created_by = ?", userID).Update("created_by", 0).Error; err != nil {
+			return err
+		}
+		// Notifications about the user's tasks go; others no longer name the user as actor
+		result = tx.Where("task_id IN (?)", userTasks).Where("user_id <> ?", userID).Delete(&models.Notification{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["notifications_about_tasks"] = result.RowsAffected
+		result = tx.Model(&models.Notification{}).Where("actor_id = ?", userID).Update("actor_id", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["notifications_pseudonymized"] = result.RowsAffected
+		userViews := tx.Model(&models.SavedView{}).Unscoped().Select("id").Where("user_id = ?", userID)
+		if err := tx.Model(&models.User{}).Where("default_view_id IN (?)", userViews).Update("default_view_id", nil).Error; err != nil {
+			return err
+		}
+
+		// Invitations to the user's address go; those they sent stay without the sender
+		userEmail := tx.Model(&models.User{}).Unscoped().Select("LOWER(email)").Where("id = ?", userID)
+		result = tx.Where("LOWER(email) IN (?) OR accepted_by = ?", userEmail, userID).Delete(&models.Invitation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["invitations"] = result.RowsAffected
+		result = tx.Model(&models.Invitation{}).Where("invited_by = ?", userID).Update("invited_by", 0)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["invitations_pseudonymized"] = result.RowsAffected
+		for _, table := range []struct {
+			name  string
+			model interface{}
+		}{
+			{"tasks", &models.Task{}},
+			{"tags", &models.Tag{}},
+			{"saved_views", &models.SavedView{}},
+			{"project_members", &models.ProjectMember{}},
+			{"calendar_feeds", &models.CalendarFeed{}},
+			{"api_keys", &models.APIKey{}},
+			{"password_reset_tokens", &models.PasswordResetToken{}},
+			{"recovery_codes", &models.RecoveryCode{}},
+			{"organization_members", &models.OrganizationMember{}},
+			{"notifications", &models.Notification{}},
+			{"notification_preferences", &models.NotificationPreference{}},
+			{"digest_settings", &models.DigestSettings{}},
+		} {
+			result := tx.Unscoped().Where("user_id = ?", userID).Delete(table.model)
+			if result.Error != nil {
+				return result.Error
+			}
+			counts[table.name] = result.RowsAffected
+		}
+		// Organizations nobody else is left in go with the memberships
+		for _, organizationID := range emptyOrganizations {
+			if err := tx.Unscoped().Delete(&models.Organization{}, organizationID).Error; err != nil {
+				return err
+			}
+			counts["organizations_deleted"]++
+		}
+
+		// The audit log is kept for accountability, without the personal details it recorded
+		result = tx.Model(&models.AuditEntry{}).Where("target_user_id = ?", userID).Update("details", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		counts["audit_entries_pseudonymized"] = result.RowsAffected
+		if err := tx.Model(&models.AuditEntry{}).Where("actor_id = ?", userID).Update("ip", "").Error; err != nil {
+			return err
+		}
+
+		result = tx.Unscoped().Delete(&models.User{}, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		counts["users"] = result.RowsAffected
+
+		record.Counts = counts
+		record.LastError = ""
+		return tx.Save(record).Error
+	})
+}
+
+// GetErasureRecords returns all erasure records, newest first
+func (r *personalDataRepository) GetErasureRecords() ([]models.ErasureRecord, error) {
+	var records []models.ErasureRecord
+	err := r.db.Order("requested_at DESC, id DESC").Find(&records).Error
+	return records, err
+}
+
+// handOverProjects makes the longest-standing other member the owner of each project the user
+// owns in the session's organization. It returns the number of transferred projects and the
+// projects without other members.
+func handOverProjects(tx *gorm.DB, userID uint) (int64, []models.Project, error) {
+	var owned []models.Project
+	if err := tx.Unscoped().Where("owner_id = ?", userID).Find(&owned).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var transferred int64
+	var orphans []models.Project
+	for _, project := range owned {
+		var successor models.ProjectMember
+		err := tx.Where("project_id = ? AND user_id <> ?", project.ID, userID).Order("created_at, id").First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			orphans = append(orphans, project)
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := tx.Model(&models.Project{}).Unscoped().Where("id = ?", project.ID).Update("owner_id", successor.UserID).Error; err != nil {
+			return 0, nil, err
+		}
+		if err := tx.Model(&successor).Update("role", models.ProjectRoleOwner).Error; err != nil {
+			return 0, nil, err
+		}
+		transferred++
+	}
+	return transferred, orphans, nil
+}
+
+// handOverOrganizations makes the longest-standing other member an owner of each organization
+// the user is the only owner of. It returns the number of transferred organizations and the
+// IDs of those without other members.
+func handOverOrganizations(tx *gorm.DB, userID uint) (int64, []uint, error) {
+	var owned []models.OrganizationMember
+	err := tx.Where("user_id = ? AND role = ?", userID, models.OrganizationRoleOwner).Find(&owned).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var transferred int64
+	var empty []uint
+	for _, membership := range owned {
+		var others []models.OrganizationMember
+		err := tx.Where("organization_id = ? AND user_id <> ?", membership.OrganizationID, userID).
+			Order("created_at, id").
+			Find(&others).Error
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(others) == 0 {
+			empty = append(empty, membership.OrganizationID)
+			continue
+		}
+		hasOwner := false
+		for _, other := range others {
+			hasOwner = hasOwner || other.Role == models.OrganizationRoleOwner
+		}
+		if hasOwner {
+			continue
+		}
+		if err := tx.Model(&others[0]).Update("role", models.OrganizationRoleOwner).Error; err != nil {
+			return 0, nil, err
+		}
+		transferred++
+	}
+	return transferred, empty, nil
+}
+
+// deleteProject deletes a project with its memberships, board, workflow and custom fields. Remaining tasks
+// and views that referred to it lose their project, and the tasks its field values.
+func deleteProject(tx *gorm.DB, projectID uint) error {
+	err := tx.Model(&models.Task{}).Unscoped().Where("project_id = ?", projectID).
+		Updates(map[string]interface{}{"project_id": nil, "custom_fields": gorm.Expr("'{}'::jsonb")}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&models.SavedView{}).Unscoped().Where("project_id = ?", projectID).Update("project_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectMember{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&models.BoardColumn{}, &models.WorkflowTransition{}, &models.WorkflowStatus{}, &models.CustomField{}} {
+		if err := tx.Where("project_id = ?", projectID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Delete(&models.Project{}, projectID).Error
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// ProjectRepository defines the interface for project-related database operations
+type ProjectRepository interface {
+	Create(project *models.Project) error
+	GetByID(id uint) (*models.Project, error)
+	GetByUserID(userID uint, projects *[]models.Project) error
+	AddMember(member *models.ProjectMember) error
+	RemoveMember(projectID, userID uint) error
+	GetMember(projectID, userID uint) (*models.ProjectMember, error)
+	GetBoardColumns(projectID uint) ([]models.BoardColumn, error)
+	SetBoardColumns(projectID uint, columns []models.BoardColumn) error
+	GetWorkflowStatuses(projectID uint) ([]models.WorkflowStatus, error)
+	CountTasksByStatus(projectID uint) (map[string]int64, error)
+	SetWorkflow(projectID uint, statuses []models.WorkflowStatus, moves map[string]string) error
+	GetCustomFields(projectID uint) ([]models.CustomField, error)
+	SetCustomFields(projectID uint, fields []models.CustomField) error
+	WithTx(tx *gorm.DB) ProjectRepository
+	ForOrganization(organizationID uint) ProjectRepository
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository initializes a new instance of ProjectRepository
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction
+func (r *projectRepository) WithTx(tx *gorm.DB) ProjectRepository {
+	return &projectRepository{db: sameOrganization(tx, r.db)}
+}
+
+// ForOrganization returns a repository restricted to the organization's projects
+func (r *projectRepository) ForOrganization(organizationID uint) ProjectRepository {
+	return &projectRepository{db: ForOrganization(r.db, organizationID)}
+}
+
+// Create adds a new project together with its owner membership
+func (r *projectRepository) Create(project *models.Project) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(project).Error; err != nil {
+			return err
+		}
+		owner := &models.ProjectMember{ProjectID: project.ID, UserID: project.OwnerID, Role: models.ProjectRoleOwner}
+		if err := tx.Create(owner).Error; err != nil {
+			return err
+		}
+		project.Members = []models.ProjectMember{*owner}
+		return nil
+	})
+}
+
+// GetByID retrieves a project with its members
+func (r *projectRepository) GetByID(id uint) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.Preload("Members").First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetByUserID retrieves all projects the user is a member of
+func (r *projectRepository) GetByUserID(userID uint, projects *[]models.Project) error {
+	return r.db.
+		Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ?", userID).
+		Order("projects.id").
+		Find(projects).Error
+}
+
+// AddMember adds a user to a project
+func (r *projectRepository) AddMember(member *models.ProjectMember) error {
+	return r.db.Create(member).Error
+}
+
+// RemoveMember removes a user from a project
+func (r *projectRepository) RemoveMember(projectID, userID uint) error {
+	return r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{}).Error
+}
+
+// GetMember returns the membership of a user in a project, or nil if the user is not a member
+func (r *projectRepository) GetMember(projectID, userID uint) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	if err := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetBoardColumns retrieves the configured columns of a project's board in board order. It
+// returns no columns if the board was not configured.
+func (r *projectRepository) GetBoardColumns(projectID uint) ([]models.BoardColumn, error) {
+	var columns []models.BoardColumn
+	err := r.db.Where("project_id = ?", projectID).Order("position, id").Find(&columns).Error
+	return columns, err
+}
+
+// SetBoardColumns replaces the columns of a project's board
+func (r *projectRepository) SetBoardColumns(projectID uint, columns []models.BoardColumn) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.BoardColumn{}).Error; err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return nil
+		}
+		return tx.Create(&columns).Error
+	})
+}
+
+// GetWorkflowStatuses retrieves the custom statuses of a project in workflow order, with
+// their transitions. It returns no statuses if the project uses the default workflow.
+func (r *projectRepository) GetWorkflowStatuses(projectID uint) ([]models.WorkflowStatus, error) {
+	var statuses []models.WorkflowStatus
+	if err := r.db.Where("project_id = ?", projectID).Order("position, id").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	var transitions []models.WorkflowTransition
+	if err := r.db.Where("project_id = ?", projectID).Order("id").Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.WorkflowStatus, len(statuses))
+	for i := range statuses {
+		statuses[i].Transitions = []string{}
+		byName[statuses[i].Name] = &statuses[i]
+	}
+	for _, transition := range transitions {
+		if status, ok := byName[transition.FromStatus]; ok {
+			status.Transitions = append(status.Transitions, transition.ToStatus)
+		}
+	}
+	return statuses, nil
+}
+
+// CountTasksByStatus counts the tasks of a project per status, including deleted tasks
+func (r *projectRepository) CountTasksByStatus(projectID uint) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&models.Task{}).Unscoped().Select("status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// SetWorkflow replaces the statuses of a project's workflow. Tasks move from the old status
+// to the new status given in moves, at the end of their new list, and every task of the
+// project gets the category of its status. It fails if a task is left in a status that is not
+// in the workflow. Board columns of removed statuses are deleted.
+func (r *projectRepository) SetWorkflow(projectID uint, statuses []models.WorkflowStatus, moves map[string]string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.WorkflowTransition{}, &models.WorkflowStatus{}} {
+			if err := tx.Where("project_id = ?", projectID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		var transitions []models.WorkflowTransition
+		names := make([]string, len(statuses))
+		for i, status := range statuses {
+			names[i] = status.Name
+			for _, to := range status.Transitions {
+				transitions = append(transitions, models.WorkflowTransition{ProjectID: projectID, FromStatus: status.Name, ToStatus: to})
+			}
+		}
+		if len(statuses) > 0 {
+			if err := tx.Create(&statuses).Error; err != nil {
+				return err
+			}
+		}
+		if len(transitions) > 0 {
+			if err := tx.Create(&transitions).Error; err != nil {
+				return err
+			}
+		}
+
+		tasks := func() *gorm.DB {
+			return tx.Model(&models.Task{}).Unscoped().Where("project_id = ?", projectID)
+		}
+		targets := map[string]bool{}
+		for from, to := range moves {
+			// Moved tasks lose their rank; the lists are rebalanced below with them at the end
+			err := tasks().Where("status = ?", from).Updates(map[string]interface{}{"status": to, "rank": ""}).Error
+			if err != nil {
+				return err
+			}
+			targets[to] = true
+		}
+		// A task left in a removed status was created after the moves were decided
+		var stranded []string
+		if err := tasks().Where("status NOT IN ?", names).Limit(1).Pluck("status", &stranded).Error; err != nil {
+			return err
+		}
+		if len(stranded) > 0 {
+			return fmt.Errorf("invalid status_mapping: tasks with status %q need a new status", stranded[0])
+		}
+		for _, status := range statuses {
+			if err := tasks().Where("status = ?", status.Name).UpdateColumn("status_category", status.Category).Error; err != nil {
+				return err
+			}
+		}
+		for target := range targets {
+			if err := rebalanceList(inRankList(tx.Model(&models.Task{}), &projectID, target)); err != nil {
+				return err
+			}
+		}
+		return tx.Where("project_id = ? AND status NOT IN ?", projectID, names).Delete(&models.BoardColumn{}).Error
+	})
+}
+
+// GetCustomFields retrieves the custom fields of a project in display order
+func (r *projectRepository) GetCustomFields(projectID uint) ([]models.CustomField, error) {
+	var fields []models.CustomField
+	err := r.db.Where("project_id = ?", projectID).Order("position, id").Find(&fields).Error
+	return fields, err
+}
+
+// SetCustomFields replaces the custom fields of a project. The values of removed fields are
+// deleted from the project's tasks, including deleted tasks.
+func (r *projectRepository) SetCustomFields(projectID uint, fields []models.CustomField) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []string
+		if err := tx.Model(&models.CustomField{}).Where("project_id = ?", projectID).Pluck("key", &current).Error; err != nil {
+			return err
+		}
+		kept := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			kept[field.Key] = true
+		}
+		for _, key := range current {
+			if kept[key] {
+				continue
+			}
+			err := tx.Model(&models.Task{}).Unscoped().
+				Where("project_id = ? AND custom_fields -> ?::text IS NOT NULL", projectID, key).
+				UpdateColumn("custom_fields", gorm.Expr("custom_fields - ?::text", key)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.CustomField{}).Error; err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		return tx.Create(&fields).Error
+	})
+}
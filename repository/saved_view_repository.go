@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// SavedViewRepository defines the interface for saved view database operations
+type SavedViewRepository interface {
+	Create(view *models.SavedView) error
+	GetByID(id uint) (*models.SavedView, error)
+	GetVisibleToUser(userID uint, views *[]models.SavedView) error
+	Update(view *models.SavedView) error
+	Delete(id uint) error
+	ForOrganization(organizationID uint) SavedViewRepository
+}
+
+type savedViewRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedViewRepository initializes a new instance of SavedViewRepository
+func NewSavedViewRepository(db *gorm.DB) SavedViewRepository {
+	return &savedViewRepository{db: db}
+}
+
+// ForOrganization returns a repository whose views are shared through the organization's projects.
+// Views themselves are not organization data, so only the project memberships are restricted.
+func (r *savedViewRepository) ForOrganization(organizationID uint) SavedViewRepository {
+	return &savedViewRepository{db: ForOrganization(r.db, organizationID)}
+}
+
+// Create adds a new saved view to the database
+func (r *savedViewRepository) Create(view *models.SavedView) error {
+	return r.db.Create(view).Error
+}
+
+// GetByID retrieves a saved view by its ID
+func (r *savedViewRepository) GetByID(id uint) (*models.SavedView, error) {
+	var view models.SavedView
+	if err := r.db.First(&view, id).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// GetVisibleToUser retrieves the user's own views and views shared with the user's projects
+func (r *savedViewRepository) GetVisibleToUser(userID uint, views *[]models.SavedView) error {
+	return r.db.
+		Where("user_id = ?", userID).
+		Or("project_id IN (?)", r.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID)).
+		Order("id").
+		Find(views).Error
+}
+
+// Update modifies an existing saved view
+func (r *savedViewRepository) Update(view *models.SavedView) error {
+	return r.db.Save(view).Error
+}
+
+// Delete removes a saved view by its ID and clears it as a default view
+func (r *savedViewRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("default_view_id = ?", id).Update("default_view_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SavedView{}, id).Error
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository defines the interface for tag-related database operations
+type TagRepository interface {
+	GetByUserID(userID uint, tags *[]models.Tag) error
+	FindOrCreate(userID uint, names []string) ([]models.Tag, error)
+	WithTx(tx *gorm.DB) TagRepository
+	ForOrganization(organizationID uint) TagRepository
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository initializes a new instance of TagRepository
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// WithTx returns a repository bound to the given transaction
+func (r *tagRepository) WithTx(tx *gorm.DB) TagRepository {
+	return &tagRepository{db: sameOrganization(tx, r.db)}
+}
+
+// ForOrganization returns a repository restricted to the organization's tags
+func (r *tagRepository) ForOrganization(organizationID uint) TagRepository {
+	return &tagRepository{db: ForOrganization(r.db, organizationID)}
+}
+
+// GetByUserID retrieves all tags of a user ordered by name
+func (r *tagRepository) GetByUserID(userID uint, tags *[]models.Tag) error {
+	return r.db.Where("user_id = ?", userID).Order("name").Find(tags).Error
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the missing ones
+func (r *tagRepository) FindOrCreate(userID uint, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	newTags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		newTags = append(newTags, models.Tag{Name: name, UserID: userID})
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []models.Tag
+	if err := r.db.Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
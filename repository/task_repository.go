@@ -1,8 +1,13 @@
 package repository
 
 import (
+	"encoding/json"
+	"strconv"
+	"time"
+
 	"github.com/EmelinDanila/task-manager-api/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TaskRepository defines the interface for interacting with tasks in the database
@@ -14,6 +19,31 @@ type TaskRepository interface {
 	Delete(id uint) error
 	GetByUserID(userID uint, tasks *[]models.Task) error
 	GetByIDAndUserID(taskID, userID uint, task *models.Task) error
+	GetFiltered(userID uint, filter models.TaskFilter, tasks *[]models.Task) (int64, error)
+	CreateBatch(tasks []models.Task) error
+	EachByUserID(userID uint, batchSize int, fn func(tasks []models.Task) error) error
+	GetByIDs(userID uint, ids []uint, tasks *[]models.Task) error
+	GetByICalUIDs(userID uint, uids []string, tasks *[]models.Task) error
+	SetAssignees(task *models.Task, userIDs []uint, changedBy uint) error
+	GetAssignmentHistory(taskID uint) ([]models.TaskAssignmentChange, error)
+	Watch(taskID, userID uint) error
+	Unwatch(taskID, userID uint) error
+	GetWatcherIDs(taskID uint) ([]uint, error)
+	SetBlockers(task *models.Task, blockerIDs []uint, changedBy uint) error
+	GetBlockerIDs(taskIDs []uint) ([]uint, error)
+	GetNextCandidates(userID uint) ([]models.Task, error)
+	CountOpenBlockers(taskIDs []uint) (blockedBy map[uint]int, blocks map[uint]int, err error)
+	GetAdjacentRank(projectID *uint, status, rank string, next bool, excludeID uint) (string, error)
+	UpdateRank(taskID uint, rank, status, category string) error
+	RebalanceRanks(projectID *uint, status string) error
+	RebalanceLongRanks() (int, error)
+	GetByProject(projectID uint) ([]models.Task, error)
+	CountInList(projectID *uint, status string, excludeID uint) (int64, error)
+	LockLists(projectID uint, fn func(repo TaskRepository) error) error
+	GetDueForReminder(from, to time.Time) ([]models.Task, error)
+	MarkReminded(ids []uint, at time.Time) error
+	WithTx(tx *gorm.DB) TaskRepository
+	ForOrganization(organizationID uint) TaskRepository
 }
 
 type taskRepository struct {
@@ -25,9 +55,31 @@ func NewTaskRepository(db *gorm.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
-// Create adds a new task to the database
+// WithTx returns a repository bound to the given transaction
+func (r *taskRepository) WithTx(tx *gorm.DB) TaskRepository {
+	return &taskRepository{db: sameOrganization(tx, r.db)}
+}
+
+// ForOrganization returns a repository restricted to the organization's tasks
+func (r *taskRepository) ForOrganization(organizationID uint) TaskRepository {
+	return &taskRepository{db: ForOrganization(r.db, organizationID)}
+}
+
+// Create adds a new task with its assignees to the database, records the assignments and
+// lets the creator and assignees watch the task
 func (r *taskRepository) Create(task *models.Task) error {
-	return r.db.Create(task).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := newRanker(tx).rankLast(task); err != nil {
+			return err
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		if err := recordAssignments(tx, []models.Task{*task}); err != nil {
+			return err
+		}
+		return addWatchers(tx, []models.Task{*task})
+	})
 }
 
 // GetByID retrieves a task by its ID
@@ -48,27 +100,39 @@ func (r *taskRepository) GetAll() ([]models.Task, error) {
 	return tasks, nil
 }
 
-// Update modifies an existing task in the database
+// Update modifies an existing task in the database; assignees and blockers are changed with
+// SetAssignees and SetBlockers
 func (r *taskRepository) Update(task *models.Task) error {
-	return r.db.Save(task).Error
+	return r.db.Omit("Assignees", "BlockedBy").Save(task).Error
 }
 
-// Delete removes a task from the database by its ID
+// Delete removes a task from the database by its ID, unblocking the tasks it blocked
 func (r *taskRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Task{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ? OR blocker_id = ?", id, id).Delete(&models.TaskBlocker{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Task{}, id).Error
+	})
 }
 
-// GetByUserID retrieves tasks for a specific user.
+// GetByUserID retrieves tasks created by a specific user, in their manual order.
 func (r *taskRepository) GetByUserID(userID uint, tasks *[]models.Task) error {
-	if err := r.db.Where("user_id = ?", userID).Find(tasks).Error; err != nil {
+	err := r.db.Preload("Tags").Preload("Assignees").Where("user_id = ?", userID).
+		Order("project_id, status, " + models.RankOrder + ", id").Find(tasks).Error
+	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetByIDAndUserID retrieves a task by its ID for a specific user.
+// GetByIDAndUserID retrieves a task by its ID if the user created it, is assigned to it or watches it.
 func (r *taskRepository) GetByIDAndUserID(taskID, userID uint, task *models.Task) error {
-	if err := r.db.Where("id = ? AND user_id = ?", taskID, userID).First(task).Error; err != nil {
+	err := r.db.Preload("Tags").Preload("Assignees").Preload("BlockedBy").
+		Where("id = ?", taskID).
+		Where(r.visibleTo(userID)).
+		First(task).Error
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return err
 		}
@@ -76,3 +140,559 @@ func (r *taskRepository) GetByIDAndUserID(taskID, userID uint, task *models.Task
 	}
 	return nil
 }
+
+// GetFiltered retrieves one page of the tasks a user created, is assigned to or watches that match the
+// filter and returns the total number of matches.
+func (r *taskRepository) GetFiltered(userID uint, filter models.TaskFilter, tasks *[]models.Task) (int64, error) {
+	query := r.db.Model(&models.Task{}).Where(r.visibleTo(userID))
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Where("status_category = ?", filter.Category)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("title ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+	if filter.Tag != "" {
+		query = query.Where("id IN (?)", r.db.Table("task_tags").
+			Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.name = ?", filter.Tag))
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("id IN (?)", r.assignedTo(*filter.AssigneeID))
+	}
+	if filter.WatcherID != nil {
+		query = query.Where("id IN (?)", r.watchedBy(*filter.WatcherID))
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.Important != nil {
+		query = query.Where("important = ?", *filter.Important)
+	}
+	if filter.Urgent != nil {
+		query = query.Where("urgent = ?", *filter.Urgent)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_date > ?", *filter.DueAfter)
+	}
+	if filter.Overdue {
+		query = query.Where("due_date < ? AND status_category <> ?", time.Now(), models.StatusCategoryDone)
+	}
+	for _, condition := range filter.CustomFields {
+		query = query.Where(customFieldCondition(condition))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	if key, ok := filter.CustomSortKey(); ok {
+		query = query.Clauses(customFieldOrder(key, filter.SortFieldType, filter.Order))
+	} else {
+		query = query.Order(filter.OrderClause())
+	}
+	err := query.Preload("Tags").Preload("Assignees").Preload("BlockedBy").
+		Offset(filter.Offset()).
+		Limit(filter.PageSize).
+		Find(tasks).Error
+	return total, err
+}
+
+// customFieldOperators maps the operators of custom field filters to SQL
+var customFieldOperators = map[string]string{"eq": "=", "gte": ">=", "lte": "<="}
+
+// customFieldValue reads the value of a custom field as a number for number and user fields,
+// and as text otherwise. Values of another type, left over in tasks moved between projects,
+// read as NULL.
+func customFieldValue(key, fieldType string) clause.Expr {
+	if fieldType == models.FieldTypeNumber || fieldType == models.FieldTypeUser {
+		return gorm.Expr("CASE WHEN jsonb_typeof(custom_fields -> ?::text) = 'number' THEN (custom_fields ->> ?::text)::numeric END", key, key)
+	}
+	return gorm.Expr("custom_fields ->> ?::text", key)
+}
+
+// customFieldCondition selects the tasks whose custom field matches the condition. Text
+// fields match when they contain the value, ignoring case, multi-select fields when the
+// value is one of their options, and checkboxes without a value count as unchecked.
+func customFieldCondition(condition models.CustomFieldFilter) clause.Expr {
+	switch condition.Type {
+	case models.FieldTypeText:
+		return gorm.Expr("custom_fields ->> ?::text ILIKE ?", condition.Key, "%"+condition.Value+"%")
+	case models.FieldTypeMultiSelect:
+		option, _ := json.Marshal([]string{condition.Value})
+		return gorm.Expr("custom_fields -> ?::text @> ?::jsonb", condition.Key, string(option))
+	case models.FieldTypeCheckbox:
+		return gorm.Expr("COALESCE(custom_fields ->> ?::text, 'false') = ?", condition.Key, condition.Value)
+	case models.FieldTypeNumber, models.FieldTypeUser:
+		return gorm.Expr("? "+customFieldOperators[condition.Op]+" ?::numeric", customFieldValue(condition.Key, condition.Type), condition.Value)
+	default:
+		return gorm.Expr("? "+customFieldOperators[condition.Op]+" ?", customFieldValue(condition.Key, condition.Type), condition.Value)
+	}
+}
+
+// customFieldOrder orders tasks by a custom field, with the tasks without a value last
+func customFieldOrder(key, fieldType, order string) clause.OrderBy {
+	direction := "ASC"
+	if order == "desc" {
+		direction = "DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                "? " + direction + " NULLS LAST, id " + direction,
+		Vars:               []interface{}{customFieldValue(key, fieldType)},
+		WithoutParentheses: true,
+	}}
+}
+
+// CreateBatch adds several tasks in a single transaction
+func (r *taskRepository) CreateBatch(tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		ranker := newRanker(tx)
+		for i := range tasks {
+			if err := ranker.rankLast(&tasks[i]); err != nil {
+				return err
+			}
+		}
+		if err := tx.CreateInBatches(&tasks, 100).Error; err != nil {
+			return err
+		}
+		if err := recordAssignments(tx, tasks); err != nil {
+			return err
+		}
+		return addWatchers(tx, tasks)
+	})
+}
+
+// EachByUserID walks through all tasks of a user in batches so they are never loaded into memory
+// at once. Tags, assignees and blockers are loaded with each batch.
+func (r *taskRepository) EachByUserID(userID uint, batchSize int, fn func(tasks []models.Task) error) error {
+	var batch []models.Task
+	return r.db.Preload("Tags").Preload("Assignees").Preload("BlockedBy").Where("user_id = ?", userID).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// GetByIDs retrieves the user's tasks with the given IDs
+func (r *taskRepository) GetByIDs(userID uint, ids []uint, tasks *[]models.Task) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(tasks).Error
+}
+
+// GetByICalUIDs retrieves the user's tasks imported with the given iCalendar UIDs
+func (r *taskRepository) GetByICalUIDs(userID uint, uids []string, tasks *[]models.Task) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	return r.db.Where("user_id = ? AND ical_uid IN ?", userID, uids).Find(tasks).Error
+}
+
+// SetAssignees replaces the assignees of a task and records who was assigned and unassigned,
+// in one transaction. New assignees start watching the task. The task's Assignees are
+// reloaded afterwards.
+func (r *taskRepository) SetAssignees(task *models.Task, userIDs []uint, changedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []models.TaskAssignee
+		if err := tx.Where("task_id = ?", task.ID).Find(&current).Error; err != nil {
+			return err
+		}
+
+		wanted := make(map[uint]bool, len(userIDs))
+		for _, id := range userIDs {
+			wanted[id] = true
+		}
+		var changes []models.TaskAssignmentChange
+		var added []models.TaskAssignee
+		for _, assignee := range current {
+			if wanted[assignee.UserID] {
+				delete(wanted, assignee.UserID)
+				continue
+			}
+			if err := tx.Delete(&assignee).Error; err != nil {
+				return err
+			}
+			changes = append(changes, models.TaskAssignmentChange{TaskID: task.ID, UserID: assignee.UserID, Action: models.AssignmentUnassigned, ChangedBy: changedBy})
+		}
+		for _, id := range userIDs {
+			if !wanted[id] {
+				continue
+			}
+			assignee := &models.TaskAssignee{TaskID: task.ID, UserID: id, AssignedBy: changedBy}
+			if err := tx.Create(assignee).Error; err != nil {
+				return err
+			}
+			added = append(added, *assignee)
+			changes = append(changes, models.TaskAssignmentChange{TaskID: task.ID, UserID: id, Action: models.AssignmentAssigned, ChangedBy: changedBy})
+		}
+		if len(changes) > 0 {
+			if err := tx.Create(&changes).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := addWatchers(tx, []models.Task{{ID: task.ID, Assignees: added}}); err != nil {
+			return err
+		}
+
+		task.Assignees = []models.TaskAssignee{}
+		return tx.Where("task_id = ?", task.ID).Order("created_at, id").Find(&task.Assignees).Error
+	})
+}
+
+// GetAssignmentHistory returns who was assigned to and unassigned from a task, oldest first
+func (r *taskRepository) GetAssignmentHistory(taskID uint) ([]models.TaskAssignmentChange, error) {
+	changes := []models.TaskAssignmentChange{}
+	err := r.db.Where("task_id = ?", taskID).Order("created_at, id").Find(&changes).Error
+	return changes, err
+}
+
+// Watch lets the user watch the task; watching it again changes nothing
+func (r *taskRepository) Watch(taskID, userID uint) error {
+	watcher := &models.TaskWatcher{TaskID: taskID, UserID: userID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(watcher).Error
+}
+
+// Unwatch stops the user from watching the task
+func (r *taskRepository) Unwatch(taskID, userID uint) error {
+	return r.db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error
+}
+
+// GetWatcherIDs returns the users who watch the task
+func (r *taskRepository) GetWatcherIDs(taskID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.TaskWatcher{}).Where("task_id = ?", taskID).Order("id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// SetBlockers replaces the tasks that block a task in one transaction and reloads the task's
+// BlockedBy
+func (r *taskRepository) SetBlockers(task *models.Task, blockerIDs []uint, changedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("task_id = ?", task.ID)
+		if len(blockerIDs) > 0 {
+			query = query.Where("blocker_id NOT IN ?", blockerIDs)
+		}
+		if err := query.Delete(&models.TaskBlocker{}).Error; err != nil {
+			return err
+		}
+		if len(blockerIDs) > 0 {
+			blockers := make([]models.TaskBlocker, 0, len(blockerIDs))
+			for _, id := range blockerIDs {
+				blockers = append(blockers, models.TaskBlocker{TaskID: task.ID, BlockerID: id, CreatedBy: changedBy})
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blockers).Error; err != nil {
+				return err
+			}
+		}
+
+		task.BlockedBy = []models.TaskBlocker{}
+		return tx.Where("task_id = ?", task.ID).Order("created_at, id").Find(&task.BlockedBy).Error
+	})
+}
+
+// GetBlockerIDs returns the tasks that block any of the given tasks
+func (r *taskRepository) GetBlockerIDs(taskIDs []uint) ([]uint, error) {
+	var blockerIDs []uint
+	if len(taskIDs) == 0 {
+		return blockerIDs, nil
+	}
+	err := r.db.Model(&models.TaskBlocker{}).Where("task_id IN ?", taskIDs).Distinct().Pluck("blocker_id", &blockerIDs).Error
+	return blockerIDs, err
+}
+
+// GetNextCandidates retrieves the open tasks the user has to do: those assigned to the user
+// and those the user created without assigning them to anyone
+func (r *taskRepository) GetNextCandidates(userID uint) ([]models.Task, error) {
+	var tasks []models.Task
+	unassigned := r.db.Model(&models.TaskAssignee{}).Select("task_id")
+	err := r.db.Preload("Tags").Preload("Assignees").Preload("BlockedBy").
+		Where("status_category <> ?", models.StatusCategoryDone).
+		Where(r.db.Where("id IN (?)", r.assignedTo(userID)).Or("user_id = ? AND id NOT IN (?)", userID, unassigned)).
+		Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// CountOpenBlockers counts, for each of the given tasks, the open tasks blocking it and the
+// open tasks it blocks
+func (r *taskRepository) CountOpenBlockers(taskIDs []uint) (map[uint]int, map[uint]int, error) {
+	blockedBy, blocks := map[uint]int{}, map[uint]int{}
+	if len(taskIDs) == 0 {
+		return blockedBy, blocks, nil
+	}
+	type count struct {
+		TaskID uint
+		Count  int
+	}
+	open := r.db.Model(&models.Task{}).Select("id").Where("status_category <> ?", models.StatusCategoryDone)
+
+	var counts []count
+	err := r.db.Model(&models.TaskBlocker{}).Select("task_id, COUNT(*) AS count").
+		Where("task_id IN ? AND blocker_id IN (?)", taskIDs, open).
+		Group("task_id").Scan(&counts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range counts {
+		blockedBy[c.TaskID] = c.Count
+	}
+
+	counts = nil
+	err = r.db.Model(&models.TaskBlocker{}).Select("blocker_id AS task_id, COUNT(*) AS count").
+		Where("blocker_id IN ? AND task_id IN (?)", taskIDs, open).
+		Group("blocker_id").Scan(&counts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range counts {
+		blocks[c.TaskID] = c.Count
+	}
+	return blockedBy, blocks, nil
+}
+
+// GetAdjacentRank returns the rank that follows (next) or precedes the given rank in the
+// list of tasks with the project and status, ignoring one task. An empty rank stands for
+// the end of the list. It returns an empty rank if there is none.
+func (r *taskRepository) GetAdjacentRank(projectID *uint, status, rank string, next bool, excludeID uint) (string, error) {
+	query := inRankList(r.db.Model(&models.Task{}), projectID, status).Where("id <> ?", excludeID)
+	switch {
+	case next:
+		query = query.Where(models.RankOrder+" > ?", rank).Order(models.RankOrder + " ASC")
+	case rank != "":
+		query = query.Where(models.RankOrder+" < ?", rank).Order(models.RankOrder + " DESC")
+	default:
+		query = query.Order(models.RankOrder + " DESC")
+	}
+	var ranks []string
+	err := query.Limit(1).Pluck("rank", &ranks).Error
+	if err != nil || len(ranks) == 0 {
+		return "", err
+	}
+	return ranks[0], nil
+}
+
+// UpdateRank moves a task to a position, and possibly to another status of the given
+// category, changing only its row
+func (r *taskRepository) UpdateRank(taskID uint, rank, status, category string) error {
+	return r.db.Model(&models.Task{}).Where("id = ?", taskID).
+		Updates(map[string]interface{}{"rank": rank, "status": status, "status_category": category}).Error
+}
+
+// RebalanceRanks spreads the ranks of the list of tasks with the project and status evenly,
+// keeping their order
+func (r *taskRepository) RebalanceRanks(projectID *uint, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return rebalanceList(inRankList(tx.Model(&models.Task{}), projectID, status))
+	})
+}
+
+// RebalanceLongRanks rebalances every list with a rank of MaxRankLength or more digits, or
+// with unranked tasks, and returns how many lists it rebalanced
+func (r *taskRepository) RebalanceLongRanks() (int, error) {
+	var lists []struct {
+		OrganizationID uint
+		ProjectID      *uint
+		Status         string
+	}
+	err := r.db.Model(&models.Task{}).Distinct("organization_id", "project_id", "status").
+		Where("rank = '' OR LENGTH(rank) >= ?", models.MaxRankLength).Scan(&lists).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, list := range lists {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			return rebalanceList(inRankList(tx.Model(&models.Task{}), list.ProjectID, list.Status).
+				Where("organization_id = ?", list.OrganizationID))
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(lists), nil
+}
+
+// GetByProject retrieves all tasks of a project with their tags, assignees and blockers,
+// ordered by status and rank
+func (r *taskRepository) GetByProject(projectID uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Preload("Tags").Preload("Assignees").Preload("BlockedBy").
+		Where("project_id = ?", projectID).
+		Order("status, " + models.RankOrder + ", id").Find(&tasks).Error
+	return tasks, err
+}
+
+// CountInList counts the tasks of the list with the project and status, ignoring one task
+func (r *taskRepository) CountInList(projectID *uint, status string, excludeID uint) (int64, error) {
+	var count int64
+	err := inRankList(r.db.Model(&models.Task{}), projectID, status).Where("id <> ?", excludeID).Count(&count).Error
+	return count, err
+}
+
+// LockLists runs fn in a transaction that holds a lock on the project row, so counts of the
+// project's lists read in fn stay true until fn's writes are committed
+func (r *taskRepository) LockLists(projectID uint, fn func(repo TaskRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&models.Project{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", projectID).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		return fn(&taskRepository{db: tx})
+	})
+}
+
+// GetDueForReminder retrieves the open tasks due after from and up to to that were not
+// reminded about yet, with their assignees
+func (r *taskRepository) GetDueForReminder(from, to time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Preload("Assignees").
+		Where("status_category <> ? AND reminded_at IS NULL AND due_date > ? AND due_date <= ?", models.StatusCategoryDone, from, to).
+		Order("due_date, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// MarkReminded records that reminders for the tasks were sent
+func (r *taskRepository) MarkReminded(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Task{}).Where("id IN ?", ids).UpdateColumn("reminded_at", at).Error
+}
+
+// visibleTo selects the tasks the user created, is assigned to or watches
+func (r *taskRepository) visibleTo(userID uint) *gorm.DB {
+	return r.db.Where("user_id = ?", userID).
+		Or("id IN (?)", r.assignedTo(userID)).
+		Or("id IN (?)", r.watchedBy(userID))
+}
+
+// assignedTo selects the IDs of the tasks assigned to the user
+func (r *taskRepository) assignedTo(userID uint) *gorm.DB {
+	return r.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userID)
+}
+
+// watchedBy selects the IDs of the tasks the user watches
+func (r *taskRepository) watchedBy(userID uint) *gorm.DB {
+	return r.db.Model(&models.TaskWatcher{}).Select("task_id").Where("user_id = ?", userID)
+}
+
+// inRankList restricts a query to the list of tasks with the project and status
+func inRankList(query *gorm.DB, projectID *uint, status string) *gorm.DB {
+	if projectID == nil {
+		return query.Where("project_id IS NULL AND status = ?", status)
+	}
+	return query.Where("project_id = ? AND status = ?", *projectID, status)
+}
+
+// rebalanceList gives the tasks of a list evenly spread ranks in their current order, with
+// unranked tasks last
+func rebalanceList(list *gorm.DB) error {
+	var tasks []models.Task
+	err := list.Select("id", "rank").
+		Order("rank = '', " + models.RankOrder + ", id").Find(&tasks).Error
+	if err != nil {
+		return err
+	}
+	for i, rank := range models.SpreadRanks(len(tasks)) {
+		if tasks[i].Rank == rank {
+			continue
+		}
+		err := list.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).
+			Where("id = ?", tasks[i].ID).UpdateColumn("rank", rank).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ranker gives new tasks ranks at the end of their lists
+type ranker struct {
+	tx   *gorm.DB
+	last map[string]string // Last rank of each list seen so far
+}
+
+func newRanker(tx *gorm.DB) *ranker {
+	return &ranker{tx: tx, last: map[string]string{}}
+}
+
+// rankLast places the task at the end of its list unless it already has a rank
+func (k *ranker) rankLast(task *models.Task) error {
+	if task.Rank != "" {
+		return nil
+	}
+	status := task.Status
+	if status == "" {
+		status = models.StatusPending
+	}
+	key := status
+	if task.ProjectID != nil {
+		key = strconv.FormatUint(uint64(*task.ProjectID), 10) + "/" + status
+	}
+
+	last, ok := k.last[key]
+	if !ok {
+		var ranks []string
+		err := inRankList(k.tx.Model(&models.Task{}), task.ProjectID, status).
+			Order(models.RankOrder+" DESC").Limit(1).Pluck("rank", &ranks).Error
+		if err != nil {
+			return err
+		}
+		if len(ranks) > 0 {
+			last = ranks[0]
+		}
+	}
+	rank, ok := models.RankBetween(last, "")
+	if !ok {
+		rank, _ = models.RankBetween("", "") // The list has invalid ranks; the rebalancer fixes them
+	}
+	task.Rank = rank
+	k.last[key] = rank
+	return nil
+}
+
+// addWatchers lets the creators and assignees of tasks watch them, keeping existing watchers
+func addWatchers(tx *gorm.DB, tasks []models.Task) error {
+	var watchers []models.TaskWatcher
+	for _, task := range tasks {
+		if task.UserID != 0 {
+			watchers = append(watchers, models.TaskWatcher{TaskID: task.ID, UserID: task.UserID})
+		}
+		for _, assignee := range task.Assignees {
+			watchers = append(watchers, models.TaskWatcher{TaskID: task.ID, UserID: assignee.UserID})
+		}
+	}
+	if len(watchers) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&watchers).Error
+}
+
+// recordAssignments adds the initial assignees of new tasks to the assignment history
+func recordAssignments(tx *gorm.DB, tasks []models.Task) error {
+	var changes []models.TaskAssignmentChange
+	for _, task := range tasks {
+		for _, assignee := range task.Assignees {
+			changes = append(changes, models.TaskAssignmentChange{TaskID: task.ID, UserID: assignee.UserID, Action: models.AssignmentAssigned, ChangedBy: assignee.AssignedBy})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Create(&changes).Error
+}
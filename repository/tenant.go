@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrNoOrganization is returned for statements on organization data from a session that is
+// bound neither to an organization nor to all organizations
+var ErrNoOrganization = errors.New("organization data accessed without an organization")
+
+// ErrOrganizationMismatch is returned when a new row names another organization than the session
+var ErrOrganizationMismatch = errors.New("row belongs to another organization")
+
+// tenantKey is the context key of the organization a session is bound to
+type tenantKey struct{}
+
+// tenant is the organization a session is bound to, or all of them
+type tenant struct {
+	organizationID uint
+	all            bool
+}
+
+// ForOrganization returns a session of db whose statements on organization data (models that
+// implement models.OrganizationScoped) are restricted to the organization. New rows are
+// assigned to it.
+func ForOrganization(db *gorm.DB, organizationID uint) *gorm.DB {
+	return bindTenant(db, tenant{organizationID: organizationID})
+}
+
+// AllOrganizations returns a session of db that reads and writes the data of every
+// organization, for system-wide work such as statistics and personal data requests.
+func AllOrganizations(db *gorm.DB) *gorm.DB {
+	return bindTenant(db, tenant{all: true})
+}
+
+// sameOrganization binds a transaction to the organization of db, so repositories keep
+// their organization in WithTx
+func sameOrganization(tx, db *gorm.DB) *gorm.DB {
+	if t, ok := tenantOf(db); ok {
+		return bindTenant(tx, t)
+	}
+	return tx
+}
+
+func bindTenant(db *gorm.DB, t tenant) *gorm.DB {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, tenantKey{}, t))
+}
+
+func tenantOf(db *gorm.DB) (tenant, bool) {
+	if db.Statement.Context == nil {
+		return tenant{}, false
+	}
+	t, ok := db.Statement.Context.Value(tenantKey{}).(tenant)
+	return t, ok
+}
+
+// TenantScope is a GORM plugin that restricts every query, update and delete on organization
+// data to the organization the session is bound to with ForOrganization, and assigns new rows
+// to it. This is the one place where tenants are separated: repositories do not filter by
+// organization themselves, and statements from unbound sessions fail with ErrNoOrganization.
+//
+// With RowLevelSecurity, statements on organization data also run in a transaction that sets
+// app.organization_id, which the Postgres policies created by the migrations check.
+type TenantScope struct {
+	RowLevelSecurity bool
+}
+
+// Name implements gorm.Plugin
+func (TenantScope) Name() string {
+	return "tenant_scope"
+}
+
+// Initialize implements gorm.Plugin by registering the callbacks
+func (p TenantScope) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("tenant:assign", assignOrganization); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tenant:scope", scopeStatement); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("tenant:scope", scopeStatement); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenant:scope", scopeStatement); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenant:scope", scopeStatement); err != nil {
+		return err
+	}
+	if !p.RowLevelSecurity {
+		return nil
+	}
+
+	// The setting only lasts for a transaction; writes already run in one, queries get one.
+	// Row queries (Scan, Rows) are read after the callbacks and are only scoped by the WHERE.
+	if err := callback.Query().After("tenant:scope").Before("gorm:query").Register("tenant:begin_transaction", beginTenantTransaction); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:after_query").Register("tenant:commit_or_rollback_transaction", callbacks.CommitOrRollbackTransaction); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:begin_transaction").Register("tenant:set_organization", setOrganizationSetting); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:begin_transaction").Register("tenant:set_organization", setOrganizationSetting); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:begin_transaction").Register("tenant:set_organization", setOrganizationSetting)
+}
+
+// scopedSchemas caches whether a schema belongs to a models.OrganizationScoped model
+var scopedSchemas sync.Map
+
+// isOrganizationScoped reports whether the statement works on organization data
+func isOrganizationScoped(db *gorm.DB) bool {
+	s := db.Statement.Schema
+	if s == nil {
+		return false
+	}
+	if scoped, ok := scopedSchemas.Load(s); ok {
+		return scoped.(bool)
+	}
+	_, scoped := reflect.New(s.ModelType).Interface().(models.OrganizationScoped)
+	scoped = scoped && s.LookUpField("OrganizationID") != nil
+	scopedSchemas.Store(s, scoped)
+	return scoped
+}
+
+// scopeStatement adds the organization condition to queries, updates and deletes
+func scopeStatement(db *gorm.DB) {
+	if db.Error != nil || !isOrganizationScoped(db) {
+		return
+	}
+	t, ok := tenantOf(db)
+	if !ok || (!t.all && t.organizationID == 0) {
+		db.AddError(ErrNoOrganization)
+		return
+	}
+	if t.all {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "organization_id"}, Value: t.organizationID},
+	}})
+}
+
+// assignOrganization sets the organization of new rows
+func assignOrganization(db *gorm.DB) {
+	if db.Error != nil || !isOrganizationScoped(db) {
+		return
+	}
+	t, ok := tenantOf(db)
+	if !ok || (!t.all && t.organizationID == 0) {
+		db.AddError(ErrNoOrganization)
+		return
+	}
+	if t.all {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField("OrganizationID")
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setRowOrganization(db, field, reflect.Indirect(rv.Index(i)), t.organizationID)
+		}
+	case reflect.Struct:
+		setRowOrganization(db, field, rv, t.organizationID)
+	}
+}
+
+func setRowOrganization(db *gorm.DB, field *schema.Field, row reflect.Value, organizationID uint) {
+	ctx := db.Statement.Context
+	if value, zero := field.ValueOf(ctx, row); !zero && value != organizationID {
+		db.AddError(ErrOrganizationMismatch)
+		return
+	}
+	if err := field.Set(ctx, row, organizationID); err != nil {
+		db.AddError(err)
+	}
+}
+
+// beginTenantTransaction runs a query on organization data in a transaction, so the
+// organization setting applies to it
+func beginTenantTransaction(db *gorm.DB) {
+	if t, ok := tenantOf(db); !ok || t.all || db.Error != nil || db.DryRun || !isOrganizationScoped(db) {
+		return
+	}
+	callbacks.BeginTransaction(db)
+	setOrganizationSetting(db)
+}
+
+// setOrganizationSetting sets app.organization_id for the rest of the current transaction
+func setOrganizationSetting(db *gorm.DB) {
+	t, ok := tenantOf(db)
+	if !ok || t.all || db.Error != nil || db.DryRun || !isOrganizationScoped(db) {
+		return
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); !inTransaction {
+		return
+	}
+	_, err := db.Statement.ConnPool.ExecContext(db.Statement.Context,
+		"SELECT set_config('app.organization_id', $1, true)", strconv.FormatUint(uint64(t.organizationID), 10))
+	if err != nil {
+		db.AddError(err)
+	}
+}
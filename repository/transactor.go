@@ -0,0 +1,23 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Transactor runs work that spans several repositories in one database transaction.
+// Repositories are bound to the transaction with their WithTx methods.
+type Transactor interface {
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor initializes a new instance of Transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// Transaction commits if fn returns nil and rolls back otherwise
+func (t *transactor) Transaction(fn func(tx *gorm.DB) error) error {
+	return t.db.Transaction(fn)
+}
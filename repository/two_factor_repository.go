@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository defines the interface for TOTP settings and recovery codes
+type TwoFactorRepository interface {
+	SetPendingSecret(userID uint, secret string) error
+	Enable(userID uint, counter int64, codeHashes []string, at time.Time) error
+	Disable(userID uint) error
+	UseCounter(userID uint, counter int64) (bool, error)
+	UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository initializes a new instance of TwoFactorRepository
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// SetPendingSecret stores a new secret that is not used for logins until it is confirmed
+func (r *twoFactorRepository) SetPendingSecret(userID uint, secret string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":       secret,
+		"totp_enabled_at":   nil,
+		"totp_last_counter": 0,
+	}).Error
+}
+
+// Enable turns on two-factor authentication and replaces the user's recovery codes
+func (r *twoFactorRepository) Enable(userID uint, counter int64, codeHashes []string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled_at":   at,
+			"totp_last_counter": counter,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Disable turns off two-factor authentication and deletes the recovery codes
+func (r *twoFactorRepository) Disable(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":       "",
+			"totp_enabled_at":   nil,
+			"totp_last_counter": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseCounter records the time step of an accepted TOTP code. It reports false if
+// the same or a later code was already used, so codes cannot be replayed.
+func (r *twoFactorRepository) UseCounter(userID uint, counter int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_counter < ?", userID, counter).
+		Update("totp_last_counter", counter)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode marks an unused recovery code as used and reports whether it existed
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/EmelinDanila/task-manager-api/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -8,8 +10,15 @@ import (
 
 // UserRepository defines the interface for user-related database operations
 type UserRepository interface {
-	FindByEmail(email string) (*models.User, error) // Find a user by email
-	CreateUser(user *models.User) error             // Create a new user
+	FindByEmail(email string) (*models.User, error)                           // Find a user by email
+	CreateUser(user *models.User) error                                       // Create a new user
+	FindByID(id uint) (*models.User, error)                                   // Find a user by ID
+	SetDefaultView(userID uint, viewID *uint) error                           // Set or clear the user's default saved view
+	MarkVerified(userID uint, at time.Time) error                             // Record that the user's email was verified
+	SetVerificationSentAt(userID uint, at time.Time) error                    // Record when a verification email was sent
+	UpdatePassword(userID uint, hash string, at time.Time) error              // Store a new password hash and revoke sessions
+	SetPendingEmail(userID uint, email string) error                          // Remember an email address waiting for verification
+	ConfirmEmailChange(userID uint, email string, at time.Time) (bool, error) // Replace the email with the verified pending one
 }
 
 // userRepository implements the UserRepository interface
@@ -38,7 +47,10 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// CreateUser adds a new user to the database
+// PersonalOrganizationName is the name of the organization every new user owns
+const PersonalOrganizationName = "Personal"
+
+// CreateUser adds a new user to the database with a personal organization
 func (r *userRepository) CreateUser(user *models.User) error {
 	// Hash the password before saving
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -48,6 +60,65 @@ func (r *userRepository) CreateUser(user *models.User) error {
 	// Replace the plain text password with the hashed version
 	user.Password = string(hashedPassword)
 
-	// Insert the new user into the database
-	return r.db.Create(user).Error
+	// Insert the new user together with their personal organization
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return createOrganization(tx, &models.Organization{Name: PersonalOrganizationName}, user.ID)
+	})
+}
+
+// FindByID searches for a user by ID, returning nil if the user does not exist
+func (r *userRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetDefaultView stores the saved view applied to the user's task list by default
+func (r *userRepository) SetDefaultView(userID uint, viewID *uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("default_view_id", viewID).Error
+}
+
+// MarkVerified records that the user's email address was verified
+func (r *userRepository) MarkVerified(userID uint, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ? AND verified_at IS NULL", userID).Update("verified_at", at).Error
+}
+
+// SetVerificationSentAt records when the last verification email was sent, for throttling
+func (r *userRepository) SetVerificationSentAt(userID uint, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("verification_sent_at", at).Error
+}
+
+// UpdatePassword stores a new password hash and revokes the user's sessions
+func (r *userRepository) UpdatePassword(userID uint, hash string, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password":            hash,
+		"sessions_revoked_at": at,
+		"must_reset_password": false,
+	}).Error
+}
+
+// SetPendingEmail remembers the address the user wants to switch to until it is verified
+func (r *userRepository) SetPendingEmail(userID uint, email string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("pending_email", email).Error
+}
+
+// ConfirmEmailChange replaces the user's email with the pending address and marks it verified.
+// It reports false if the pending address has changed in the meantime.
+func (r *userRepository) ConfirmEmailChange(userID uint, email string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND pending_email = ?", userID, email).
+		Updates(map[string]interface{}{
+			"email":         email,
+			"pending_email": "",
+			"verified_at":   at,
+		})
+	return result.RowsAffected > 0, result.Error
 }
@@ -1,9 +1,13 @@
 package routes
 
 import (
+	"log"
+	"time"
+
 	"github.com/EmelinDanila/task-manager-api/controllers"
 	"github.com/EmelinDanila/task-manager-api/docs"
 	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/EmelinDanila/task-manager-api/repository"
 	"github.com/EmelinDanila/task-manager-api/services"
 	swaggerFiles "github.com/swaggo/files"
@@ -13,7 +17,26 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(router *gin.Engine, db *gorm.DB) {
+// Jobs are the background jobs of the services wired by SetupRoutes
+type Jobs struct {
+	reminders services.ReminderService
+	ranks     services.RankRebalancer
+	digests   services.DigestService
+	erasures  services.PersonalDataService
+}
+
+// Start runs the background jobs: due date reminders, digest emails and requested erasures
+// every minute, and respacing long task ranks every hour
+func (j *Jobs) Start() {
+	j.reminders.Start(time.Minute)
+	j.ranks.Start(time.Hour)
+	j.digests.Start(time.Minute)
+	j.erasures.Start(time.Minute)
+}
+
+// SetupRoutes registers all routes on the router. The returned jobs are not running yet;
+// the server starts them with Start.
+func SetupRoutes(router *gin.Engine, db *gorm.DB) *Jobs {
 	// Swagger documentation
 	docs.SwaggerInfo.Title = "Task Manager API"
 	docs.SwaggerInfo.Description = "This is a task manager API."
@@ -24,46 +47,268 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Emails go through SMTP when it is configured
+	mailer := services.NewMailerFromEnv()
+	if mailer == nil {
+		log.Println("SMTP_HOST is not set, emails will not be delivered")
+		mailer = services.NewMemoryMailer()
+	}
+
+	// Password rules for registration and password reset
+	passwordPolicy, err := services.NewPasswordPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid password policy: %v", err)
+	}
+
 	// Auth service and controller setup
 	authService := services.NewAuthService()
 	userRepo := repository.NewUserRepository(db)
-	authController := controllers.NewAuthController(authService, userRepo)
+	verificationService := services.NewEmailVerificationService(userRepo, mailer)
+	loginAttempts := repository.NewLoginAttemptRepository(db)
+	twoFactorService := services.NewTwoFactorService(userRepo, repository.NewTwoFactorRepository(db), loginAttempts)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	invitationService := services.NewInvitationService(repository.NewTransactor(db), repository.NewInvitationRepository(db), organizationRepo, userRepo, mailer)
+	authController := controllers.NewAuthController(authService, userRepo, verificationService, twoFactorService, passwordPolicy, invitationService)
+
+	// Failed logins lock the account and the client IP for a while
+	loginThrottle := services.NewLoginThrottleService(loginAttempts, services.DefaultLoginThrottleConfig())
 
 	// Auth routes
 	router.POST("/register", authController.RegisterUser)
-	router.POST("/login", authController.LoginUser)
+	router.POST("/login", middleware.LoginThrottle(loginThrottle, middleware.EmailAccount), authController.LoginUser)
+	router.POST("/login/2fa", middleware.LoginThrottle(loginThrottle, middleware.MFATokenAccount(twoFactorService)), authController.LoginTwoFactor)
+
+	// Email verification
+	verificationController := controllers.NewEmailVerificationController(verificationService)
+	router.GET("/email/verify", verificationController.VerifyEmail)
+	router.POST("/email/verify/resend", verificationController.ResendVerification)
+
+	// Password reset
+	passwordResetService := services.NewPasswordResetService(userRepo, repository.NewPasswordResetRepository(db), mailer, passwordPolicy)
+	passwordController := controllers.NewPasswordController(passwordResetService)
+	router.POST("/password/forgot", passwordController.ForgotPassword)
+	router.POST("/password/reset", passwordController.ResetPassword)
+
+	// Task events feed the notification inbox and due date reminders
+	events := services.NewEventBus()
+	notificationService := services.NewNotificationService(repository.NewNotificationRepository(db), userRepo, organizationRepo)
+	notificationService.Subscribe(events)
+
+	// Shared repositories and services. They work on organization data only once bound to an
+	// organization with ForOrganization; system-wide work uses the all-organizations variants.
+	taskRepo := repository.NewTaskRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	taskService := services.NewTaskService(taskRepo, projectRepo, organizationRepo, events)
+	allTaskRepo := repository.NewTaskRepository(repository.AllOrganizations(db))
+	allTaskService := services.NewTaskService(allTaskRepo, repository.NewProjectRepository(repository.AllOrganizations(db)), organizationRepo, nil)
+	organizationService := services.NewOrganizationService(organizationRepo, userRepo)
+
+	// Digest emails, sent when due and cancelled by the signed link in each email
+	digestService := services.NewDigestService(repository.NewDigestRepository(db), userRepo, mailer)
+	digestController := controllers.NewDigestController(digestService)
+	router.GET("/digest/unsubscribe", digestController.Unsubscribe)
+	router.POST("/digest/unsubscribe", digestController.Unsubscribe)
+
+	// Invitation links open without signing in
+	invitationController := controllers.NewInvitationController(invitationService)
+	router.GET("/invitations/accept", invitationController.Show)
+
+	// Calendar feed, authenticated by the secret token in the URL
+	calendarService := services.NewCalendarService(repository.NewTransactor(db), repository.NewCalendarFeedRepository(db), taskRepo, userRepo, taskService)
+	calendarController := controllers.NewCalendarController(calendarService)
+	router.GET("/calendar/feed/:token", calendarController.GetFeed)
+
+	// The signed-in user's personal data; requested erasures run in the background
+	personalDataService := services.NewPersonalDataService(repository.NewPersonalDataRepository(db), services.NewTaskTransferService(allTaskRepo, allTaskService), loginThrottle)
+
+	// API keys are accepted alongside JWTs
+	apiKeyService := services.NewAPIKeyService(repository.NewAPIKeyRepository(db))
 
 	// Protected group for authenticated routes
 	protected := router.Group("/")
-	protected.Use(middleware.AuthMiddleware(authService))
+	protected.Use(middleware.AuthMiddleware(authService, apiKeyService), middleware.ActiveSession(userRepo))
 	{
-		// Profile route
-		protected.GET("/profile", func(c *gin.Context) {
-			userID, exists := middleware.GetUserID(c)
-			if !exists {
-				c.JSON(401, gin.H{"error": "Unauthorized"})
-				return
-			}
-			c.JSON(200, gin.H{"message": "Welcome!", "userID": userID})
-		})
-
-		// Task routes
-		taskRepo := repository.NewTaskRepository(db)
-		taskService := services.NewTaskService(taskRepo)
-		taskController := controllers.TaskController{Service: taskService}
+		// The signed-in user's account
+		accountService := services.NewAccountService(userRepo, verificationService, passwordPolicy, personalDataService)
+		accountController := controllers.NewAccountController(accountService, personalDataService)
+		protected.GET("/me", accountController.GetProfile)
+
+		// Route groups by required scope
+		reader := protected.Group("/", middleware.RequireScopes(models.ScopeTasksRead))
+		writer := protected.Group("/", middleware.RequireScopes(models.ScopeTasksWrite))
+		if verificationService.Policy() == services.VerificationWrites {
+			writer.Use(middleware.RequireVerifiedEmail())
+		}
+		admin := protected.Group("/", middleware.RequireScopes(models.ScopeAdmin))
+
+		// Tasks, projects, views and imports belong to the active organization
+		activeOrganization := middleware.ActiveOrganization(organizationService)
+		orgReader := reader.Group("/", activeOrganization)
+		orgWriter := writer.Group("/", activeOrganization)
+		orgAdmin := admin.Group("/", activeOrganization)
+
+		// Organizations and their members
+		organizationController := controllers.NewOrganizationController(organizationService, authService)
+		reader.GET("/organizations", organizationController.GetOrganizations)
+		reader.GET("/organizations/:id", organizationController.GetOrganization)
+		reader.POST("/organizations/:id/token", organizationController.IssueToken)
+		admin.POST("/organizations", organizationController.CreateOrganization)
+		admin.PUT("/organizations/:id", organizationController.RenameOrganization)
+		admin.POST("/organizations/:id/members", organizationController.AddMember)
+		admin.PUT("/organizations/:id/members/:userId", organizationController.ChangeMemberRole)
+		admin.DELETE("/organizations/:id/members/:userId", organizationController.RemoveMember)
+
+		// Invitations to organizations
+		// Accepting only needs a signed-in user; whether they may join is up to the token
+		protected.POST("/invitations/accept", invitationController.Accept)
+		admin.POST("/organizations/:id/invitations", invitationController.Invite)
+		admin.GET("/organizations/:id/invitations", invitationController.ListPending)
+		admin.DELETE("/organizations/:id/invitations/:invitationId", invitationController.Revoke)
+		admin.POST("/organizations/:id/invitations/:invitationId/resend", invitationController.Resend)
+
+		// Account changes need the admin scope, like other security settings, and a login token:
+		// API keys cannot take over or delete the account
+		account := admin.Group("/", middleware.RequireLoginToken())
+		account.PUT("/me/password", accountController.ChangePassword)
+		account.PUT("/me/email", accountController.ChangeEmail)
+		account.DELETE("/me", accountController.DeleteAccount)
+		account.GET("/me/export", accountController.ExportData)
+
+		// API key management
+		apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+		admin.POST("/api-keys", apiKeyController.CreateKey)
+		admin.GET("/api-keys", apiKeyController.ListKeys)
+		admin.DELETE("/api-keys/:id", apiKeyController.RevokeKey)
+
+		// Two-factor authentication settings
+		twoFactorController := controllers.NewTwoFactorController(twoFactorService)
+		admin.POST("/2fa/enroll", twoFactorController.Enroll)
+		admin.POST("/2fa/confirm", twoFactorController.Confirm)
+		admin.POST("/2fa/disable", twoFactorController.Disable)
+
+		// Saved view service, also provides the default view for GET /tasks
+		viewRepo := repository.NewSavedViewRepository(db)
+		viewService := services.NewSavedViewService(viewRepo, projectRepo, userRepo, taskService)
+
+		taskController := controllers.TaskController{Service: taskService, Views: viewService}
 		// Create a task
-		protected.POST("/tasks", taskController.CreateTask)
+		orgWriter.POST("/tasks", taskController.CreateTask)
 
 		// Get all tasks
-		protected.GET("/tasks", taskController.GetAllTasks)
+		orgReader.GET("/tasks", taskController.GetAllTasks)
+
+		// Ranked list of what to do next
+		orgReader.GET("/tasks/next", taskController.GetNextTasks)
+
+		// Export and import tasks
+		transferController := controllers.NewTaskTransferController(services.NewTaskTransferService(taskRepo, taskService))
+		orgReader.GET("/tasks/export", transferController.ExportTasks)
+		orgWriter.POST("/tasks/import", transferController.ImportTasks)
+		orgWriter.POST("/tasks/import/ics", calendarController.ImportICS)
+
+		// Imports from other task trackers
+		importService := services.NewImportService(repository.NewTransactor(db), projectRepo, repository.NewTagRepository(db), taskRepo, taskService)
+		importController := controllers.NewImportController(importService)
+		orgWriter.POST("/import/:source", importController.Import)
+
+		// Calendar feed management
+		orgAdmin.POST("/calendar/feed", calendarController.CreateFeed)
+		orgAdmin.DELETE("/calendar/feed", calendarController.RevokeFeed)
 
 		// Get task by ID
-		protected.GET("/tasks/:id", taskController.GetTaskByID)
+		orgReader.GET("/tasks/:id", taskController.GetTaskByID)
 
 		// Update task
-		protected.PUT("/tasks/:id", taskController.UpdateTask)
+		orgWriter.PUT("/tasks/:id", taskController.UpdateTask)
 
 		// Delete task
-		protected.DELETE("/tasks/:id", taskController.DeleteTask)
+		orgWriter.DELETE("/tasks/:id", taskController.DeleteTask)
+
+		// Task assignment
+		orgWriter.PUT("/tasks/:id/assignees", taskController.SetAssignees)
+		orgReader.GET("/tasks/:id/assignments", taskController.GetAssignmentHistory)
+		orgReader.GET("/me/assigned", taskController.GetAssignedTasks)
+
+		// Watching tasks
+		orgWriter.POST("/tasks/:id/watch", taskController.WatchTask)
+		orgWriter.DELETE("/tasks/:id/watch", taskController.UnwatchTask)
+		orgReader.GET("/me/watching", taskController.GetWatchingTasks)
+
+		// Blockers of a task
+		orgWriter.PUT("/tasks/:id/blockers", taskController.SetBlockers)
+
+		// Manual ordering
+		orgWriter.POST("/tasks/:id/move", taskController.MoveTask)
+
+		// Notification inbox, across all of the user's organizations
+		notificationController := controllers.NewNotificationController(notificationService)
+		reader.GET("/notifications", notificationController.List)
+		reader.GET("/notifications/unread-count", notificationController.UnreadCount)
+		reader.GET("/notifications/preferences", notificationController.GetPreferences)
+		writer.POST("/notifications/:id/read", notificationController.MarkRead)
+		writer.POST("/notifications/read-all", notificationController.MarkAllRead)
+		writer.PUT("/notifications/preferences", notificationController.UpdatePreferences)
+		reader.GET("/me/digest", digestController.GetSettings)
+		writer.PUT("/me/digest", digestController.UpdateSettings)
+
+		// Project routes
+		projectService := services.NewProjectService(projectRepo, userRepo, organizationRepo)
+		projectController := controllers.NewProjectController(projectService)
+		orgWriter.POST("/projects", projectController.CreateProject)
+		orgReader.GET("/projects", projectController.GetProjects)
+		orgReader.GET("/projects/:id", projectController.GetProject)
+		orgWriter.POST("/projects/:id/members", projectController.AddMember)
+		orgWriter.DELETE("/projects/:id/members/:userId", projectController.RemoveMember)
+
+		// Board routes
+		boardController := controllers.NewBoardController(services.NewBoardService(projectRepo, taskRepo))
+		orgReader.GET("/projects/:id/board", boardController.GetBoard)
+		orgWriter.PUT("/projects/:id/board", boardController.SetColumns)
+
+		// Workflow routes
+		workflowController := controllers.NewWorkflowController(services.NewWorkflowService(repository.NewTransactor(db), projectRepo))
+		orgReader.GET("/projects/:id/workflow", workflowController.GetWorkflow)
+		orgWriter.PUT("/projects/:id/workflow", workflowController.SetWorkflow)
+
+		// Custom field routes
+		customFieldController := controllers.NewCustomFieldController(services.NewCustomFieldService(projectRepo))
+		orgReader.GET("/projects/:id/fields", customFieldController.GetFields)
+		orgWriter.PUT("/projects/:id/fields", customFieldController.SetFields)
+
+		// Saved view routes
+		viewController := controllers.NewSavedViewController(viewService)
+		orgWriter.POST("/views", viewController.CreateView)
+		orgReader.GET("/views", viewController.GetViews)
+		orgReader.GET("/views/:id", viewController.GetView)
+		orgWriter.PUT("/views/:id", viewController.UpdateView)
+		orgWriter.DELETE("/views/:id", viewController.DeleteView)
+		orgReader.GET("/views/:id/tasks", viewController.GetViewTasks)
+		orgWriter.POST("/views/:id/default", viewController.SetDefaultView)
+		orgWriter.DELETE("/views/default", viewController.ClearDefaultView)
+
+		// Administrator routes; every request to them is audited, including rejected ones
+		adminService := services.NewAdminService(userRepo, repository.NewAdminRepository(db), allTaskService, passwordResetService, loginThrottle)
+		auditService := services.NewAuditService(repository.NewAuditRepository(db))
+		adminController := controllers.NewAdminController(adminService, personalDataService, auditService)
+		administration := admin.Group("/admin", middleware.AuditAdmin(auditService), middleware.RequireAdmin())
+		administration.GET("/users", adminController.ListUsers)
+		administration.GET("/users/:id", adminController.GetUser)
+		administration.POST("/users/:id/disable", adminController.DisableUser)
+		administration.POST("/users/:id/enable", adminController.EnableUser)
+		administration.PUT("/users/:id/role", adminController.SetRole)
+		administration.POST("/users/:id/password-reset", adminController.ForcePasswordReset)
+		administration.GET("/users/:id/tasks", adminController.ListUserTasks)
+		administration.POST("/users/unlock", adminController.UnlockUser)
+		administration.POST("/users/:id/erase", adminController.EraseUser)
+		administration.GET("/erasures", adminController.GetErasureRecords)
+		administration.GET("/stats", adminController.GetStats)
+		administration.GET("/audit-log", adminController.GetAuditLog)
+	}
+
+	return &Jobs{
+		reminders: services.NewReminderService(allTaskRepo, events),
+		ranks:     services.NewRankRebalancer(allTaskRepo),
+		digests:   digestService,
+		erasures:  personalDataService,
 	}
 }
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountService defines the interface for users managing their own account.
+type AccountService interface {
+	GetProfile(userID uint) (*models.User, error)
+	ChangePassword(userID uint, currentPassword, newPassword string) error
+	ChangeEmail(userID uint, password, newEmail string) error
+	DeleteAccount(userID uint, password string) error
+}
+
+type accountService struct {
+	userRepo     repository.UserRepository
+	verification EmailVerificationService
+	policy       *PasswordPolicy
+	personalData PersonalDataService
+	now          func() time.Time
+}
+
+// NewAccountService creates a new instance of AccountService.
+func NewAccountService(userRepo repository.UserRepository, verification EmailVerificationService, policy *PasswordPolicy, personalData PersonalDataService) AccountService {
+	return &accountService{userRepo: userRepo, verification: verification, policy: policy, personalData: personalData, now: time.Now}
+}
+
+// GetProfile returns the user's account.
+func (s *accountService) GetProfile(userID uint) (*models.User, error) {
+	return s.findUser(userID)
+}
+
+// ChangePassword replaces the password after checking the current one. Login tokens
+// issued before the change stop working, so the client has to log in again.
+func (s *accountService) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := s.authenticate(userID, currentPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Validate(newPassword, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdatePassword(userID, string(hash), s.now())
+}
+
+// ChangeEmail starts an email change after checking the password. The account keeps its
+// current address until the link sent to the new one is opened.
+func (s *accountService) ChangeEmail(userID uint, password, newEmail string) error {
+	user, err := s.authenticate(userID, password)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("invalid email: this is already your address")
+	}
+
+	existing, err := s.userRepo.FindByEmail(newEmail)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("email address is already in use")
+	}
+
+	if err := s.userRepo.SetPendingEmail(userID, newEmail); err != nil {
+		return err
+	}
+	return s.verification.SendEmailChange(user, newEmail)
+}
+
+// DeleteAccount disables the account after checking the password and queues its erasure.
+// Tasks are deleted, except tasks in projects shared with other members, which are kept
+// without an author.
+func (s *accountService) DeleteAccount(userID uint, password string) error {
+	if _, err := s.authenticate(userID, password); err != nil {
+		return err
+	}
+	_, err := s.personalData.RequestErasure(userID, userID)
+	return err
+}
+
+// authenticate loads the user and checks their current password.
+func (s *accountService) authenticate(userID uint, password string) (*models.User, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, errors.New("invalid password")
+	}
+	return user, nil
+}
+
+// findUser loads a user, treating a missing user as an error.
+func (s *accountService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
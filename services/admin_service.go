@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// AdminService defines the interface for administrators managing users and content.
+type AdminService interface {
+	ListUsers(filter models.UserFilter) (*models.UserPage, error)
+	GetUser(userID uint) (*models.User, error)
+	SetDisabled(adminID, userID uint, disabled bool) (*models.User, error)
+	SetRole(adminID, userID uint, role string) (*models.User, error)
+	ForcePasswordReset(userID uint) error
+	ListUserTasks(userID uint, filter models.TaskFilter) (*models.TaskPage, error)
+	GetStats() (*models.SystemStats, error)
+	Unlock(email, ip string) (*models.User, error)
+}
+
+type adminService struct {
+	userRepo      repository.UserRepository
+	repo          repository.AdminRepository
+	taskService   TaskService
+	passwordReset PasswordResetService
+	loginThrottle LoginThrottleService
+	now           func() time.Time
+}
+
+// NewAdminService creates a new instance of AdminService.
+func NewAdminService(userRepo repository.UserRepository, repo repository.AdminRepository, taskService TaskService, passwordReset PasswordResetService, loginThrottle LoginThrottleService) AdminService {
+	return &adminService{
+		userRepo:      userRepo,
+		repo:          repo,
+		taskService:   taskService,
+		passwordReset: passwordReset,
+		loginThrottle: loginThrottle,
+		now:           time.Now,
+	}
+}
+
+// ListUsers returns one page of users matching the filter.
+func (s *adminService) ListUsers(filter models.UserFilter) (*models.UserPage, error) {
+	return s.repo.ListUsers(filter)
+}
+
+// GetUser returns a user's account.
+func (s *adminService) GetUser(userID uint) (*models.User, error) {
+	return s.findUser(userID)
+}
+
+// SetDisabled disables or enables an account. Disabled users cannot log in, and their
+// tokens and API keys are rejected. Administrators cannot disable themselves.
+func (s *adminService) SetDisabled(adminID, userID uint, disabled bool) (*models.User, error) {
+	if adminID == userID && disabled {
+		return nil, errors.New("invalid request: you cannot disable your own account")
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var at *time.Time
+	if disabled {
+		if user.DisabledAt != nil {
+			return user, nil
+		}
+		now := s.now()
+		at = &now
+	}
+	if err := s.repo.SetDisabled(userID, at); err != nil {
+		return nil, err
+	}
+	user.DisabledAt = at
+	return user, nil
+}
+
+// SetRole makes a user an administrator or a regular user. Administrators cannot change
+// their own role, so there is always at least one administrator left.
+func (s *adminService) SetRole(adminID, userID uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, errors.New("invalid role: must be user or admin")
+	}
+	if adminID == userID {
+		return nil, errors.New("invalid request: you cannot change your own role")
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetRole(userID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+// ForcePasswordReset logs the user out everywhere, blocks logins until the password is
+// reset and emails a reset link.
+func (s *adminService) ForcePasswordReset(userID uint) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RequirePasswordReset(userID, s.now()); err != nil {
+		return err
+	}
+	return s.passwordReset.RequestReset(user.Email)
+}
+
+// ListUserTasks returns one page of any user's tasks.
+func (s *adminService) ListUserTasks(userID uint, filter models.TaskFilter) (*models.TaskPage, error) {
+	if _, err := s.findUser(userID); err != nil {
+		return nil, err
+	}
+	return s.taskService.ListTasks(userID, filter)
+}
+
+// GetStats counts users, tasks and projects of the whole system.
+func (s *adminService) GetStats() (*models.SystemStats, error) {
+	return s.repo.GetStats(s.now())
+}
+
+// Unlock clears failed login attempts of an account and, optionally, a client IP.
+// It returns the account's user, or nil if no user has the email.
+func (s *adminService) Unlock(email, ip string) (*models.User, error) {
+	if err := s.loginThrottle.Unlock(email, ip); err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, nil
+	}
+	return s.userRepo.FindByEmail(email)
+}
+
+// findUser loads a user, treating a missing user as an error.
+func (s *adminService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// APIKeyPrefix marks API keys so they can be told apart from JWTs in the Authorization header
+const APIKeyPrefix = "tm_"
+
+// lastUsedResolution limits how often last_used_at is written for a busy key
+const lastUsedResolution = time.Minute
+
+// APIKeyService defines the interface for managing and verifying API keys.
+type APIKeyService interface {
+	CreateKey(userID uint, request models.APIKeyCreateRequest) (*models.APIKeyCreateResponse, error)
+	ListKeys(userID uint) ([]models.APIKey, error)
+	RevokeKey(id uint, userID uint) error
+	Authenticate(key string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService.
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo, now: time.Now}
+}
+
+// IsAPIKey reports whether a bearer token looks like an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// CreateKey creates a new key for the user. The plaintext key is only part of the returned value;
+// only its hash is stored.
+func (s *apiKeyService) CreateKey(userID uint, request models.APIKeyCreateRequest) (*models.APIKeyCreateResponse, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, errors.New("invalid key: name cannot be empty")
+	}
+	if len(request.Scopes) == 0 {
+		return nil, errors.New("invalid key: at least one scope is required")
+	}
+	scopes := uniqueStrings(request.Scopes)
+	for _, scope := range scopes {
+		if !models.IsValidScope(scope) {
+			return nil, errors.New("invalid key: unknown scope " + scope)
+		}
+	}
+	if request.ExpiresInDays < 0 {
+		return nil, errors.New("invalid key: expires_in_days cannot be negative")
+	}
+
+	secret, err := generateToken(24)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := APIKeyPrefix + secret
+
+	key := models.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  plaintext[:len(APIKeyPrefix)+8],
+		KeyHash: hashToken(plaintext),
+		Scopes:  scopes,
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := s.now().AddDate(0, 0, request.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(&key); err != nil {
+		return nil, err
+	}
+	return &models.APIKeyCreateResponse{APIKey: key, Key: plaintext}, nil
+}
+
+// ListKeys returns the user's keys without their secrets.
+func (s *apiKeyService) ListKeys(userID uint) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	if err := s.repo.GetByUserID(userID, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeKey deletes one of the user's keys.
+func (s *apiKeyService) RevokeKey(id uint, userID uint) error {
+	deleted, err := s.repo.Delete(id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("api key not found")
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key to the stored key and records its use.
+func (s *apiKeyService) Authenticate(plaintext string) (*models.APIKey, error) {
+	if !IsAPIKey(plaintext) {
+		return nil, errors.New("invalid api key")
+	}
+	key, err := s.repo.FindByHash(hashToken(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("invalid api key")
+	}
+
+	now := s.now()
+	if key.Expired(now) {
+		return nil, errors.New("api key expired")
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// AuditService defines the interface for the administrator audit log.
+type AuditService interface {
+	Record(entry *models.AuditEntry) error
+	List(filter models.AuditFilter) (*models.AuditPage, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// Record appends an entry to the audit log.
+func (s *auditService) Record(entry *models.AuditEntry) error {
+	return s.repo.Create(entry)
+}
+
+// List returns one page of the audit log, newest first.
+func (s *auditService) List(filter models.AuditFilter) (*models.AuditPage, error) {
+	return s.repo.List(filter)
+}
@@ -5,14 +5,28 @@ import (
 	"os"
 	"time"
 
+	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthService defines the interface for authentication-related operations.
 type AuthService interface {
-	GenerateToken(userID uint) (string, error)         // Generate a JWT for a given user ID.
-	VerifyToken(tokenString string) (uint, error)      // Verify a JWT and return the user ID.
-	ParseToken(tokenString string) (*jwt.Token, error) // Optionally parse token for advanced use cases.
+	GenerateToken(userID uint) (string, error)                                                   // Generate a JWT with every scope for a given user ID.
+	GenerateScopedToken(userID uint, scopes []string) (string, error)                            // Generate a JWT limited to the given scopes.
+	GenerateOrganizationToken(userID uint, scopes []string, organizationID uint) (string, error) // Generate a scoped JWT that selects an organization.
+	ReissueToken(claims TokenClaims, organizationID uint) (string, error)                        // Copy a verified JWT into one that selects an organization.
+	VerifyToken(tokenString string) (uint, error)                                                // Verify a JWT and return the user ID.
+	VerifyTokenClaims(tokenString string) (*TokenClaims, error)                                  // Verify a JWT and return its user ID, scopes and issue time.
+	ParseToken(tokenString string) (*jwt.Token, error)                                           // Optionally parse token for advanced use cases.
+}
+
+// TokenClaims are the verified claims of a JWT
+type TokenClaims struct {
+	UserID         uint
+	Scopes         []string
+	IssuedAt       time.Time // Zero for tokens issued before the claim was added
+	ExpiresAt      time.Time // Zero for tokens without an expiry
+	OrganizationID uint      // Active organization selected by the token, zero if none
 }
 
 type authService struct {
@@ -21,36 +35,136 @@ type authService struct {
 
 // NewAuthService creates a new instance of AuthService.
 func NewAuthService() AuthService {
+	return &authService{secretKey: jwtSecret()}
+}
+
+// jwtSecret returns the key used to sign JWTs and other signed links.
+func jwtSecret() string {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "default_secret" // Fallback for testing (avoid in production).
 	}
-	return &authService{secretKey: secret}
+	return secret
 }
 
-// GenerateToken generates a JWT for the given user ID.
+// GenerateToken generates a JWT with every scope for the given user ID.
 func (a *authService) GenerateToken(userID uint) (string, error) {
-	claims := jwt.MapClaims{
-		"userID": userID,
-		"exp":    time.Now().Add(time.Hour * 24).Unix(), // 24-hour expiration.
+	return a.GenerateScopedToken(userID, models.AllScopes)
+}
+
+// GenerateScopedToken generates a JWT for the given user ID that is limited to the given scopes.
+func (a *authService) GenerateScopedToken(userID uint, scopes []string) (string, error) {
+	return a.GenerateOrganizationToken(userID, scopes, 0)
+}
+
+// GenerateOrganizationToken generates a scoped JWT whose "org" claim selects the active
+// organization. Membership is checked when the token is used, not here.
+func (a *authService) GenerateOrganizationToken(userID uint, scopes []string, organizationID uint) (string, error) {
+	for _, scope := range scopes {
+		if !models.IsValidScope(scope) {
+			return "", errors.New("invalid scope " + scope)
+		}
+	}
+	now := time.Now()
+	return a.sign(TokenClaims{
+		UserID:         userID,
+		Scopes:         scopes,
+		IssuedAt:       now,
+		ExpiresAt:      now.Add(time.Hour * 24), // 24-hour expiration.
+		OrganizationID: organizationID,
+	})
+}
+
+// ReissueToken returns a JWT with the user, scopes, issue time and expiry of a verified token
+// whose "org" claim selects the organization. Keeping the lifetime means exchanging tokens
+// cannot keep a session alive past its expiry or its revocation.
+func (a *authService) ReissueToken(claims TokenClaims, organizationID uint) (string, error) {
+	if claims.ExpiresAt.IsZero() {
+		return "", errors.New("token has no expiry")
+	}
+	claims.OrganizationID = organizationID
+	return a.sign(claims)
+}
+
+// sign creates a JWT with the given claims
+func (a *authService) sign(claims TokenClaims) (string, error) {
+	mapClaims := jwt.MapClaims{
+		"userID": claims.UserID,
+		"scopes": claims.Scopes,
+		"exp":    claims.ExpiresAt.Unix(),
+	}
+	if !claims.IssuedAt.IsZero() {
+		mapClaims["iat"] = claims.IssuedAt.Unix()
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if claims.OrganizationID != 0 {
+		mapClaims["org"] = claims.OrganizationID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, mapClaims)
 	return token.SignedString([]byte(a.secretKey))
 }
 
 // VerifyToken validates a JWT and extracts the user ID.
 func (a *authService) VerifyToken(tokenString string) (uint, error) {
-	token, err := a.ParseToken(tokenString)
+	claims, err := a.VerifyTokenClaims(tokenString)
 	if err != nil {
 		return 0, err
 	}
+	return claims.UserID, nil
+}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if userID, ok := claims["userID"].(float64); ok {
-			return uint(userID), nil
+// VerifyTokenClaims validates a JWT and extracts the user ID, scopes and issue time.
+// Tokens issued before scopes were introduced have every scope.
+func (a *authService) VerifyTokenClaims(tokenString string) (*TokenClaims, error) {
+	token, err := a.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	// Signed links such as email verification are not login tokens
+	if _, ok := claims["purpose"]; ok {
+		return nil, errors.New("invalid token claims")
+	}
+	userID, ok := claims["userID"].(float64)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	result := &TokenClaims{UserID: uint(userID), Scopes: models.AllScopes}
+	if iat, ok := claims["iat"].(float64); ok {
+		result.IssuedAt = time.Unix(int64(iat), 0)
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		result.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if org, ok := claims["org"]; ok {
+		id, ok := org.(float64)
+		if !ok || id < 1 {
+			return nil, errors.New("invalid token claims")
+		}
+		result.OrganizationID = uint(id)
+	}
+
+	rawScopes, ok := claims["scopes"]
+	if !ok {
+		return result, nil
+	}
+	list, ok := rawScopes.([]interface{})
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	scopes := make([]string, 0, len(list))
+	for _, raw := range list {
+		scope, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("invalid token claims")
 		}
+		scopes = append(scopes, scope)
 	}
-	return 0, errors.New("invalid token claims")
+	result.Scopes = scopes
+	return result, nil
 }
 
 // ParseToken parses and validates a JWT, returning the token for advanced use cases.
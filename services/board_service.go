@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// BoardService defines the interface for working with the kanban boards of projects.
+type BoardService interface {
+	GetBoard(projectID, userID uint) (*models.Board, error)
+	SetColumns(projectID, userID uint, columns []models.BoardColumnRequest) ([]models.BoardColumn, error)
+	ForOrganization(organizationID uint) BoardService
+}
+
+type boardService struct {
+	projectRepo repository.ProjectRepository
+	taskRepo    repository.TaskRepository
+}
+
+// NewBoardService creates a new instance of BoardService.
+func NewBoardService(projectRepo repository.ProjectRepository, taskRepo repository.TaskRepository) BoardService {
+	return &boardService{projectRepo: projectRepo, taskRepo: taskRepo}
+}
+
+// ForOrganization returns a BoardService restricted to the organization's projects and tasks.
+func (s *boardService) ForOrganization(organizationID uint) BoardService {
+	return &boardService{
+		projectRepo: s.projectRepo.ForOrganization(organizationID),
+		taskRepo:    s.taskRepo.ForOrganization(organizationID),
+	}
+}
+
+// GetBoard returns the board of a project the user is a member of: its columns with all the
+// project's tasks in manual order. Statuses without a configured column get a default one.
+func (s *boardService) GetBoard(projectID, userID uint) (*models.Board, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	workflow, err := loadWorkflow(s.projectRepo, &projectID)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := s.projectRepo.GetBoardColumns(projectID)
+	if err != nil {
+		return nil, err
+	}
+	columns := boardLayout(projectID, workflow, configured)
+	tasks, err := s.taskRepo.GetByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &models.Board{ProjectID: projectID, Columns: make([]models.BoardColumnTasks, len(columns))}
+	byStatus := make(map[string]*models.BoardColumnTasks, len(columns))
+	for i, column := range columns {
+		board.Columns[i] = models.BoardColumnTasks{BoardColumn: column, Tasks: []models.Task{}}
+		byStatus[column.Status] = &board.Columns[i]
+	}
+	for _, task := range tasks {
+		column, ok := byStatus[task.Status]
+		if !ok {
+			continue
+		}
+		column.Tasks = append(column.Tasks, task)
+	}
+	for i := range board.Columns {
+		column := &board.Columns[i]
+		column.TaskCount = len(column.Tasks)
+		column.OverLimit = column.WIPLimit != nil && column.TaskCount > *column.WIPLimit
+	}
+	return board, nil
+}
+
+// SetColumns replaces the columns of a project's board. Only the project owner can configure
+// the board; the columns are given in board order and must cover every status of the
+// project's workflow exactly once.
+func (s *boardService) SetColumns(projectID, userID uint, requests []models.BoardColumnRequest) ([]models.BoardColumn, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	if member.Role != models.ProjectRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+
+	workflow, err := loadWorkflow(s.projectRepo, &projectID)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := boardColumns(projectID, workflow, requests)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.SetBoardColumns(projectID, columns); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// boardLayout returns the columns of a board: the configured columns of the workflow's
+// statuses in board order, followed by default columns for the statuses without one.
+func boardLayout(projectID uint, workflow models.Workflow, configured []models.BoardColumn) []models.BoardColumn {
+	columns := make([]models.BoardColumn, 0, len(workflow.Statuses))
+	placed := make(map[string]bool, len(configured))
+	for _, column := range configured {
+		if _, ok := workflow.Status(column.Status); ok {
+			column.Position = len(columns)
+			columns = append(columns, column)
+			placed[column.Status] = true
+		}
+	}
+	for _, status := range workflow.Statuses {
+		if !placed[status.Name] {
+			column := models.DefaultBoardColumn(projectID, status)
+			column.Position = len(columns)
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// boardColumns validates column requests and turns them into the columns of a board.
+func boardColumns(projectID uint, workflow models.Workflow, requests []models.BoardColumnRequest) ([]models.BoardColumn, error) {
+	seen := make(map[string]bool, len(requests))
+	columns := make([]models.BoardColumn, len(requests))
+	for i, request := range requests {
+		status, ok := workflow.Status(request.Status)
+		if !ok {
+			return nil, fmt.Errorf("invalid column status %q", request.Status)
+		}
+		if seen[request.Status] {
+			return nil, fmt.Errorf("invalid columns: status %q has more than one column", request.Status)
+		}
+		seen[request.Status] = true
+
+		name := strings.TrimSpace(request.Name)
+		if name == "" {
+			name = request.Status
+		}
+		if len([]rune(name)) > models.MaxBoardColumnName {
+			return nil, fmt.Errorf("invalid column name: must be at most %d characters", models.MaxBoardColumnName)
+		}
+		color := request.Color
+		if color == "" {
+			color = models.DefaultColumnColor(status.Category)
+		}
+		if !models.IsValidColumnColor(color) {
+			return nil, errors.New("invalid column color: must be written as #RRGGBB")
+		}
+		if request.WIPLimit != nil && *request.WIPLimit < 1 {
+			return nil, errors.New("invalid wip_limit: must be a positive number or null")
+		}
+		columns[i] = models.BoardColumn{
+			ProjectID: projectID,
+			Status:    request.Status,
+			Name:      name,
+			Position:  i,
+			Color:     strings.ToLower(color),
+			WIPLimit:  request.WIPLimit,
+		}
+	}
+	for _, status := range workflow.Statuses {
+		if !seen[status.Name] {
+			return nil, fmt.Errorf("invalid columns: status %q has no column", status.Name)
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"io"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// Calendar feed component types
+const (
+	CalendarTodos  = "todo"
+	CalendarEvents = "event"
+)
+
+// CalendarService defines the interface for iCalendar feeds and imports.
+type CalendarService interface {
+	CreateFeed(userID uint) (string, error)
+	RevokeFeed(userID uint) error
+	WriteFeed(userID uint, component string, w io.Writer) error
+	ResolveFeed(token string) (*models.CalendarFeed, error)
+	ImportICS(userID uint, r io.Reader, dryRun bool) (*models.ImportReport, error)
+	ForOrganization(organizationID uint) CalendarService
+}
+
+type calendarService struct {
+	transactor  repository.Transactor
+	feedRepo    repository.CalendarFeedRepository
+	taskRepo    repository.TaskRepository
+	userRepo    repository.UserRepository
+	taskService TaskService
+}
+
+// NewCalendarService creates a new instance of CalendarService.
+func NewCalendarService(transactor repository.Transactor, feedRepo repository.CalendarFeedRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository, taskService TaskService) CalendarService {
+	return &calendarService{transactor: transactor, feedRepo: feedRepo, taskRepo: taskRepo, userRepo: userRepo, taskService: taskService}
+}
+
+// ForOrganization returns a CalendarService restricted to the organization's feeds and tasks.
+func (s *calendarService) ForOrganization(organizationID uint) CalendarService {
+	return &calendarService{
+		transactor:  s.transactor,
+		feedRepo:    s.feedRepo.ForOrganization(organizationID),
+		taskRepo:    s.taskRepo.ForOrganization(organizationID),
+		userRepo:    s.userRepo,
+		taskService: s.taskService.ForOrganization(organizationID),
+	}
+}
+
+// CreateFeed creates a secret feed token for the user in the organization, revoking any previous one.
+// The token is returned once; only its hash is stored.
+func (s *calendarService) CreateFeed(userID uint) (string, error) {
+	token, err := generateToken(32)
+	if err != nil {
+		return "", err
+	}
+	feed := &models.CalendarFeed{UserID: userID, TokenHash: hashToken(token)}
+	if err := s.feedRepo.Replace(feed); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeFeed disables the user's feed URL.
+func (s *calendarService) RevokeFeed(userID uint) error {
+	return s.feedRepo.DeleteByUserID(userID)
+}
+
+// ResolveFeed returns the feed of a token, which names its owner and organization. Feeds of
+// disabled accounts are rejected like their login tokens.
+func (s *calendarService) ResolveFeed(token string) (*models.CalendarFeed, error) {
+	feed, err := s.feedRepo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, errors.New("feed not found")
+	}
+	user, err := s.userRepo.FindByID(feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("feed not found")
+	}
+	if user.DisabledAt != nil {
+		return nil, errors.New("account is disabled")
+	}
+	return feed, nil
+}
+
+// WriteFeed streams the user's tasks as VTODO or VEVENT components.
+// Events are only produced for tasks with a due date.
+func (s *calendarService) WriteFeed(userID uint, component string, w io.Writer) error {
+	if component != CalendarTodos && component != CalendarEvents {
+		return errors.New("unsupported component type")
+	}
+
+	iw := &icalWriter{w: w}
+	writeICalHeader(iw, "Tasks")
+	err := s.taskRepo.EachByUserID(userID, exportBatchSize, func(tasks []models.Task) error {
+		for _, task := range tasks {
+			if component == CalendarTodos {
+				writeICalTodo(iw, task)
+			} else if task.DueDate != nil {
+				writeICalEvent(iw, task)
+			}
+		}
+		return iw.err
+	})
+	if err != nil {
+		return err
+	}
+	writeICalFooter(iw)
+	return iw.err
+}
+
+// ImportICS creates or updates tasks from the VTODO components of an iCalendar file.
+// Components whose UID matches a task of the user update that task, so exported
+// feeds can be imported back without creating duplicates. Updates go through the
+// same checks as UpdateTask.
+func (s *calendarService) ImportICS(userID uint, r io.Reader, dryRun bool) (*models.ImportReport, error) {
+	todos, err := parseICalTodos(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) > MaxImportRows {
+		return nil, errors.New("invalid file: too many VTODO components")
+	}
+
+	existing, err := s.matchExistingTasks(userID, todos)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Total: len(todos), DryRun: dryRun, Errors: []models.ImportRowError{}}
+	tasks := make([]models.Task, 0, len(todos))
+	var updates []TaskUpdate
+
+	for i, todo := range todos {
+		task, ok := existing[todo.UID]
+		if !ok {
+			task = models.Task{UserID: userID, ICalUID: todo.UID, Title: todo.Summary, Description: todo.Description,
+				Status: taskStatusFromICal(todo.Status), DueDate: todo.Due}
+			if err := s.taskService.ValidateTask(&task); err != nil {
+				report.Errors = append(report.Errors, models.ImportRowError{Row: i + 1, Error: err.Error()})
+				continue
+			}
+			tasks = append(tasks, task)
+			continue
+		}
+
+		request := models.TaskUpdateRequest{
+			Title:       &todo.Summary,
+			Description: &todo.Description,
+			DueDate:     models.OptionalTime{Set: true, Value: todo.Due},
+		}
+		if status := taskStatusFromICal(todo.Status); models.DefaultStatusCategory(status) != task.Category() {
+			request.Status = &status // Tasks keep their custom status unless its category changed
+		}
+		if _, err := s.taskService.ValidateUpdate(task.ID, userID, request); err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		updates = append(updates, TaskUpdate{TaskID: task.ID, Request: request})
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	err = s.transactor.Transaction(func(tx *gorm.DB) error {
+		if err := s.taskRepo.WithTx(tx).CreateBatch(tasks); err != nil {
+			return err
+		}
+		// Updates are checked again as they are saved, so WIP limits and ranks see the earlier rows
+		_, err := s.taskService.WithTx(tx).UpdateTasks(userID, updates)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(tasks) + len(updates)
+	return report, nil
+}
+
+// matchExistingTasks finds the user's tasks referenced by the VTODO UIDs.
+func (s *calendarService) matchExistingTasks(userID uint, todos []icalTodo) (map[string]models.Task, error) {
+	var ids []uint
+	var uids []string
+	for _, todo := range todos {
+		if todo.UID == "" {
+			continue
+		}
+		// Generated UIDs point at our own task IDs; any UID may also have been imported before
+		if id, ok := taskIDFromICalUID(todo.UID); ok {
+			ids = append(ids, id)
+		}
+		uids = append(uids, todo.UID)
+	}
+
+	var byID, byUID []models.Task
+	if err := s.taskRepo.GetByIDs(userID, ids, &byID); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.GetByICalUIDs(userID, uids, &byUID); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]models.Task, len(byID)+len(byUID))
+	for _, task := range append(byID, byUID...) {
+		existing[taskICalUID(task)] = task
+	}
+	return existing, nil
+}
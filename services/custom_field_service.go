@@ -0,0 +1,391 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// CustomFieldService defines the interface for working with the custom fields of projects.
+type CustomFieldService interface {
+	GetFields(projectID, userID uint) ([]models.CustomField, error)
+	SetFields(projectID, userID uint, requests []models.CustomFieldRequest) ([]models.CustomField, error)
+	ForOrganization(organizationID uint) CustomFieldService
+}
+
+type customFieldService struct {
+	projectRepo repository.ProjectRepository
+}
+
+// NewCustomFieldService creates a new instance of CustomFieldService.
+func NewCustomFieldService(projectRepo repository.ProjectRepository) CustomFieldService {
+	return &customFieldService{projectRepo: projectRepo}
+}
+
+// ForOrganization returns a CustomFieldService restricted to the organization's projects.
+func (s *customFieldService) ForOrganization(organizationID uint) CustomFieldService {
+	return &customFieldService{projectRepo: s.projectRepo.ForOrganization(organizationID)}
+}
+
+// GetFields returns the custom fields of a project the user is a member of.
+func (s *customFieldService) GetFields(projectID, userID uint) ([]models.CustomField, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	fields, err := s.projectRepo.GetCustomFields(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = []models.CustomField{}
+	}
+	return fields, nil
+}
+
+// SetFields replaces the custom fields of a project. Only the project owner can change them;
+// fields that are left out are deleted with their values, and kept fields cannot change type.
+func (s *customFieldService) SetFields(projectID, userID uint, requests []models.CustomFieldRequest) ([]models.CustomField, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	if member.Role != models.ProjectRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+
+	current, err := s.projectRepo.GetCustomFields(projectID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := customFields(projectID, current, requests)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.SetCustomFields(projectID, fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// customFields validates field requests and turns them into the custom fields of a project.
+func customFields(projectID uint, current []models.CustomField, requests []models.CustomFieldRequest) ([]models.CustomField, error) {
+	if len(requests) > models.MaxCustomFields {
+		return nil, fmt.Errorf("invalid fields: a project can have at most %d fields", models.MaxCustomFields)
+	}
+	types := make(map[string]string, len(current))
+	for _, field := range current {
+		types[field.Key] = field.Type
+	}
+
+	fields := make([]models.CustomField, len(requests))
+	keys := make(map[string]bool, len(requests))
+	for i, request := range requests {
+		name := strings.TrimSpace(request.Name)
+		if name == "" || len([]rune(name)) > models.MaxCustomFieldName {
+			return nil, fmt.Errorf("invalid field name: must be between 1 and %d characters", models.MaxCustomFieldName)
+		}
+		key := strings.TrimSpace(request.Key)
+		if key == "" {
+			key = fieldKey(name)
+		}
+		if !models.IsValidCustomFieldKey(key) {
+			return nil, fmt.Errorf("invalid field key %q: must start with a lowercase letter and have at most 40 lowercase letters, digits and underscores", key)
+		}
+		if keys[key] {
+			return nil, fmt.Errorf("invalid fields: key %q is used more than once", key)
+		}
+		keys[key] = true
+		if !models.IsValidCustomFieldType(request.Type) {
+			return nil, fmt.Errorf("invalid field type %q: must be one of %s", request.Type, strings.Join(models.CustomFieldTypes, ", "))
+		}
+		if previous, ok := types[key]; ok && previous != request.Type {
+			return nil, fmt.Errorf("invalid field %q: the type of an existing field cannot change", key)
+		}
+
+		field := models.CustomField{ProjectID: projectID, Key: key, Name: name, Type: request.Type, Required: request.Required, Position: i}
+		if err := fieldRules(&field, request); err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// fieldRules validates the rules of a field request and copies them to the field.
+func fieldRules(field *models.CustomField, request models.CustomFieldRequest) error {
+	selects := field.Type == models.FieldTypeSelect || field.Type == models.FieldTypeMultiSelect
+	if field.Required && field.Type == models.FieldTypeCheckbox {
+		return fmt.Errorf("invalid field %q: checkbox fields cannot be required", field.Key)
+	}
+	if len(request.Options) > 0 && !selects {
+		return fmt.Errorf("invalid field %q: only select and multi_select fields have options", field.Key)
+	}
+	if (request.Min != nil || request.Max != nil) && field.Type != models.FieldTypeNumber {
+		return fmt.Errorf("invalid field %q: only number fields have min and max", field.Key)
+	}
+	if request.MaxLength != nil && field.Type != models.FieldTypeText {
+		return fmt.Errorf("invalid field %q: only text fields have max_length", field.Key)
+	}
+
+	if selects {
+		if len(request.Options) == 0 || len(request.Options) > models.MaxFieldOptions {
+			return fmt.Errorf("invalid field %q: must have between 1 and %d options", field.Key, models.MaxFieldOptions)
+		}
+		field.Options = make([]string, 0, len(request.Options))
+		for _, option := range request.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || len([]rune(option)) > models.MaxFieldOption {
+				return fmt.Errorf("invalid field %q: options must be between 1 and %d characters", field.Key, models.MaxFieldOption)
+			}
+			if field.HasOption(option) {
+				return fmt.Errorf("invalid field %q: option %q is listed more than once", field.Key, option)
+			}
+			field.Options = append(field.Options, option)
+		}
+	}
+	if request.Min != nil && request.Max != nil && *request.Min > *request.Max {
+		return fmt.Errorf("invalid field %q: min must not be greater than max", field.Key)
+	}
+	field.Min, field.Max = request.Min, request.Max
+	if request.MaxLength != nil && (*request.MaxLength < 1 || *request.MaxLength > models.MaxTextFieldLength) {
+		return fmt.Errorf("invalid field %q: max_length must be between 1 and %d", field.Key, models.MaxTextFieldLength)
+	}
+	field.MaxLength = request.MaxLength
+	return nil
+}
+
+// fieldKey derives a key from a field name, e.g. "story_points" from "Story points". Names
+// without latin letters give an invalid key, so such fields need an explicit key.
+func fieldKey(name string) string {
+	var key strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if underscore && key.Len() > 0 {
+				key.WriteByte('_')
+			}
+			key.WriteRune(r)
+			underscore = false
+			continue
+		}
+		underscore = true
+	}
+	derived := key.String()
+	if len(derived) > 40 {
+		derived = strings.TrimRight(derived[:40], "_") // Keys are ASCII, so bytes are characters
+	}
+	return derived
+}
+
+// customFieldCache remembers the custom fields of projects while a batch of tasks is validated.
+type customFieldCache map[uint][]models.CustomField
+
+// load returns the custom fields of a project, loading them on first use.
+func (c customFieldCache) load(projectRepo repository.ProjectRepository, projectID *uint) ([]models.CustomField, error) {
+	if projectID == nil {
+		return nil, nil
+	}
+	if fields, ok := c[*projectID]; ok {
+		return fields, nil
+	}
+	fields, err := projectRepo.GetCustomFields(*projectID)
+	if err != nil {
+		return nil, err
+	}
+	c[*projectID] = fields
+	return fields, nil
+}
+
+// fieldValue validates a value of a custom field and returns it as it is stored, or nil for
+// values that clear the field: null, empty text and empty lists. Whether users are members of
+// the project is checked by the caller.
+func fieldValue(field models.CustomField, value interface{}) (interface{}, error) {
+	invalid := func(rule string) error {
+		return fmt.Errorf("invalid custom field %q: %s", field.Key, rule)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch field.Type {
+	case models.FieldTypeText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be text")
+		}
+		limit := models.MaxTextFieldLength
+		if field.MaxLength != nil {
+			limit = *field.MaxLength
+		}
+		if text = strings.TrimSpace(text); len([]rune(text)) > limit {
+			return nil, invalid(fmt.Sprintf("must be at most %d characters", limit))
+		}
+		return emptyAsNil(text), nil
+	case models.FieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, invalid("must be a number")
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, invalid(fmt.Sprintf("must be at least %v", *field.Min))
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, invalid(fmt.Sprintf("must be at most %v", *field.Max))
+		}
+		return number, nil
+	case models.FieldTypeDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a date written as YYYY-MM-DD")
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			return nil, nil
+		}
+		date, err := parseFieldDate(text)
+		if err != nil {
+			return nil, invalid("must be a date written as YYYY-MM-DD")
+		}
+		return date, nil
+	case models.FieldTypeSelect:
+		option, ok := value.(string)
+		if option = strings.TrimSpace(option); !ok || (option != "" && !field.HasOption(option)) {
+			return nil, invalid("must be one of the field's options")
+		}
+		return emptyAsNil(option), nil
+	case models.FieldTypeMultiSelect:
+		var list []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			list = v
+		case []string:
+			for _, option := range v {
+				list = append(list, option)
+			}
+		default:
+			return nil, invalid("must be a list of the field's options")
+		}
+		options := []string{}
+		for _, item := range list {
+			option, ok := item.(string)
+			if option = strings.TrimSpace(option); !ok || !field.HasOption(option) {
+				return nil, invalid("must be a list of the field's options")
+			}
+			if !contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return nil, nil
+		}
+		return options, nil
+	case models.FieldTypeUser:
+		id, ok := value.(float64)
+		if !ok || id < 1 || id != float64(uint(id)) {
+			return nil, invalid("must be the ID of a project member")
+		}
+		return uint(id), nil
+	case models.FieldTypeCheckbox:
+		checked, ok := value.(bool)
+		if !ok {
+			return nil, invalid("must be true or false")
+		}
+		return checked, nil
+	}
+	return nil, invalid("has an unknown type")
+}
+
+// parseFieldDate accepts dates written as YYYY-MM-DD and RFC3339 timestamps, and returns the date.
+func parseFieldDate(raw string) (string, error) {
+	if t, err := time.Parse(models.FieldDateLayout, raw); err == nil {
+		return t.Format(models.FieldDateLayout), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(models.FieldDateLayout), nil
+}
+
+// emptyAsNil returns nil for empty text, which clears a field.
+func emptyAsNil(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	return text
+}
+
+// fieldFilterValue checks the value of a custom field condition and returns it in the form
+// the field's values are stored in. Only number and date fields can be compared.
+func fieldFilterValue(fieldType, op, raw string) (string, error) {
+	if op != "eq" && fieldType != models.FieldTypeNumber && fieldType != models.FieldTypeDate {
+		return "", fmt.Errorf("only number and date fields support %s", op)
+	}
+	switch fieldType {
+	case models.FieldTypeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", errors.New("must be a number")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case models.FieldTypeDate:
+		date, err := parseFieldDate(raw)
+		if err != nil {
+			return "", errors.New("must be a date written as YYYY-MM-DD")
+		}
+		return date, nil
+	case models.FieldTypeUser:
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return "", errors.New("must be a user ID")
+		}
+		return strconv.FormatUint(id, 10), nil
+	case models.FieldTypeCheckbox:
+		checked, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", errors.New("must be true or false")
+		}
+		return strconv.FormatBool(checked), nil
+	}
+	return raw, nil
+}
+
+// keptFieldValues returns the values of a task moving between projects that the new project
+// has a field for, with the same key and type.
+func keptFieldValues(values models.FieldValues, previous, next []models.CustomField) models.FieldValues {
+	types := make(map[string]string, len(previous))
+	for _, field := range previous {
+		types[field.Key] = field.Type
+	}
+	kept := models.FieldValues{}
+	for _, field := range next {
+		if value, ok := values[field.Key]; ok && types[field.Key] == field.Type {
+			kept[field.Key] = value
+		}
+	}
+	return kept
+}
+
+// sameFieldValues compares custom field values by their JSON form, so values read from the
+// database equal the values they were saved from.
+func sameFieldValues(a, b models.FieldValues) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	jsonA, errA := json.Marshal(a)
+	jsonB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(jsonA) == string(jsonB)
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"os"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	digestUnsubscribePurpose = "digest_unsubscribe"   // Purpose claim of unsubscribe links
+	unsubscribeLinkTTL       = 90 * 24 * time.Hour    // Unsubscribe links outlive many digests
+	digestDueFormat          = "Mon, Jan 2 15:04 MST" // Due dates in the user's time zone
+)
+
+//go:embed data/digest.txt.tmpl
+var digestTextSource string
+
+//go:embed data/digest.html.tmpl
+var digestHTMLSource string
+
+// Digest templates; "due" is replaced per user to format due dates in their time zone
+var digestTextTemplate = texttemplate.Must(
+	texttemplate.New("digest").Funcs(texttemplate.FuncMap{"due": formatDue(time.UTC)}).Parse(digestTextSource))
+
+var digestHTMLTemplate = htmltemplate.Must(
+	htmltemplate.New("digest").Funcs(htmltemplate.FuncMap{"due": formatDue(time.UTC)}).Parse(digestHTMLSource))
+
+// DigestService defines the interface for daily and weekly digest emails.
+type DigestService interface {
+	GetSettings(userID uint) (*models.DigestSettings, error)
+	UpdateSettings(userID uint, request models.DigestSettingsRequest) (*models.DigestSettings, error)
+	Unsubscribe(token string) error
+	SendDue(now time.Time) (int, error)
+	Start(interval time.Duration)
+}
+
+type digestService struct {
+	repo           repository.DigestRepository
+	userRepo       repository.UserRepository
+	mailer         Mailer
+	secretKey      string
+	unsubscribeURL string
+	now            func() time.Time
+}
+
+// digestView is the data the digest templates render
+type digestView struct {
+	Period         string
+	Digest         *models.Digest
+	UnsubscribeURL string
+}
+
+// NewDigestService creates a new instance of DigestService.
+// Unsubscribe links point at DIGEST_UNSUBSCRIBE_URL with a signed token as a query parameter.
+func NewDigestService(repo repository.DigestRepository, userRepo repository.UserRepository, mailer Mailer) DigestService {
+	unsubscribeURL := os.Getenv("DIGEST_UNSUBSCRIBE_URL")
+	if unsubscribeURL == "" {
+		unsubscribeURL = "http://localhost:8080/digest/unsubscribe"
+	}
+	return &digestService{
+		repo:           repo,
+		userRepo:       userRepo,
+		mailer:         mailer,
+		secretKey:      jwtSecret(),
+		unsubscribeURL: unsubscribeURL,
+		now:            time.Now,
+	}
+}
+
+// GetSettings returns the user's digest settings; digests are off by default.
+func (s *digestService) GetSettings(userID uint) (*models.DigestSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.DigestSettings{
+			UserID:    userID,
+			Frequency: models.DigestOff,
+			SendTime:  models.DefaultDigestSendTime,
+			Weekday:   int(models.DefaultDigestWeekday),
+			TimeZone:  models.DefaultDigestTimeZone,
+		}
+	}
+	return settings, nil
+}
+
+// UpdateSettings changes when the user receives digests and schedules the next one.
+func (s *digestService) UpdateSettings(userID uint, request models.DigestSettingsRequest) (*models.DigestSettings, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Frequency != nil {
+		settings.Frequency = *request.Frequency
+	}
+	if request.SendTime != nil {
+		settings.SendTime = *request.SendTime
+	}
+	if request.Weekday != nil {
+		settings.Weekday = *request.Weekday
+	}
+	if request.TimeZone != nil {
+		settings.TimeZone = *request.TimeZone
+	}
+
+	if settings.Frequency != models.DigestOff && settings.Frequency != models.DigestDaily && settings.Frequency != models.DigestWeekly {
+		return nil, errors.New("invalid frequency: must be off, daily or weekly")
+	}
+	if _, err := time.Parse("15:04", settings.SendTime); err != nil {
+		return nil, errors.New("invalid send_time: must be HH:MM")
+	}
+	if settings.Weekday < int(time.Sunday) || settings.Weekday > int(time.Saturday) {
+		return nil, errors.New("invalid weekday: must be 0 (Sunday) to 6 (Saturday)")
+	}
+	if _, err := time.LoadLocation(settings.TimeZone); err != nil || settings.TimeZone == "" || settings.TimeZone == "Local" {
+		return nil, errors.New("invalid time_zone: must be an IANA time zone such as Europe/Berlin")
+	}
+
+	settings.NextSendAt = nil
+	if settings.Frequency != models.DigestOff {
+		next := nextDigestAt(settings, s.now())
+		settings.NextSendAt = &next
+	}
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Unsubscribe turns digests off for the user of a signed unsubscribe link.
+func (s *digestService) Unsubscribe(token string) error {
+	claims, ok := parsePurposeToken(s.secretKey, digestUnsubscribePurpose, token)
+	if !ok {
+		return errors.New("invalid or expired unsubscribe link")
+	}
+	userID, ok := claims["userID"].(float64)
+	if !ok {
+		return errors.New("invalid or expired unsubscribe link")
+	}
+	settings, err := s.repo.GetSettings(uint(userID))
+	if err != nil {
+		return err
+	}
+	if settings == nil || settings.Frequency == models.DigestOff {
+		return nil // Already unsubscribed
+	}
+	settings.Frequency = models.DigestOff
+	settings.NextSendAt = nil
+	return s.repo.SaveSettings(settings)
+}
+
+// SendDue emails the digests that are due and schedules the next ones. It returns how many
+// digests were sent; digests with nothing to report are skipped.
+func (s *digestService) SendDue(now time.Time) (int, error) {
+	due, err := s.repo.GetDue(now)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for i := range due {
+		settings := &due[i]
+		next := nextDigestAt(settings, now)
+
+		user, err := s.userRepo.FindByID(settings.UserID)
+		if err != nil {
+			return sent, err
+		}
+		if user != nil && user.DisabledAt == nil {
+			period := 24 * time.Hour
+			if settings.Frequency == models.DigestWeekly {
+				period = 7 * 24 * time.Hour
+			}
+			since := now.Add(-period)
+			if settings.LastSentAt != nil && settings.LastSentAt.After(since) {
+				since = *settings.LastSentAt
+			}
+			digest, err := s.repo.GetDigest(user.ID, since, now, now.Add(period))
+			if err != nil {
+				return sent, err
+			}
+			if !digest.IsEmpty() {
+				if err := s.send(user, settings, digest); err != nil {
+					return sent, err
+				}
+				sent++
+			}
+		}
+		if err := s.repo.MarkSent(settings.UserID, now, &next); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Start sends due digests every interval in the background.
+func (s *digestService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if _, err := s.SendDue(now); err != nil {
+				log.Printf("Could not send digests: %v", err)
+			}
+		}
+	}()
+}
+
+// send renders a digest with the text and HTML templates and emails it with a one-click
+// unsubscribe link.
+func (s *digestService) send(user *models.User, settings *models.DigestSettings, digest *models.Digest) error {
+	token, err := signPurposeToken(s.secretKey, digestUnsubscribePurpose, jwt.MapClaims{"userID": user.ID}, unsubscribeLinkTTL)
+	if err != nil {
+		return err
+	}
+	view := digestView{
+		Period:         settings.Frequency,
+		Digest:         digest,
+		UnsubscribeURL: s.unsubscribeURL + "?token=" + url.QueryEscape(token),
+	}
+	location, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	var text, html bytes.Buffer
+	textTemplate := texttemplate.Must(digestTextTemplate.Clone()).Funcs(texttemplate.FuncMap{"due": formatDue(location)})
+	if err := textTemplate.Execute(&text, view); err != nil {
+		return err
+	}
+	htmlTemplate := htmltemplate.Must(digestHTMLTemplate.Clone()).Funcs(htmltemplate.FuncMap{"due": formatDue(location)})
+	if err := htmlTemplate.Execute(&html, view); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Your " + settings.Frequency + " Task Manager digest",
+		Body:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + view.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+}
+
+// formatDue returns a template function that formats due dates in the location
+func formatDue(location *time.Location) func(*time.Time) string {
+	return func(due *time.Time) string {
+		return due.In(location).Format(digestDueFormat)
+	}
+}
+
+// nextDigestAt returns the first send time of the settings after the given moment: the
+// local send time on the next day, or on the chosen weekday for weekly digests.
+func nextDigestAt(settings *models.DigestSettings, after time.Time) time.Time {
+	location, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	clock, err := time.Parse("15:04", settings.SendTime)
+	if err != nil {
+		clock, _ = time.Parse("15:04", models.DefaultDigestSendTime)
+	}
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+	for !next.After(after) || (settings.Frequency == models.DigestWeekly && next.Weekday() != time.Weekday(settings.Weekday)) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, clock.Hour(), clock.Minute(), 0, 0, location)
+	}
+	return next.UTC()
+}
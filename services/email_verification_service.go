@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Values of REQUIRE_EMAIL_VERIFICATION
+const (
+	VerificationOptional = ""       // Unverified users can do everything
+	VerificationLogin    = "login"  // Unverified users cannot log in
+	VerificationWrites   = "writes" // Unverified users can log in and read, but not change tasks
+)
+
+const (
+	verificationLinkTTL     = 48 * time.Hour  // How long a verification link stays valid
+	verificationResendDelay = 2 * time.Minute // Minimum time between two verification emails
+	verificationPurpose     = "verify_email"  // Purpose claim that keeps links apart from login tokens
+)
+
+// ThrottledError is returned when an action is retried too soon
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, retry in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns the wait rounded up to whole seconds, as used by the Retry-After header
+func (e *ThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// EmailVerificationService defines the interface for verifying users' email addresses.
+type EmailVerificationService interface {
+	Policy() string
+	SendVerification(user *models.User) error
+	SendEmailChange(user *models.User, newEmail string) error
+	Resend(email string) error
+	Verify(token string) error
+}
+
+type emailVerificationService struct {
+	userRepo  repository.UserRepository
+	mailer    Mailer
+	secretKey string
+	verifyURL string
+	policy    string
+	now       func() time.Time
+}
+
+// NewEmailVerificationService creates a new instance of EmailVerificationService.
+// Links point at EMAIL_VERIFICATION_URL and REQUIRE_EMAIL_VERIFICATION selects the policy.
+func NewEmailVerificationService(userRepo repository.UserRepository, mailer Mailer) EmailVerificationService {
+	verifyURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verifyURL == "" {
+		verifyURL = "http://localhost:8080/email/verify"
+	}
+	return &emailVerificationService{
+		userRepo:  userRepo,
+		mailer:    mailer,
+		secretKey: jwtSecret(),
+		verifyURL: verifyURL,
+		policy:    os.Getenv("REQUIRE_EMAIL_VERIFICATION"),
+		now:       time.Now,
+	}
+}
+
+// Policy returns what unverified users are not allowed to do.
+func (s *emailVerificationService) Policy() string {
+	return s.policy
+}
+
+// SendVerification emails a signed verification link to the user.
+func (s *emailVerificationService) SendVerification(user *models.User) error {
+	token, err := signPurposeToken(s.secretKey, verificationPurpose, jwt.MapClaims{
+		"userID": user.ID,
+		"email":  user.Email,
+	}, verificationLinkTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetVerificationSentAt(user.ID, s.now()); err != nil {
+		return err
+	}
+	link := s.verifyURL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Welcome to Task Manager!\n\n" +
+			"Please confirm your email address by opening this link within 48 hours:\n" + link,
+	})
+}
+
+// SendEmailChange emails a verification link to the new address of a pending email change
+// and lets the current address know about the request.
+func (s *emailVerificationService) SendEmailChange(user *models.User, newEmail string) error {
+	token, err := signPurposeToken(s.secretKey, verificationPurpose, jwt.MapClaims{
+		"userID": user.ID,
+		"email":  newEmail,
+	}, verificationLinkTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.verifyURL + "?token=" + url.QueryEscape(token)
+	if err := s.mailer.Send(Mail{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: "You asked to use this address for your Task Manager account.\n\n" +
+			"Please confirm it by opening this link within 48 hours:\n" + link,
+	}); err != nil {
+		return err
+	}
+	return s.mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: "Someone asked to change the email address of your Task Manager account to " + newEmail + ".\n\n" +
+			"The change takes effect once the new address is confirmed. If this was not you, " +
+			"change your password right away.",
+	})
+}
+
+// Resend sends a new verification link unless the previous one was sent moments ago.
+// Unknown and already verified addresses are ignored.
+func (s *emailVerificationService) Resend(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.VerifiedAt != nil {
+		return nil
+	}
+	if user.VerificationSentAt != nil {
+		if wait := user.VerificationSentAt.Add(verificationResendDelay).Sub(s.now()); wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+	return s.SendVerification(user)
+}
+
+// Verify checks a verification link token and marks the address as verified. Links for
+// a pending email change switch the account to the new address. Links stop working when
+// the user's email, or the pending address, changes.
+func (s *emailVerificationService) Verify(tokenString string) error {
+	invalid := errors.New("invalid or expired verification link")
+
+	claims, ok := parsePurposeToken(s.secretKey, verificationPurpose, tokenString)
+	if !ok {
+		return invalid
+	}
+	userID, ok := claims["userID"].(float64)
+	email, _ := claims["email"].(string)
+	if !ok {
+		return invalid
+	}
+
+	user, err := s.userRepo.FindByID(uint(userID))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return invalid
+	}
+	if user.PendingEmail != "" && user.PendingEmail == email {
+		return s.confirmEmailChange(user, email)
+	}
+	if user.Email != email {
+		return invalid
+	}
+	if user.VerifiedAt != nil {
+		return nil
+	}
+	return s.userRepo.MarkVerified(user.ID, s.now())
+}
+
+// confirmEmailChange switches the user to the verified pending address, unless another
+// account registered it in the meantime.
+func (s *emailVerificationService) confirmEmailChange(user *models.User, email string) error {
+	existing, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != user.ID {
+		return errors.New("email address is already in use")
+	}
+	changed, err := s.userRepo.ConfirmEmailChange(user.ID, email, s.now())
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return errors.New("invalid or expired verification link")
+	}
+	return nil
+}
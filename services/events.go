@@ -0,0 +1,104 @@
+package services
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+)
+
+// Event types published on the event bus
+const (
+	EventTaskAssigned  = "task.assigned"  // UserIDs were assigned to the task
+	EventTaskMentioned = "task.mentioned" // Mentions were added to the task's title or description
+	EventTaskUpdated   = "task.updated"   // Changes lists the fields that changed, UserIDs the watchers
+	EventTaskReminder  = "task.reminder"  // The task is due soon
+)
+
+// Event is something that happened to a task. Services publish events without knowing who
+// reacts to them, such as the notification inbox.
+type Event struct {
+	Type           string
+	OrganizationID uint
+	Task           models.Task // The task after the change, with its assignees
+	ActorID        uint        // User who caused the event; zero for scheduled events
+	UserIDs        []uint      // Users the event is about, e.g. new assignees or watchers
+	Mentions       []string    // Mentioned email addresses, lowercased
+	Changes        []string    // Changed fields of task.updated
+}
+
+// EventHandler reacts to an event
+type EventHandler func(event Event) error
+
+// EventBus delivers events from the services that publish them to the handlers subscribed
+// to their type.
+type EventBus interface {
+	Subscribe(eventType string, handler EventHandler)
+	Publish(event Event)
+}
+
+type eventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]EventHandler
+}
+
+// NewEventBus creates an in-process EventBus.
+func NewEventBus() EventBus {
+	return &eventBus{handlers: map[string][]EventHandler{}}
+}
+
+// Subscribe registers a handler for events of the given type.
+func (b *eventBus) Subscribe(eventType string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish runs the handlers of the event's type in the order they subscribed. Handler errors
+// are logged, so a failing subscriber never fails the change that published the event.
+func (b *eventBus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.Type]
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			log.Printf("Could not handle %s event for task %d: %v", event.Type, event.Task.ID, err)
+		}
+	}
+}
+
+// mentionPattern matches mentions of users by email address, e.g. "@ann@example.com"
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.%+-]+@[\w-]+(?:\.[\w-]+)*\.[A-Za-z]{2,})`)
+
+// mentionedEmails returns the lowercased email addresses mentioned in the texts, without duplicates
+func mentionedEmails(texts ...string) []string {
+	seen := map[string]bool{}
+	var emails []string
+	for _, text := range texts {
+		for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			email := strings.ToLower(match[1])
+			if !seen[email] {
+				seen[email] = true
+				emails = append(emails, email)
+			}
+		}
+	}
+	return emails
+}
+
+// newMentions returns the emails mentioned in after that were not mentioned in before
+func newMentions(before, after []string) []string {
+	known := make(map[string]bool, len(before))
+	for _, email := range before {
+		known[email] = true
+	}
+	var added []string
+	for _, email := range after {
+		if !known[email] {
+			added = append(added, email)
+		}
+	}
+	return added
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+)
+
+// iCalendar (RFC 5545) helpers used by the calendar feed and import.
+
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDateTime    = "20060102T150405"
+	icalDate        = "20060102"
+	icalUIDDomain   = "task-manager-api"
+	icalLineLimit   = 75
+)
+
+// icalStatuses maps status categories to VTODO statuses
+var icalStatuses = map[string]string{
+	models.StatusCategoryTodo:  "NEEDS-ACTION",
+	models.StatusCategoryDoing: "IN-PROCESS",
+	models.StatusCategoryDone:  "COMPLETED",
+}
+
+// taskStatusFromICal maps a VTODO status back to a task status
+func taskStatusFromICal(status string) string {
+	switch strings.ToUpper(status) {
+	case "IN-PROCESS":
+		return models.StatusInProgress
+	case "COMPLETED", "CANCELLED":
+		return models.StatusCompleted
+	default:
+		return models.StatusPending
+	}
+}
+
+// taskICalUID returns the UID of a task: the imported UID if present, otherwise one derived from the task ID.
+func taskICalUID(task models.Task) string {
+	if task.ICalUID != "" {
+		return task.ICalUID
+	}
+	return fmt.Sprintf("task-%d@%s", task.ID, icalUIDDomain)
+}
+
+// taskIDFromICalUID extracts the task ID from a UID generated by taskICalUID.
+func taskIDFromICalUID(uid string) (uint, bool) {
+	if !strings.HasPrefix(uid, "task-") || !strings.HasSuffix(uid, "@"+icalUIDDomain) {
+		return 0, false
+	}
+	raw := strings.TrimSuffix(strings.TrimPrefix(uid, "task-"), "@"+icalUIDDomain)
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// icalWriter writes folded, CRLF-terminated content lines.
+type icalWriter struct {
+	w   io.Writer
+	err error
+}
+
+// line writes a single property, folding it at 75 octets.
+func (iw *icalWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	content := name + ":" + value
+
+	var b strings.Builder
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > icalLineLimit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
+
+// escapeICalText escapes a TEXT value.
+func escapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// unescapeICalText reverses escapeICalText.
+func unescapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(value)
+}
+
+// writeICalHeader starts a calendar.
+func writeICalHeader(iw *icalWriter, name string) {
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//Task Manager API//Tasks//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	iw.line("X-WR-CALNAME", escapeICalText(name))
+}
+
+// writeICalFooter ends a calendar.
+func writeICalFooter(iw *icalWriter) {
+	iw.line("END", "VCALENDAR")
+}
+
+// writeICalTodo writes a task as a VTODO component.
+func writeICalTodo(iw *icalWriter, task models.Task) {
+	iw.line("BEGIN", "VTODO")
+	writeICalCommon(iw, task)
+	iw.line("STATUS", icalStatuses[task.Category()])
+	if task.DueDate != nil {
+		iw.line("DUE", task.DueDate.UTC().Format(icalDateTimeUTC))
+	}
+	if task.Category() == models.StatusCategoryDone {
+		iw.line("COMPLETED", task.UpdatedAt.UTC().Format(icalDateTimeUTC))
+	}
+	iw.line("END", "VTODO")
+}
+
+// writeICalEvent writes a task with a due date as a VEVENT at the due time.
+func writeICalEvent(iw *icalWriter, task models.Task) {
+	iw.line("BEGIN", "VEVENT")
+	writeICalCommon(iw, task)
+	iw.line("DTSTART", task.DueDate.UTC().Format(icalDateTimeUTC))
+	iw.line("END", "VEVENT")
+}
+
+// writeICalCommon writes the properties shared by VTODO and VEVENT.
+func writeICalCommon(iw *icalWriter, task models.Task) {
+	iw.line("UID", taskICalUID(task))
+	iw.line("DTSTAMP", task.UpdatedAt.UTC().Format(icalDateTimeUTC))
+	iw.line("CREATED", task.CreatedAt.UTC().Format(icalDateTimeUTC))
+	iw.line("LAST-MODIFIED", task.UpdatedAt.UTC().Format(icalDateTimeUTC))
+	iw.line("SUMMARY", escapeICalText(task.Title))
+	if task.Description != "" {
+		iw.line("DESCRIPTION", escapeICalText(task.Description))
+	}
+}
+
+// icalProperty is a parsed content line.
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalTodo holds the VTODO properties relevant for tasks.
+type icalTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Status      string
+	Due         *time.Time
+}
+
+// parseICalTodos reads all VTODO components from an iCalendar stream.
+func parseICalTodos(r io.Reader) ([]icalTodo, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []icalTodo
+	var current *icalTodo
+	depth := 0 // Nesting inside the VTODO, e.g. VALARM
+
+	for _, line := range lines {
+		prop, ok := parseICalProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VTODO") && current == nil:
+			current = &icalTodo{}
+		case prop.Name == "BEGIN" && current != nil:
+			depth++
+		case prop.Name == "END" && current != nil && depth > 0:
+			depth--
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VTODO") && current != nil:
+			todos = append(todos, *current)
+			current = nil
+		case current != nil && depth == 0:
+			if err := current.set(prop); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("invalid ics: unterminated VTODO")
+	}
+	return todos, nil
+}
+
+// set assigns a property to the VTODO.
+func (t *icalTodo) set(prop icalProperty) error {
+	switch prop.Name {
+	case "UID":
+		t.UID = prop.Value
+	case "SUMMARY":
+		t.Summary = unescapeICalText(prop.Value)
+	case "DESCRIPTION":
+		t.Description = unescapeICalText(prop.Value)
+	case "STATUS":
+		t.Status = prop.Value
+	case "DUE":
+		due, err := parseICalTime(prop)
+		if err != nil {
+			return fmt.Errorf("invalid ics: %s", err)
+		}
+		t.Due = &due
+	}
+	return nil
+}
+
+// unfoldICalLines joins folded content lines.
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("invalid ics: missing BEGIN:VCALENDAR")
+	}
+	return lines, nil
+}
+
+// parseICalProperty splits "NAME;PARAM=VALUE:value" into its parts.
+func parseICalProperty(line string) (icalProperty, bool) {
+	colon := indexOutsideQuotes(line, ':')
+	if colon < 0 {
+		return icalProperty{}, false
+	}
+
+	head := strings.Split(line[:colon], ";")
+	prop := icalProperty{
+		Name:   strings.ToUpper(head[0]),
+		Params: map[string]string{},
+		Value:  line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, true
+}
+
+// indexOutsideQuotes finds the first separator that is not inside a quoted parameter value.
+func indexOutsideQuotes(s string, sep byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseICalTime parses DATE, UTC DATE-TIME and local DATE-TIME values (with an optional TZID).
+func parseICalTime(prop icalProperty) (time.Time, error) {
+	if prop.Params["VALUE"] == "DATE" || len(prop.Value) == len(icalDate) {
+		return time.Parse(icalDate, prop.Value)
+	}
+	if strings.HasSuffix(prop.Value, "Z") {
+		return time.Parse(icalDateTimeUTC, prop.Value)
+	}
+
+	location := time.UTC
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+	return time.ParseInLocation(icalDateTime, prop.Value, location)
+}
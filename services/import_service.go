@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// ImportService defines the interface for importing tasks from other task trackers.
+type ImportService interface {
+	Import(userID uint, source string, r io.Reader, defaultProject string, dryRun bool) (*models.ImportReport, error)
+	ForOrganization(organizationID uint) ImportService
+}
+
+type importService struct {
+	transactor  repository.Transactor
+	projectRepo repository.ProjectRepository
+	tagRepo     repository.TagRepository
+	taskRepo    repository.TaskRepository
+	taskService TaskService
+}
+
+// NewImportService creates a new instance of ImportService.
+func NewImportService(transactor repository.Transactor, projectRepo repository.ProjectRepository, tagRepo repository.TagRepository, taskRepo repository.TaskRepository, taskService TaskService) ImportService {
+	return &importService{transactor: transactor, projectRepo: projectRepo, tagRepo: tagRepo, taskRepo: taskRepo, taskService: taskService}
+}
+
+// ForOrganization returns an ImportService that imports into the organization.
+func (s *importService) ForOrganization(organizationID uint) ImportService {
+	return &importService{
+		transactor:  s.transactor,
+		projectRepo: s.projectRepo.ForOrganization(organizationID),
+		tagRepo:     s.tagRepo.ForOrganization(organizationID),
+		taskRepo:    s.taskRepo.ForOrganization(organizationID),
+		taskService: s.taskService.ForOrganization(organizationID),
+	}
+}
+
+// Import parses an export file of the given source and creates its tasks, projects and tags
+// for the user in a single transaction. Items without a project go to defaultProject if set.
+// Nothing is saved when dryRun is set or when any item is invalid.
+func (s *importService) Import(userID uint, source string, r io.Reader, defaultProject string, dryRun bool) (*models.ImportReport, error) {
+	parse, ok := importParsers[source]
+	if !ok {
+		return nil, errors.New("unsupported source")
+	}
+	data, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Items) > MaxImportRows {
+		return nil, errors.New("invalid file: too many items")
+	}
+
+	report := &models.ImportReport{Total: len(data.Items), Skipped: data.Skipped, DryRun: dryRun, Errors: []models.ImportRowError{}}
+	for i := range data.Items {
+		if data.Items[i].Project == "" {
+			data.Items[i].Project = defaultProject
+		}
+	}
+	// Tasks of existing projects are checked against the project's workflow and custom fields;
+	// projects created by the import start with the defaults
+	projects, err := s.existingProjects(userID, data.Items)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]models.Task, len(data.Items))
+	for i, item := range data.Items {
+		tasks[i] = models.Task{Title: item.Title, Description: item.Description, Status: item.Status, DueDate: item.DueDate, UserID: userID}
+		if projectID, ok := projects[item.Project]; ok {
+			tasks[i].ProjectID = &projectID
+		}
+	}
+
+	projectNames := map[string]bool{}
+	tagNames := map[string]bool{}
+	for i, err := range s.taskService.ValidateTasks(tasks) {
+		if err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		if project := data.Items[i].Project; project != "" {
+			projectNames[project] = true
+		}
+		for _, tag := range data.Items[i].Tags {
+			tagNames[tag] = true
+		}
+	}
+	report.Projects = sortedKeys(projectNames)
+	report.Tags = sortedKeys(tagNames)
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	err = s.transactor.Transaction(func(tx *gorm.DB) error {
+		if err := s.createProjects(s.projectRepo.WithTx(tx), userID, report.Projects, projects); err != nil {
+			return err
+		}
+		tags, err := s.tagRepo.WithTx(tx).FindOrCreate(userID, report.Tags)
+		if err != nil {
+			return err
+		}
+		tagsByName := make(map[string]models.Tag, len(tags))
+		for _, tag := range tags {
+			tagsByName[tag.Name] = tag
+		}
+
+		// The tasks were validated above, so they are saved as they are
+		for i, item := range data.Items {
+			if projectID, ok := projects[item.Project]; ok && tasks[i].ProjectID == nil {
+				tasks[i].ProjectID = &projectID
+			}
+			for _, name := range uniqueStrings(item.Tags) {
+				tasks[i].Tags = append(tasks[i].Tags, tagsByName[name])
+			}
+		}
+		return s.taskRepo.WithTx(tx).CreateBatch(tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Imported = len(data.Items)
+	return report, nil
+}
+
+// existingProjects maps the project names of the items to the user's projects with the same name.
+func (s *importService) existingProjects(userID uint, items []importItem) (map[string]uint, error) {
+	projects := map[string]uint{}
+	names := map[string]bool{}
+	for _, item := range items {
+		if item.Project != "" {
+			names[item.Project] = true
+		}
+	}
+	if len(names) == 0 {
+		return projects, nil
+	}
+
+	var existing []models.Project
+	if err := s.projectRepo.GetByUserID(userID, &existing); err != nil {
+		return nil, err
+	}
+	for _, project := range existing {
+		if _, ok := projects[project.Name]; !ok && names[project.Name] {
+			projects[project.Name] = project.ID
+		}
+	}
+	return projects, nil
+}
+
+// createProjects creates the named projects that are not in projects yet and adds them to it.
+func (s *importService) createProjects(projectRepo repository.ProjectRepository, userID uint, names []string, projects map[string]uint) error {
+	for _, name := range names {
+		if _, ok := projects[name]; ok {
+			continue
+		}
+		project := &models.Project{Name: name, OwnerID: userID}
+		if err := projectRepo.Create(project); err != nil {
+			return err
+		}
+		projects[name] = project.ID
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a set in alphabetical order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// uniqueStrings removes duplicates while keeping the original order.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+)
+
+// Parsers for export files of other task trackers. They only read uploaded
+// files and never call the services themselves.
+
+// Supported import sources
+const (
+	SourceTrello  = "trello"
+	SourceTodoist = "todoist"
+	SourceGitHub  = "github"
+)
+
+// importItem is a task read from an external export, before it is mapped onto our models
+type importItem struct {
+	Title       string
+	Description string
+	Status      string
+	Project     string
+	Tags        []string
+	DueDate     *time.Time
+}
+
+// importData is the parsed content of an export file
+type importData struct {
+	Items   []importItem
+	Skipped int
+}
+
+// importParsers maps each source to its parser
+var importParsers = map[string]func(r io.Reader) (*importData, error){
+	SourceTrello:  parseTrelloExport,
+	SourceTodoist: parseTodoistExport,
+	SourceGitHub:  parseGitHubExport,
+}
+
+// IsValidImportSource reports whether an importer exists for the source.
+func IsValidImportSource(source string) bool {
+	_, ok := importParsers[source]
+	return ok
+}
+
+// statusFromName guesses a task status from a list, column or section name.
+func statusFromName(name string) string {
+	lower := strings.ToLower(name)
+	for _, word := range []string{"done", "complete", "closed", "finished", "shipped"} {
+		if strings.Contains(lower, word) {
+			return models.StatusCompleted
+		}
+	}
+	for _, word := range []string{"doing", "progress", "review", "wip", "active", "started"} {
+		if strings.Contains(lower, word) {
+			return models.StatusInProgress
+		}
+	}
+	return models.StatusPending
+}
+
+// parseExternalDate accepts the date formats used by the supported exports.
+func parseExternalDate(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q", raw)
+}
+
+// flexibleID accepts IDs encoded either as JSON strings or numbers.
+type flexibleID string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = flexibleID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = flexibleID(n.String())
+	return nil
+}
+
+// flexibleBool accepts booleans encoded as JSON booleans or 0/1 numbers.
+type flexibleBool bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// trelloBoard is the relevant part of a Trello board JSON export.
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Labels []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Cards []struct {
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		IDList      string   `json:"idList"`
+		IDLabels    []string `json:"idLabels"`
+		Closed      bool     `json:"closed"`
+		Due         string   `json:"due"`
+		DueComplete bool     `json:"dueComplete"`
+	} `json:"cards"`
+}
+
+// parseTrelloExport maps a board to a project, lists to statuses and labels to tags.
+// Archived cards and cards in archived lists are skipped.
+func parseTrelloExport(r io.Reader) (*importData, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, errors.New("invalid trello export: " + err.Error())
+	}
+	if board.Name == "" && len(board.Cards) == 0 {
+		return nil, errors.New("invalid trello export: no board found")
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+	labels := make(map[string]string, len(board.Labels))
+	for _, label := range board.Labels {
+		name := label.Name
+		if name == "" {
+			name = label.Color // Trello allows labels that only have a color
+		}
+		labels[label.ID] = name
+	}
+
+	data := &importData{}
+	for _, card := range board.Cards {
+		if card.Closed || closedLists[card.IDList] {
+			data.Skipped++
+			continue
+		}
+
+		item := importItem{
+			Title:       card.Name,
+			Description: card.Desc,
+			Status:      statusFromName(lists[card.IDList]),
+			Project:     board.Name,
+		}
+		if card.DueComplete {
+			item.Status = models.StatusCompleted
+		}
+		for _, labelID := range card.IDLabels {
+			if name := labels[labelID]; name != "" {
+				item.Tags = append(item.Tags, name)
+			}
+		}
+		due, err := parseExternalDate(card.Due)
+		if err != nil {
+			return nil, errors.New("invalid trello export: " + err.Error())
+		}
+		item.DueDate = due
+
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+// todoistBackup is the relevant part of a Todoist sync backup.
+type todoistBackup struct {
+	Projects []struct {
+		ID           flexibleID   `json:"id"`
+		Name         string       `json:"name"`
+		InboxProject flexibleBool `json:"inbox_project"`
+	} `json:"projects"`
+	Sections []struct {
+		ID   flexibleID `json:"id"`
+		Name string     `json:"name"`
+	} `json:"sections"`
+	Items []struct {
+		Content     string       `json:"content"`
+		Description string       `json:"description"`
+		ProjectID   flexibleID   `json:"project_id"`
+		SectionID   flexibleID   `json:"section_id"`
+		Checked     flexibleBool `json:"checked"`
+		IsDeleted   flexibleBool `json:"is_deleted"`
+		Labels      []string     `json:"labels"`
+		Due         *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+}
+
+// parseTodoistExport maps projects to projects, sections to statuses and labels to tags.
+// Tasks in the Inbox are imported without a project; deleted tasks are skipped.
+func parseTodoistExport(r io.Reader) (*importData, error) {
+	var backup todoistBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, errors.New("invalid todoist export: " + err.Error())
+	}
+	if backup.Items == nil {
+		return nil, errors.New("invalid todoist export: no items found")
+	}
+
+	projects := make(map[flexibleID]string, len(backup.Projects))
+	for _, project := range backup.Projects {
+		if project.InboxProject || project.Name == "Inbox" {
+			continue
+		}
+		projects[project.ID] = project.Name
+	}
+	sections := make(map[flexibleID]string, len(backup.Sections))
+	for _, section := range backup.Sections {
+		sections[section.ID] = section.Name
+	}
+
+	data := &importData{}
+	for _, item := range backup.Items {
+		if item.IsDeleted {
+			data.Skipped++
+			continue
+		}
+
+		task := importItem{
+			Title:       item.Content,
+			Description: item.Description,
+			Status:      statusFromName(sections[item.SectionID]),
+			Project:     projects[item.ProjectID],
+			Tags:        item.Labels,
+		}
+		if item.Checked {
+			task.Status = models.StatusCompleted
+		}
+		if item.Due != nil {
+			due, err := parseExternalDate(item.Due.Date)
+			if err != nil {
+				return nil, errors.New("invalid todoist export: " + err.Error())
+			}
+			task.DueDate = due
+		}
+
+		data.Items = append(data.Items, task)
+	}
+	return data, nil
+}
+
+// githubIssue is the relevant part of an issue from the REST API or `gh issue list --json`.
+type githubIssue struct {
+	Number        int             `json:"number"`
+	Title         string          `json:"title"`
+	Body          string          `json:"body"`
+	State         string          `json:"state"`
+	RepositoryURL string          `json:"repository_url"`
+	PullRequest   json.RawMessage `json:"pull_request"`
+	Labels        []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		DueOn      string `json:"due_on"`
+		DueOnCamel string `json:"dueOn"`
+	} `json:"milestone"`
+}
+
+// parseGitHubExport maps the repository to a project, open/closed to statuses and labels to tags.
+// Pull requests are skipped.
+func parseGitHubExport(r io.Reader) (*importData, error) {
+	var issues []githubIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, errors.New("invalid github export: expected an array of issues")
+	}
+
+	data := &importData{}
+	for _, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			data.Skipped++
+			continue
+		}
+
+		item := importItem{
+			Title:       issue.Title,
+			Description: issue.Body,
+			Status:      models.StatusPending,
+			Project:     repositoryName(issue.RepositoryURL),
+		}
+		for _, label := range issue.Labels {
+			item.Tags = append(item.Tags, label.Name)
+			if statusFromName(label.Name) == models.StatusInProgress {
+				item.Status = models.StatusInProgress
+			}
+		}
+		if strings.EqualFold(issue.State, "closed") {
+			item.Status = models.StatusCompleted
+		}
+		if issue.Number > 0 {
+			item.Description = strings.TrimSpace(item.Description + "\n\nImported from GitHub issue #" + strconv.Itoa(issue.Number))
+		}
+		if issue.Milestone != nil {
+			dueOn := issue.Milestone.DueOn
+			if dueOn == "" {
+				dueOn = issue.Milestone.DueOnCamel
+			}
+			due, err := parseExternalDate(dueOn)
+			if err != nil {
+				return nil, errors.New("invalid github export: " + err.Error())
+			}
+			item.DueDate = due
+		}
+
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+// repositoryName turns "https://api.github.com/repos/owner/repo" into "owner/repo".
+func repositoryName(url string) string {
+	const prefix = "/repos/"
+	i := strings.Index(url, prefix)
+	if i < 0 {
+		return ""
+	}
+	return url[i+len(prefix):]
+}
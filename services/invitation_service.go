@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// InvitationTTL is how long an emailed invitation link stays valid
+const InvitationTTL = 7 * 24 * time.Hour
+
+// InvitationService defines the interface for inviting people to organizations by email.
+type InvitationService interface {
+	Invite(organizationID, actorID uint, email, role string) (*models.Invitation, error)
+	ListPending(organizationID, actorID uint) ([]models.Invitation, error)
+	Revoke(organizationID, actorID, invitationID uint) error
+	Resend(organizationID, actorID, invitationID uint) (*models.Invitation, error)
+	Lookup(token string) (*models.Invitation, error)
+	Accept(token string, userID uint) (*models.OrganizationMember, error)
+}
+
+type invitationService struct {
+	transactor repository.Transactor
+	repo       repository.InvitationRepository
+	orgRepo    repository.OrganizationRepository
+	userRepo   repository.UserRepository
+	mailer     Mailer
+	acceptURL  string
+	now        func() time.Time
+}
+
+// NewInvitationService creates a new instance of InvitationService.
+// The emailed link points at INVITATION_URL with the token as a query parameter; by default
+// that is GET /invitations/accept, which shows the invitation without signing in.
+func NewInvitationService(transactor repository.Transactor, repo repository.InvitationRepository, orgRepo repository.OrganizationRepository, userRepo repository.UserRepository, mailer Mailer) InvitationService {
+	acceptURL := os.Getenv("INVITATION_URL")
+	if acceptURL == "" {
+		acceptURL = "http://localhost:8080/invitations/accept"
+	}
+	return &invitationService{transactor: transactor, repo: repo, orgRepo: orgRepo, userRepo: userRepo, mailer: mailer, acceptURL: acceptURL, now: time.Now}
+}
+
+// Invite emails an invitation to join the organization. Owners and admins can invite
+// members; only owners can invite other owners and admins. A pending invitation for the
+// same email is replaced, so only the newest link works. The invitation is only stored if
+// the email could be sent.
+func (s *invitationService) Invite(organizationID, actorID uint, email, role string) (*models.Invitation, error) {
+	email = strings.TrimSpace(email)
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if !models.IsValidOrganizationRole(role) {
+		return nil, errors.New("invalid role: must be owner, admin or member")
+	}
+	actor, err := s.requireManager(organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.OrganizationRoleMember && actor.Role != models.OrganizationRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		existing, err := s.orgRepo.GetMember(organizationID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.New("user is already a member")
+		}
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.Invitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      hashToken(token),
+		InvitedBy:      actorID,
+		ExpiresAt:      s.now().Add(InvitationTTL),
+	}
+	err = s.transactor.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(invitation); err != nil {
+			return err
+		}
+		return s.send(invitation, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListPending returns the organization's invitations that can still be accepted.
+// Only owners and admins can see them.
+func (s *invitationService) ListPending(organizationID, actorID uint) ([]models.Invitation, error) {
+	if _, err := s.requireManager(organizationID, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPending(organizationID, s.now())
+}
+
+// Revoke deletes an invitation so its link stops working.
+func (s *invitationService) Revoke(organizationID, actorID, invitationID uint) error {
+	if _, err := s.requireInvitation(organizationID, actorID, invitationID); err != nil {
+		return err
+	}
+	return s.repo.Delete(organizationID, invitationID)
+}
+
+// Resend emails a pending invitation again with a new link and a fresh expiry;
+// the previous link stops working. If the email cannot be sent, the previous link is kept.
+func (s *invitationService) Resend(organizationID, actorID, invitationID uint) (*models.Invitation, error) {
+	invitation, err := s.requireInvitation(organizationID, actorID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invalid request: invitation was already accepted")
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	invitation.TokenHash = hashToken(token)
+	invitation.ExpiresAt = s.now().Add(InvitationTTL)
+	err = s.transactor.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Renew(invitation); err != nil {
+			return err
+		}
+		return s.send(invitation, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Lookup returns the pending invitation for a token, so that registration can check it
+// before creating the account.
+func (s *invitationService) Lookup(token string) (*models.Invitation, error) {
+	invitation, err := s.repo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || invitation.AcceptedAt != nil || !s.now().Before(invitation.ExpiresAt) {
+		return nil, errors.New("invalid or expired invitation")
+	}
+	return invitation, nil
+}
+
+// Accept adds the user to the invitation's organization with the invited role. The token
+// can only be used once, and only by the user whose email address it was sent to.
+func (s *invitationService) Accept(token string, userID uint) (*models.OrganizationMember, error) {
+	invitation, err := s.Lookup(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, errors.New("invalid invitation: it was sent to another email address")
+	}
+	existing, err := s.orgRepo.GetMember(invitation.OrganizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("user is already a member")
+	}
+
+	member := &models.OrganizationMember{OrganizationID: invitation.OrganizationID, UserID: userID, Role: invitation.Role}
+	accepted, err := s.repo.Accept(invitation, member, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, errors.New("invalid or expired invitation")
+	}
+	return member, nil
+}
+
+// send emails the invitation link.
+func (s *invitationService) send(invitation *models.Invitation, token string) error {
+	name := "an organization"
+	if organization, err := s.orgRepo.GetByID(invitation.OrganizationID); err == nil && organization != nil {
+		name = `"` + organization.Name + `"`
+	}
+	link := s.acceptURL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(Mail{
+		To:      invitation.Email,
+		Subject: "You are invited to join " + name,
+		Body: "You have been invited to join " + name + " on Task Manager as " + invitation.Role + ".\n\n" +
+			"Use this link within a week to accept; you can create an account on the way if you don't have one:\n" + link + "\n\n" +
+			"If you weren't expecting this, you can ignore this email.",
+	})
+}
+
+// requireInvitation returns an invitation of the organization the actor manages.
+func (s *invitationService) requireInvitation(organizationID, actorID, invitationID uint) (*models.Invitation, error) {
+	if _, err := s.requireManager(organizationID, actorID); err != nil {
+		return nil, err
+	}
+	invitation, err := s.repo.GetByID(organizationID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, errors.New("invitation not found")
+	}
+	return invitation, nil
+}
+
+// requireManager checks that the user is an owner or admin of the organization,
+// hiding organizations the user doesn't belong to.
+func (s *invitationService) requireManager(organizationID, userID uint) (*models.OrganizationMember, error) {
+	member, err := s.orgRepo.GetMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("organization not found")
+	}
+	if member.Role == models.OrganizationRoleMember {
+		return nil, errors.New("forbidden")
+	}
+	return member, nil
+}
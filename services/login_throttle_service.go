@@ -0,0 +1,142 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// LoginThrottleConfig controls when failed logins lock an account or a client IP
+type LoginThrottleConfig struct {
+	Window           time.Duration // Failures older than this are forgotten
+	AccountThreshold int           // Failures per account before it is locked
+	IPThreshold      int           // Failures per IP before it is locked
+	BaseLockout      time.Duration // First lockout; doubles with each further failure
+	MaxLockout       time.Duration // Upper bound for a single lockout
+}
+
+// DefaultLoginThrottleConfig locks an account after 5 and an IP after 20 failures in 15 minutes,
+// starting at one minute and doubling up to an hour.
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		Window:           15 * time.Minute,
+		AccountThreshold: 5,
+		IPThreshold:      20,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+	}
+}
+
+// LoginThrottleService defines the interface for brute-force protection of logins.
+type LoginThrottleService interface {
+	Check(email, ip string) (time.Duration, error)
+	RecordFailure(email, ip string) (time.Duration, error)
+	RecordSuccess(email string) error
+	Unlock(email, ip string) error
+}
+
+type loginThrottleService struct {
+	store  repository.LoginAttemptStore
+	config LoginThrottleConfig
+	now    func() time.Time
+}
+
+// NewLoginThrottleService creates a new instance of LoginThrottleService.
+func NewLoginThrottleService(store repository.LoginAttemptStore, config LoginThrottleConfig) LoginThrottleService {
+	return &loginThrottleService{store: store, config: config, now: time.Now}
+}
+
+// accountKey and ipKey name the counters of an account and a client address
+func accountKey(email string) string { return "account:" + strings.ToLower(strings.TrimSpace(email)) }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// Check returns how long logins for the account or IP are still locked, or zero if they are allowed.
+func (s *loginThrottleService) Check(email, ip string) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range s.keys(email, ip) {
+		attempt, err := s.store.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && attempt.LockedUntil != nil {
+			if remaining := attempt.LockedUntil.Sub(s.now()); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a failed login and locks the account or IP once its threshold is reached.
+// It returns the resulting lockout, or zero.
+func (s *loginThrottleService) RecordFailure(email, ip string) (time.Duration, error) {
+	now := s.now()
+	var wait time.Duration
+	for _, key := range s.keys(email, ip) {
+		attempt, err := s.store.Increment(key, now, s.config.Window)
+		if err != nil {
+			return 0, err
+		}
+		threshold := s.config.AccountThreshold
+		if strings.HasPrefix(key, "ip:") {
+			threshold = s.config.IPThreshold
+		}
+		lockout := s.lockout(attempt.Failures, threshold)
+		if lockout == 0 {
+			continue
+		}
+		if err := s.store.Lock(key, now.Add(lockout)); err != nil {
+			return 0, err
+		}
+		if lockout > wait {
+			wait = lockout
+		}
+	}
+	return wait, nil
+}
+
+// RecordSuccess clears the account's failures. IP counters are kept, so one valid
+// account cannot be used to reset an attacker's budget.
+func (s *loginThrottleService) RecordSuccess(email string) error {
+	if email == "" {
+		return nil
+	}
+	return s.store.Reset(accountKey(email))
+}
+
+// Unlock clears the lock and failures of an account and, if given, an IP.
+func (s *loginThrottleService) Unlock(email, ip string) error {
+	for _, key := range s.keys(email, ip) {
+		if err := s.store.Reset(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *loginThrottleService) keys(email, ip string) []string {
+	var keys []string
+	if email != "" {
+		keys = append(keys, accountKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+// lockout doubles the base lockout for every failure past the threshold, up to the maximum.
+func (s *loginThrottleService) lockout(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	lockout := s.config.BaseLockout
+	for i := threshold; i < failures && lockout < s.config.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > s.config.MaxLockout {
+		lockout = s.config.MaxLockout
+	}
+	return lockout
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mail is an email message with a plain-text body and an optional HTML alternative
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    string            // Sent as an alternative to Body when not empty
+	Headers map[string]string // Extra headers, e.g. List-Unsubscribe
+}
+
+// Mailer sends emails to users.
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// NewMailerFromEnv returns an SMTP mailer configured by SMTP_HOST, SMTP_PORT, SMTP_USER,
+// SMTP_PASSWORD and SMTP_FROM, or nil if SMTP_HOST is not set.
+func NewMailerFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@" + host
+	}
+	return NewSMTPMailer(host, port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), from)
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a new SMTPMailer. Authentication is skipped when user is empty.
+func NewSMTPMailer(host, port, user, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+	return &SMTPMailer{addr: host + ":" + port, auth: auth, from: from}
+}
+
+// Send delivers the message with STARTTLS when the server supports it.
+func (m *SMTPMailer) Send(mail Mail) error {
+	if strings.ContainsAny(mail.To+mail.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+	var msg strings.Builder
+	msg.WriteString("From: " + m.from + "\r\n" +
+		"To: " + mail.To + "\r\n" +
+		"Subject: " + mail.Subject + "\r\n")
+	names := make([]string, 0, len(mail.Headers))
+	for name := range mail.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.ContainsAny(name, ": \r\n") || strings.ContainsAny(mail.Headers[name], "\r\n") {
+			return fmt.Errorf("invalid mail header")
+		}
+		msg.WriteString(name + ": " + mail.Headers[name] + "\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	if mail.HTML == "" {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(crlf(mail.Body))
+	} else {
+		boundary, err := generateToken(16)
+		if err != nil {
+			return err
+		}
+		msg.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+		msg.WriteString("--" + boundary + "\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" + crlf(mail.Body) + "\r\n")
+		msg.WriteString("--" + boundary + "\r\nContent-Type: text/html; charset=utf-8\r\n\r\n" + crlf(mail.HTML) + "\r\n")
+		msg.WriteString("--" + boundary + "--\r\n")
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{mail.To}, []byte(msg.String()))
+}
+
+// crlf converts line endings to the CRLF that SMTP requires
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// MemoryMailer keeps sent emails in memory. It is used in tests and when SMTP is not configured.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Mail
+}
+
+// NewMemoryMailer creates a new MemoryMailer
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send records the message.
+func (m *MemoryMailer) Send(mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+// Sent returns a copy of the recorded messages.
+func (m *MemoryMailer) Sent() []Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Mail(nil), m.sent...)
+}
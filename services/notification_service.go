@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// NotificationService defines the interface for the in-app notification inbox.
+type NotificationService interface {
+	List(userID uint, filter models.NotificationFilter) (*models.NotificationPage, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) (int64, error)
+	GetPreferences(userID uint) (map[string]bool, error)
+	UpdatePreferences(userID uint, preferences map[string]bool) (map[string]bool, error)
+	Subscribe(bus EventBus)
+}
+
+type notificationService struct {
+	repo     repository.NotificationRepository
+	userRepo repository.UserRepository
+	orgRepo  repository.OrganizationRepository
+	now      func() time.Time
+}
+
+// NewNotificationService creates a new instance of NotificationService. Notifications are
+// generated from task events once the service is subscribed to an event bus.
+func NewNotificationService(repo repository.NotificationRepository, userRepo repository.UserRepository, orgRepo repository.OrganizationRepository) NotificationService {
+	return &notificationService{repo: repo, userRepo: userRepo, orgRepo: orgRepo, now: time.Now}
+}
+
+// List returns a page of the user's notifications, newest first.
+func (s *notificationService) List(userID uint, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = models.DefaultPageSize
+	}
+	return s.repo.GetPage(userID, filter)
+}
+
+// UnreadCount returns the number of unread notifications of the user.
+func (s *notificationService) UnreadCount(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+// MarkRead marks one of the user's notifications as read.
+func (s *notificationService) MarkRead(userID, id uint) error {
+	found, err := s.repo.MarkRead(userID, id, s.now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read and returns how many were unread.
+func (s *notificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID, s.now())
+}
+
+// GetPreferences returns whether each notification type is on for the user.
+func (s *notificationService) GetPreferences(userID uint) (map[string]bool, error) {
+	stored, err := s.repo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences := make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, preference := range stored {
+		if models.IsValidNotificationType(preference.Type) {
+			preferences[preference.Type] = preference.Enabled
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences turns the given notification types on or off; other types keep their setting.
+func (s *notificationService) UpdatePreferences(userID uint, preferences map[string]bool) (map[string]bool, error) {
+	for notificationType := range preferences {
+		if !models.IsValidNotificationType(notificationType) {
+			return nil, fmt.Errorf("invalid notification type: %s (must be one of %s)", notificationType, strings.Join(models.NotificationTypes, ", "))
+		}
+	}
+	if err := s.repo.SetPreferences(userID, preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID)
+}
+
+// Subscribe generates notifications from the task events published on the bus.
+func (s *notificationService) Subscribe(bus EventBus) {
+	bus.Subscribe(EventTaskAssigned, s.onAssigned)
+	bus.Subscribe(EventTaskMentioned, s.onMentioned)
+	bus.Subscribe(EventTaskUpdated, s.onUpdated)
+	bus.Subscribe(EventTaskReminder, s.onReminder)
+}
+
+// onAssigned notifies users who were assigned to a task.
+func (s *notificationService) onAssigned(event Event) error {
+	message := fmt.Sprintf("You were assigned to %q", event.Task.Title)
+	return s.notify(event, models.NotificationTaskAssigned, event.UserIDs, message)
+}
+
+// onMentioned notifies the members of the task's organization who were mentioned by email.
+// Mentions of unknown addresses and of people outside the organization are ignored.
+func (s *notificationService) onMentioned(event Event) error {
+	var userIDs []uint
+	for _, email := range event.Mentions {
+		user, err := s.userRepo.FindByEmail(email)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			continue
+		}
+		member, err := s.orgRepo.GetMember(event.OrganizationID, user.ID)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+	message := fmt.Sprintf("You were mentioned in %q", event.Task.Title)
+	return s.notify(event, models.NotificationMentioned, userIDs, message)
+}
+
+// onUpdated notifies the watchers of a task that it changed.
+func (s *notificationService) onUpdated(event Event) error {
+	message := fmt.Sprintf("%q was updated: %s", event.Task.Title, strings.Join(event.Changes, ", "))
+	return s.notify(event, models.NotificationTaskUpdated, event.UserIDs, message)
+}
+
+// onReminder notifies the creator and assignees of a task that it is due soon.
+func (s *notificationService) onReminder(event Event) error {
+	if event.Task.DueDate == nil {
+		return nil
+	}
+	message := fmt.Sprintf("%q is due at %s", event.Task.Title, event.Task.DueDate.UTC().Format(time.RFC3339))
+	return s.notify(event, models.NotificationReminder, taskParticipants(event.Task), message)
+}
+
+// notify adds a notification about the event's task to the inbox of each user, except the
+// user who caused the event and users who turned the notification type off.
+func (s *notificationService) notify(event Event, notificationType string, userIDs []uint, message string) error {
+	recipients := make([]uint, 0, len(userIDs))
+	for _, id := range uniqueIDs(userIDs) {
+		if id != 0 && id != event.ActorID {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	disabled, err := s.repo.GetDisabledUsers(recipients, notificationType)
+	if err != nil {
+		return err
+	}
+	off := make(map[uint]bool, len(disabled))
+	for _, id := range disabled {
+		off[id] = true
+	}
+
+	taskID := event.Task.ID
+	var actorID *uint
+	if event.ActorID != 0 {
+		actor := event.ActorID
+		actorID = &actor
+	}
+	notifications := make([]models.Notification, 0, len(recipients))
+	for _, id := range recipients {
+		if off[id] {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:         id,
+			OrganizationID: event.OrganizationID,
+			Type:           notificationType,
+			TaskID:         &taskID,
+			ActorID:        actorID,
+			Message:        message,
+		})
+	}
+	return s.repo.CreateBatch(notifications)
+}
+
+// taskParticipants returns the creator and the assignees of a task
+func taskParticipants(task models.Task) []uint {
+	return append([]uint{task.UserID}, assigneeIDsOf(&task)...)
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// OrganizationService defines the interface for working with organizations and their members.
+type OrganizationService interface {
+	CreateOrganization(name string, ownerID uint) (*models.Organization, error)
+	GetUserOrganizations(userID uint) ([]models.OrganizationMember, error)
+	GetOrganization(organizationID, userID uint) (*models.Organization, error)
+	Rename(organizationID, actorID uint, name string) (*models.Organization, error)
+	AddMember(organizationID, actorID uint, email, role string) (*models.OrganizationMember, error)
+	ChangeMemberRole(organizationID, actorID, memberID uint, role string) (*models.OrganizationMember, error)
+	RemoveMember(organizationID, actorID, memberID uint) error
+	ResolveMembership(userID, organizationID uint) (*models.OrganizationMember, error)
+}
+
+type organizationService struct {
+	repo     repository.OrganizationRepository
+	userRepo repository.UserRepository
+}
+
+// NewOrganizationService creates a new instance of OrganizationService.
+func NewOrganizationService(repo repository.OrganizationRepository, userRepo repository.UserRepository) OrganizationService {
+	return &organizationService{repo: repo, userRepo: userRepo}
+}
+
+// CreateOrganization creates an organization; its creator becomes the owner.
+func (s *organizationService) CreateOrganization(name string, ownerID uint) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("invalid organization: name cannot be empty")
+	}
+	organization := &models.Organization{Name: name}
+	if err := s.repo.Create(organization, ownerID); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// GetUserOrganizations returns the user's memberships with their organizations, oldest first.
+func (s *organizationService) GetUserOrganizations(userID uint) ([]models.OrganizationMember, error) {
+	return s.repo.GetMemberships(userID)
+}
+
+// GetOrganization returns an organization with its members if the user is one of them.
+func (s *organizationService) GetOrganization(organizationID, userID uint) (*models.Organization, error) {
+	if _, err := s.requireMember(organizationID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(organizationID)
+}
+
+// Rename changes the name of an organization. Only owners and admins can rename it.
+func (s *organizationService) Rename(organizationID, actorID uint, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("invalid organization: name cannot be empty")
+	}
+	if _, err := s.requireManager(organizationID, actorID); err != nil {
+		return nil, err
+	}
+	organization, err := s.repo.GetByID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	organization.Name = name
+	if err := s.repo.Update(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// AddMember adds the user with the given email to the organization. Owners and admins can
+// add members; only owners can add other owners and admins.
+func (s *organizationService) AddMember(organizationID, actorID uint, email, role string) (*models.OrganizationMember, error) {
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if !models.IsValidOrganizationRole(role) {
+		return nil, errors.New("invalid role: must be owner, admin or member")
+	}
+	actor, err := s.requireManager(organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.OrganizationRoleMember && actor.Role != models.OrganizationRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	existing, err := s.repo.GetMember(organizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("user is already a member")
+	}
+
+	member := &models.OrganizationMember{OrganizationID: organizationID, UserID: user.ID, Role: role}
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// ChangeMemberRole changes the role of a member. Only owners can change roles, and the
+// last owner cannot give up ownership.
+func (s *organizationService) ChangeMemberRole(organizationID, actorID, memberID uint, role string) (*models.OrganizationMember, error) {
+	if !models.IsValidOrganizationRole(role) {
+		return nil, errors.New("invalid role: must be owner, admin or member")
+	}
+	actor, err := s.requireMember(organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != models.OrganizationRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+	member, err := s.repo.GetMember(organizationID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("member not found")
+	}
+	if member.Role == role {
+		return member, nil
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		if err := s.requireAnotherOwner(organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateMemberRole(organizationID, memberID, role); err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember removes a member from the organization. Owners and admins can remove others,
+// but only owners can remove owners; anyone can leave unless they are the last owner.
+// Projects of the removed member are handed over to remaining members.
+func (s *organizationService) RemoveMember(organizationID, actorID, memberID uint) error {
+	actor, err := s.requireMember(organizationID, actorID)
+	if err != nil {
+		return err
+	}
+	member := actor
+	if memberID != actorID {
+		if actor.Role == models.OrganizationRoleMember {
+			return errors.New("forbidden")
+		}
+		member, err = s.repo.GetMember(organizationID, memberID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return errors.New("member not found")
+		}
+		if member.Role == models.OrganizationRoleOwner && actor.Role != models.OrganizationRoleOwner {
+			return errors.New("forbidden")
+		}
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		if err := s.requireAnotherOwner(organizationID); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(organizationID, memberID)
+}
+
+// ResolveMembership returns the user's membership in the organization, or in their oldest
+// organization when organizationID is zero. It returns nil if the user is not a member.
+func (s *organizationService) ResolveMembership(userID, organizationID uint) (*models.OrganizationMember, error) {
+	if organizationID == 0 {
+		return s.repo.GetFirstMembership(userID)
+	}
+	return s.repo.GetMember(organizationID, userID)
+}
+
+// requireMember returns the user's membership, hiding organizations the user doesn't belong to.
+func (s *organizationService) requireMember(organizationID, userID uint) (*models.OrganizationMember, error) {
+	member, err := s.repo.GetMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("organization not found")
+	}
+	return member, nil
+}
+
+// requireManager checks that the user is an owner or admin of the organization.
+func (s *organizationService) requireManager(organizationID, userID uint) (*models.OrganizationMember, error) {
+	member, err := s.requireMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == models.OrganizationRoleMember {
+		return nil, errors.New("forbidden")
+	}
+	return member, nil
+}
+
+// requireAnotherOwner checks that an owner remains when one owner steps down.
+func (s *organizationService) requireAnotherOwner(organizationID uint) error {
+	owners, err := s.repo.CountOwners(organizationID)
+	if err != nil {
+		return err
+	}
+	if owners < 2 {
+		return errors.New("invalid request: an organization needs at least one owner")
+	}
+	return nil
+}
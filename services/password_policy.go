@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/common_passwords.txt
+var bundledCommonPasswords string
+
+// bcryptMaxBytes is the input length after which bcrypt silently ignores the rest of a password
+const bcryptMaxBytes = 72
+
+// Character classes that a password policy can require
+const (
+	ClassUpper   = "upper"
+	ClassLower   = "lower"
+	ClassDigit   = "digit"
+	ClassSpecial = "special"
+)
+
+// PasswordPolicyError describes why a password was rejected
+type PasswordPolicyError struct {
+	Message string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Message
+}
+
+// PasswordPolicy checks new passwords on registration, change and reset
+type PasswordPolicy struct {
+	MinLength       int             // Minimum number of characters
+	MaxBytes        int             // Maximum length in bytes; at most bcryptMaxBytes
+	RequiredClasses []string        // Character classes that must appear, see ClassUpper etc.
+	RejectEmail     bool            // Reject passwords that contain the email or its local part
+	common          map[string]bool // Lower-cased common and breached passwords
+}
+
+// DefaultPasswordPolicy requires 8 to 72 bytes with an uppercase letter, a number and a
+// special character, and rejects the email address and the bundled common passwords.
+func DefaultPasswordPolicy() *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength:       8,
+		MaxBytes:        bcryptMaxBytes,
+		RequiredClasses: []string{ClassUpper, ClassDigit, ClassSpecial},
+		RejectEmail:     true,
+	}
+	policy.SetCommonPasswords(bundledCommonPasswords)
+	return policy
+}
+
+// NewPasswordPolicyFromEnv adjusts the default policy with PASSWORD_MIN_LENGTH,
+// PASSWORD_REQUIRED_CLASSES (comma-separated, e.g. "upper,lower,digit,special"; empty for none)
+// and PASSWORD_COMMON_LIST (a file that replaces the bundled list of common passwords).
+func NewPasswordPolicyFromEnv() (*PasswordPolicy, error) {
+	policy := DefaultPasswordPolicy()
+
+	if raw := os.Getenv("PASSWORD_MIN_LENGTH"); raw != "" {
+		length, err := strconv.Atoi(raw)
+		if err != nil || length < 1 || length > bcryptMaxBytes {
+			return nil, fmt.Errorf("PASSWORD_MIN_LENGTH must be between 1 and %d", bcryptMaxBytes)
+		}
+		policy.MinLength = length
+	}
+
+	if raw, ok := os.LookupEnv("PASSWORD_REQUIRED_CLASSES"); ok {
+		policy.RequiredClasses = nil
+		for _, class := range strings.Split(raw, ",") {
+			class = strings.TrimSpace(class)
+			if class == "" {
+				continue
+			}
+			if class != ClassUpper && class != ClassLower && class != ClassDigit && class != ClassSpecial {
+				return nil, fmt.Errorf("unknown character class %q in PASSWORD_REQUIRED_CLASSES", class)
+			}
+			policy.RequiredClasses = append(policy.RequiredClasses, class)
+		}
+	}
+
+	if path := os.Getenv("PASSWORD_COMMON_LIST"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read PASSWORD_COMMON_LIST: %w", err)
+		}
+		policy.SetCommonPasswords(string(data))
+	}
+	return policy, nil
+}
+
+// SetCommonPasswords replaces the rejected passwords with the lines of list.
+// Empty lines and lines starting with # are ignored.
+func (p *PasswordPolicy) SetCommonPasswords(list string) {
+	p.common = make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.common[strings.ToLower(line)] = true
+	}
+}
+
+// Validate returns a PasswordPolicyError if the password does not meet the policy.
+func (p *PasswordPolicy) Validate(password, email string) error {
+	if len([]rune(password)) < p.MinLength {
+		return &PasswordPolicyError{fmt.Sprintf("password must be at least %d characters long", p.MinLength)}
+	}
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		return &PasswordPolicyError{fmt.Sprintf("password must not be longer than %d bytes", p.MaxBytes)}
+	}
+
+	present := map[string]bool{}
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			present[ClassUpper] = true
+		case unicode.IsLower(char):
+			present[ClassLower] = true
+		case unicode.IsDigit(char):
+			present[ClassDigit] = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			present[ClassSpecial] = true
+		}
+	}
+	for _, class := range p.RequiredClasses {
+		if !present[class] {
+			return &PasswordPolicyError{classMessage(class)}
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if p.RejectEmail && email != "" {
+		email = strings.ToLower(email)
+		local := strings.SplitN(email, "@", 2)[0]
+		if strings.Contains(lower, email) || (len(local) >= 3 && strings.Contains(lower, local)) {
+			return &PasswordPolicyError{"password must not contain your email address"}
+		}
+	}
+	if p.common[lower] {
+		return &PasswordPolicyError{"password is too common, choose another one"}
+	}
+	return nil
+}
+
+// classMessage explains a missing character class
+func classMessage(class string) string {
+	switch class {
+	case ClassUpper:
+		return "password must contain an uppercase letter"
+	case ClassLower:
+		return "password must contain a lowercase letter"
+	case ClassDigit:
+		return "password must contain a number"
+	default:
+		return "password must contain a special character"
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordResetTTL is how long an emailed reset token stays valid
+const PasswordResetTTL = time.Hour
+
+// PasswordResetService defines the interface for the forgotten password flow.
+type PasswordResetService interface {
+	RequestReset(email string) error
+	ResetPassword(token string, newPassword string) error
+}
+
+type passwordResetService struct {
+	userRepo  repository.UserRepository
+	resetRepo repository.PasswordResetRepository
+	mailer    Mailer
+	policy    *PasswordPolicy
+	resetURL  string
+	now       func() time.Time
+}
+
+// NewPasswordResetService creates a new instance of PasswordResetService.
+// The emailed link points at PASSWORD_RESET_URL with the token as a query parameter.
+func NewPasswordResetService(userRepo repository.UserRepository, resetRepo repository.PasswordResetRepository, mailer Mailer, policy *PasswordPolicy) PasswordResetService {
+	resetURL := os.Getenv("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = "http://localhost:8080/password/reset"
+	}
+	return &passwordResetService{userRepo: userRepo, resetRepo: resetRepo, mailer: mailer, policy: policy, resetURL: resetURL, now: time.Now}
+}
+
+// RequestReset emails a reset token if a user with the email exists.
+// It succeeds silently for unknown emails so callers cannot probe for accounts.
+func (s *passwordResetService) RequestReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return err
+	}
+	reset := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: s.now().Add(PasswordResetTTL),
+	}
+	if err := s.resetRepo.Create(reset); err != nil {
+		return err
+	}
+
+	link := s.resetURL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Someone asked to reset the password of your Task Manager account.\n\n" +
+			"Use this link within an hour to choose a new password:\n" + link + "\n\n" +
+			"If it wasn't you, you can ignore this email.",
+	})
+}
+
+// ResetPassword sets a new password using a reset token. The token can only be used once,
+// and all login tokens issued before the reset stop working. A password that does not meet
+// the policy is rejected with a PasswordPolicyError and the token stays usable.
+func (s *passwordResetService) ResetPassword(token string, newPassword string) error {
+	reset, err := s.resetRepo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if reset == nil || reset.UsedAt != nil || !now.Before(reset.ExpiresAt) {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := s.userRepo.FindByID(reset.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("invalid or expired reset token")
+	}
+	if err := s.policy.Validate(newPassword, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	consumed, err := s.resetRepo.Consume(reset, string(hash), now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.New("invalid or expired reset token")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// personalDataExportVersion is increased when the layout of the export archive changes
+const personalDataExportVersion = 1
+
+// PersonalDataService defines the interface for answering data subject requests.
+type PersonalDataService interface {
+	Export(userID uint, w io.Writer) error
+	RequestErasure(userID, requestedBy uint) (*models.ErasureRecord, error)
+	RunErasures() (int, error)
+	Start(interval time.Duration)
+	GetErasureRecords() ([]models.ErasureRecord, error)
+}
+
+type personalDataService struct {
+	repo          repository.PersonalDataRepository
+	transfer      TaskTransferService
+	loginThrottle LoginThrottleService
+	now           func() time.Time
+}
+
+// NewPersonalDataService creates a new instance of PersonalDataService. Without a login
+// throttle, failed login counters of erased accounts expire on their own.
+func NewPersonalDataService(repo repository.PersonalDataRepository, transfer TaskTransferService, loginThrottle LoginThrottleService) PersonalDataService {
+	return &personalDataService{repo: repo, transfer: transfer, loginThrottle: loginThrottle, now: time.Now}
+}
+
+// exportManifest describes the files of an export archive
+type exportManifest struct {
+	Version    int       `json:"version"`
+	UserID     uint      `json:"user_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	Files      []string  `json:"files"`
+}
+
+// Export writes a ZIP archive with everything stored about the user as JSON files.
+func (s *personalDataService) Export(userID uint, w io.Writer) error {
+	data, err := s.repo.GetPersonalData(userID)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return errors.New("user not found")
+	}
+
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"user.json", data.User},
+		{"organizations.json", data.Organizations},
+		{"invitations.json", data.Invitations},
+		{"projects.json", data.Projects},
+		{"tags.json", data.Tags},
+		{"task_tags.json", data.TaskTags},
+		{"task_assignments.json", data.Assignments},
+		{"assignment_history.json", data.AssignmentLog},
+		{"watching.json", data.Watching},
+		{"saved_views.json", data.SavedViews},
+		{"api_keys.json", data.APIKeys},
+		{"calendar_feeds.json", data.CalendarFeeds},
+		{"notifications.json", data.Notifications},
+		{"notification_preferences.json", data.Preferences},
+		{"digest_settings.json", data.Digest},
+		{"audit_log.json", data.AuditEntries},
+	}
+	manifest := exportManifest{Version: personalDataExportVersion, UserID: userID, ExportedAt: s.now().UTC()}
+	for _, file := range files {
+		manifest.Files = append(manifest.Files, file.name)
+	}
+	manifest.Files = append(manifest.Files, "tasks.json")
+
+	archive := zip.NewWriter(w)
+	if err := writeJSONFile(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := writeJSONFile(archive, file.name, file.value); err != nil {
+			return err
+		}
+	}
+	// Tasks are streamed so large accounts are never loaded into memory at once
+	tasks, err := archive.Create("tasks.json")
+	if err != nil {
+		return err
+	}
+	if err := s.transfer.Export(userID, FormatJSON, tasks); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// RequestErasure disables the account and revokes its sessions right away, and queues the
+// erasure of all of the user's data for the background job. It returns the pending record,
+// the one already queued if the user's erasure was requested before.
+func (s *personalDataService) RequestErasure(userID, requestedBy uint) (*models.ErasureRecord, error) {
+	record := &models.ErasureRecord{UserID: userID, RequestedBy: requestedBy, RequestedAt: s.now()}
+	if err := s.repo.QueueErasure(record); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	log.Printf("Queued erasure of user %d, requested by user %d (record %d)", userID, requestedBy, record.ID)
+	return record, nil
+}
+
+// RunErasures deletes or pseudonymizes the data of every user with a pending erasure and
+// completes the records. Failed erasures are recorded and tried again on the next run. It
+// returns the number of completed erasures.
+func (s *personalDataService) RunErasures() (int, error) {
+	records, err := s.repo.GetPendingErasures()
+	if err != nil {
+		return 0, err
+	}
+	completed := 0
+	for i := range records {
+		record := &records[i]
+		if err := s.erase(record); err != nil {
+			log.Printf("Could not erase user %d (record %d): %v", record.UserID, record.ID, err)
+			if err := s.repo.RecordErasureFailure(record, err.Error()); err != nil {
+				return completed, err
+			}
+			continue
+		}
+		log.Printf("Erased personal data of user %d, requested by user %d (record %d)", record.UserID, record.RequestedBy, record.ID)
+		completed++
+	}
+	return completed, nil
+}
+
+// Start runs pending erasures every interval in the background.
+func (s *personalDataService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.RunErasures(); err != nil {
+				log.Printf("Could not run erasures: %v", err)
+			}
+		}
+	}()
+}
+
+// erase deletes or pseudonymizes all of the user's data and completes the record.
+func (s *personalDataService) erase(record *models.ErasureRecord) error {
+	data, err := s.repo.GetPersonalData(record.UserID)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return errors.New("user not found")
+	}
+
+	// Failed login counters are keyed by email address
+	if s.loginThrottle != nil {
+		if err := s.loginThrottle.Unlock(data.User.Email, ""); err != nil {
+			return err
+		}
+	}
+
+	completedAt := s.now()
+	record.CompletedAt = &completedAt
+	if err := s.repo.Erase(record); err != nil {
+		record.CompletedAt = nil
+		return err
+	}
+	return nil
+}
+
+// GetErasureRecords returns the records of all erasures, pending ones included, newest first.
+func (s *personalDataService) GetErasureRecords() ([]models.ErasureRecord, error) {
+	return s.repo.GetErasureRecords()
+}
+
+// writeJSONFile adds an indented JSON file to the archive.
+func writeJSONFile(archive *zip.Writer, name string, value interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
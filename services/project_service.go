@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// ProjectService defines the interface for working with projects and their members.
+type ProjectService interface {
+	CreateProject(project *models.Project) error
+	GetProject(id, userID uint) (*models.Project, error)
+	GetUserProjects(userID uint) ([]models.Project, error)
+	AddMember(projectID, actorID uint, email, role string) (*models.ProjectMember, error)
+	RemoveMember(projectID, actorID, memberID uint) error
+	ForOrganization(organizationID uint) ProjectService
+}
+
+type projectService struct {
+	repo           repository.ProjectRepository
+	userRepo       repository.UserRepository
+	orgRepo        repository.OrganizationRepository
+	organizationID uint
+}
+
+// NewProjectService creates a new instance of ProjectService.
+func NewProjectService(repo repository.ProjectRepository, userRepo repository.UserRepository, orgRepo repository.OrganizationRepository) ProjectService {
+	return &projectService{repo: repo, userRepo: userRepo, orgRepo: orgRepo}
+}
+
+// ForOrganization returns a ProjectService restricted to the organization's projects.
+// Only members of the organization can be added to its projects.
+func (s *projectService) ForOrganization(organizationID uint) ProjectService {
+	return &projectService{
+		repo:           s.repo.ForOrganization(organizationID),
+		userRepo:       s.userRepo,
+		orgRepo:        s.orgRepo,
+		organizationID: organizationID,
+	}
+}
+
+// CreateProject validates and saves a project; the creator becomes its owner.
+func (s *projectService) CreateProject(project *models.Project) error {
+	if project.Name == "" {
+		return errors.New("project name cannot be empty")
+	}
+	return s.repo.Create(project)
+}
+
+// GetProject returns a project if the user is one of its members.
+func (s *projectService) GetProject(id, userID uint) (*models.Project, error) {
+	project, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range project.Members {
+		if member.UserID == userID {
+			return project, nil
+		}
+	}
+	return nil, errors.New("project not found") // Hide projects the user doesn't belong to
+}
+
+// GetUserProjects returns all projects the user is a member of.
+func (s *projectService) GetUserProjects(userID uint) ([]models.Project, error) {
+	projects := []models.Project{}
+	if err := s.repo.GetByUserID(userID, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// AddMember adds the user with the given email to the project. Only the owner can add members.
+func (s *projectService) AddMember(projectID, actorID uint, email, role string) (*models.ProjectMember, error) {
+	if err := s.requireOwner(projectID, actorID); err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		role = models.ProjectRoleMember
+	}
+	if role != models.ProjectRoleMember {
+		return nil, errors.New("invalid role")
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	membership, err := s.orgRepo.GetMember(s.organizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, errors.New("user is not a member of this organization")
+	}
+
+	existing, err := s.repo.GetMember(projectID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("user is already a member")
+	}
+
+	member := &models.ProjectMember{ProjectID: projectID, UserID: user.ID, Role: role}
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member from the project. Only the owner can remove members.
+func (s *projectService) RemoveMember(projectID, actorID, memberID uint) error {
+	if err := s.requireOwner(projectID, actorID); err != nil {
+		return err
+	}
+	if memberID == actorID {
+		return errors.New("owner cannot be removed")
+	}
+	return s.repo.RemoveMember(projectID, memberID)
+}
+
+// requireOwner checks that the actor owns the project.
+func (s *projectService) requireOwner(projectID, actorID uint) error {
+	member, err := s.repo.GetMember(projectID, actorID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.New("project not found")
+	}
+	if member.Role != models.ProjectRoleOwner {
+		return errors.New("forbidden")
+	}
+	return nil
+}
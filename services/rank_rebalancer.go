@@ -0,0 +1,44 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// RankRebalancer keeps task ranks short. Moves change a single rank, which grows a digit
+// whenever a task is placed between two close neighbours; rebalancing respaces the lists.
+type RankRebalancer interface {
+	Rebalance() (int, error)
+	Start(interval time.Duration)
+}
+
+type rankRebalancer struct {
+	repo repository.TaskRepository
+}
+
+// NewRankRebalancer creates a new instance of RankRebalancer. The repository must see the
+// tasks of all organizations.
+func NewRankRebalancer(repo repository.TaskRepository) RankRebalancer {
+	return &rankRebalancer{repo: repo}
+}
+
+// Rebalance respaces the lists with long ranks or unranked tasks and returns how many lists
+// it rebalanced.
+func (s *rankRebalancer) Rebalance() (int, error) {
+	return s.repo.RebalanceLongRanks()
+}
+
+// Start rebalances ranks every interval in the background.
+func (s *rankRebalancer) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.Rebalance(); err != nil {
+				log.Printf("Could not rebalance task ranks: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// DefaultReminderLead is how long before the due date reminders are sent
+const DefaultReminderLead = time.Hour
+
+// ReminderService publishes reminders for tasks that are due soon.
+type ReminderService interface {
+	SendDueReminders(now time.Time) (int, error)
+	Start(interval time.Duration)
+}
+
+type reminderService struct {
+	repo   repository.TaskRepository
+	events EventBus
+	lead   time.Duration
+}
+
+// NewReminderService creates a new instance of ReminderService. The repository must see the
+// tasks of all organizations. REMINDER_LEAD (e.g. "30m") overrides DefaultReminderLead.
+func NewReminderService(repo repository.TaskRepository, events EventBus) ReminderService {
+	lead := DefaultReminderLead
+	if raw := os.Getenv("REMINDER_LEAD"); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			lead = parsed
+		} else {
+			log.Printf("Ignoring invalid REMINDER_LEAD %q", raw)
+		}
+	}
+	return &reminderService{repo: repo, events: events, lead: lead}
+}
+
+// SendDueReminders publishes a reminder for every open task that becomes due within the lead
+// time and returns how many were sent. Each task is reminded about once per due date.
+func (s *reminderService) SendDueReminders(now time.Time) (int, error) {
+	tasks, err := s.repo.GetDueForReminder(now, now.Add(s.lead))
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	// Mark first so that a slow subscriber never causes a second reminder
+	if err := s.repo.MarkReminded(ids, now); err != nil {
+		return 0, err
+	}
+	for _, task := range tasks {
+		s.events.Publish(Event{Type: EventTaskReminder, OrganizationID: task.OrganizationID, Task: task})
+	}
+	return len(tasks), nil
+}
+
+// Start checks for due tasks every interval in the background.
+func (s *reminderService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if _, err := s.SendDueReminders(now); err != nil {
+				log.Printf("Could not send due date reminders: %v", err)
+			}
+		}
+	}()
+}
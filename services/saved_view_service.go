@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// SavedViewService defines the interface for working with saved task views.
+type SavedViewService interface {
+	CreateView(view *models.SavedView) error
+	GetView(id, userID uint) (*models.SavedView, error)
+	GetUserViews(userID uint) ([]models.SavedView, error)
+	UpdateView(view *models.SavedView, userID uint) error
+	DeleteView(id, userID uint) error
+	GetViewTasks(id, userID uint, page, pageSize int) (*models.TaskPage, error)
+	SetDefaultView(userID uint, viewID *uint) error
+	GetDefaultFilter(userID uint) (*models.TaskFilter, error)
+	ForOrganization(organizationID uint) SavedViewService
+}
+
+type savedViewService struct {
+	repo        repository.SavedViewRepository
+	projectRepo repository.ProjectRepository
+	userRepo    repository.UserRepository
+	taskService TaskService
+}
+
+// NewSavedViewService creates a new instance of SavedViewService.
+func NewSavedViewService(repo repository.SavedViewRepository, projectRepo repository.ProjectRepository, userRepo repository.UserRepository, taskService TaskService) SavedViewService {
+	return &savedViewService{repo: repo, projectRepo: projectRepo, userRepo: userRepo, taskService: taskService}
+}
+
+// ForOrganization returns a SavedViewService whose views show the organization's tasks.
+// Views themselves belong to their user, not to an organization, but are only shared
+// through the organization's projects.
+func (s *savedViewService) ForOrganization(organizationID uint) SavedViewService {
+	return &savedViewService{
+		repo:        s.repo.ForOrganization(organizationID),
+		projectRepo: s.projectRepo.ForOrganization(organizationID),
+		userRepo:    s.userRepo,
+		taskService: s.taskService.ForOrganization(organizationID),
+	}
+}
+
+// CreateView validates the view query and sharing settings before saving.
+func (s *savedViewService) CreateView(view *models.SavedView) error {
+	if err := s.validate(view, view.UserID); err != nil {
+		return err
+	}
+	return s.repo.Create(view)
+}
+
+// GetView returns a view owned by the user or shared with one of the user's projects.
+func (s *savedViewService) GetView(id, userID uint) (*models.SavedView, error) {
+	view, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("view not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if view.UserID == userID {
+		return view, nil
+	}
+	if view.ProjectID != nil {
+		member, err := s.projectRepo.GetMember(*view.ProjectID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return view, nil
+		}
+	}
+	return nil, errors.New("view not found")
+}
+
+// GetUserViews returns the user's own views and views shared with the user's projects.
+func (s *savedViewService) GetUserViews(userID uint) ([]models.SavedView, error) {
+	views := []models.SavedView{}
+	if err := s.repo.GetVisibleToUser(userID, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// UpdateView lets the creator change the name, query and sharing of a view.
+func (s *savedViewService) UpdateView(view *models.SavedView, userID uint) error {
+	existingView, err := s.GetView(view.ID, userID)
+	if err != nil {
+		return err
+	}
+	if existingView.UserID != userID {
+		return errors.New("forbidden")
+	}
+	if err := s.validate(view, userID); err != nil {
+		return err
+	}
+
+	existingView.Name = view.Name
+	existingView.Query = view.Query
+	existingView.ProjectID = view.ProjectID
+
+	if err := s.repo.Update(existingView); err != nil {
+		return err
+	}
+	*view = *existingView
+	return nil
+}
+
+// DeleteView lets the creator delete a view.
+func (s *savedViewService) DeleteView(id, userID uint) error {
+	view, err := s.GetView(id, userID)
+	if err != nil {
+		return err
+	}
+	if view.UserID != userID {
+		return errors.New("forbidden")
+	}
+	return s.repo.Delete(id)
+}
+
+// GetViewTasks runs the view query against the user's tasks.
+func (s *savedViewService) GetViewTasks(id, userID uint, page, pageSize int) (*models.TaskPage, error) {
+	view, err := s.GetView(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseViewQuery(view.Query)
+	if err != nil {
+		return nil, err
+	}
+	filter.Page = page
+	filter.PageSize = pageSize
+
+	tasks, err := s.taskService.ListTasks(userID, filter)
+	if err != nil && strings.HasPrefix(err.Error(), "invalid") {
+		// Custom fields used by the query may have been removed from the project since
+		return nil, errors.New("invalid view query: " + err.Error())
+	}
+	return tasks, err
+}
+
+// SetDefaultView marks a visible view as the user's default task list, or clears it when viewID is nil.
+func (s *savedViewService) SetDefaultView(userID uint, viewID *uint) error {
+	if viewID != nil {
+		if _, err := s.GetView(*viewID, userID); err != nil {
+			return err
+		}
+	}
+	return s.userRepo.SetDefaultView(userID, viewID)
+}
+
+// GetDefaultFilter returns the filter of the user's default view, or nil if none is set.
+func (s *savedViewService) GetDefaultFilter(userID uint) (*models.TaskFilter, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DefaultViewID == nil {
+		return nil, nil
+	}
+
+	view, err := s.GetView(*user.DefaultViewID, userID)
+	if err != nil {
+		// The view may have been unshared since it was chosen; fall back to the plain list
+		if err.Error() == "view not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	filter, err := parseViewQuery(view.Query)
+	if err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// validate checks the view name, query and project sharing.
+func (s *savedViewService) validate(view *models.SavedView, userID uint) error {
+	if view.Name == "" {
+		return errors.New("view name cannot be empty")
+	}
+	if _, err := parseViewQuery(view.Query); err != nil {
+		return err
+	}
+	if view.ProjectID != nil {
+		member, err := s.projectRepo.GetMember(*view.ProjectID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return errors.New("forbidden")
+		}
+	}
+	return nil
+}
+
+// parseViewQuery turns a stored view query into a task filter.
+func parseViewQuery(query string) (models.TaskFilter, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return models.TaskFilter{}, errors.New("invalid view query")
+	}
+	filter, err := models.ParseTaskFilter(values)
+	if err != nil {
+		return filter, errors.New("invalid view query: " + err.Error())
+	}
+	return filter, nil
+}
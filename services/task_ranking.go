@@ -0,0 +1,96 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+)
+
+// Points of the scoring function used by GET /tasks/next
+var priorityPoints = map[string]int{
+	models.PriorityP0: 40,
+	models.PriorityP1: 30,
+	models.PriorityP2: 20,
+	models.PriorityP3: 10,
+	models.PriorityP4: 0,
+}
+
+const (
+	overduePoints     = 40 // Due date in the past
+	dueTodayPoints    = 30 // Due within 24 hours
+	dueSoonPoints     = 20 // Due within 3 days
+	dueThisWeekPoints = 10 // Due within 7 days
+	importantPoints   = 15 // Eisenhower importance
+	urgentPoints      = 10 // Eisenhower urgency
+	blockingPoints    = 5  // For each open task waiting on this one
+	maxBlockingScore  = 20 // Cap of the points for blocking other tasks
+)
+
+// ScoreTask scores an open task for GET /tasks/next; higher scores come first. The score is
+// the sum of:
+//
+//   - priority: P0 40, P1 30, P2 20, P3 10, P4 0 (tasks without a priority count as P2),
+//   - due date: overdue 40, due within 24 hours 30, within 3 days 20, within 7 days 10,
+//     later or without a due date 0,
+//   - Eisenhower matrix: 15 if important, 10 if urgent,
+//   - blockers: 5 for each open task that waits on this one, at most 20.
+func ScoreTask(task models.Task, blocks int, now time.Time) models.RankedTask {
+	priority := task.Priority
+	if priority == "" {
+		priority = models.DefaultPriority
+	}
+	ranked := models.RankedTask{
+		Task:          task,
+		PriorityScore: priorityPoints[priority],
+		Quadrant:      task.Quadrant(),
+		Blocks:        blocks,
+		BlockingScore: min(blocks*blockingPoints, maxBlockingScore),
+	}
+
+	if task.DueDate != nil {
+		switch until := task.DueDate.Sub(now); {
+		case until < 0:
+			ranked.DueScore = overduePoints
+		case until <= 24*time.Hour:
+			ranked.DueScore = dueTodayPoints
+		case until <= 3*24*time.Hour:
+			ranked.DueScore = dueSoonPoints
+		case until <= 7*24*time.Hour:
+			ranked.DueScore = dueThisWeekPoints
+		}
+	}
+	if task.Important {
+		ranked.EisenhowerScore += importantPoints
+	}
+	if task.Urgent {
+		ranked.EisenhowerScore += urgentPoints
+	}
+
+	ranked.Score = ranked.PriorityScore + ranked.DueScore + ranked.EisenhowerScore + ranked.BlockingScore
+	return ranked
+}
+
+// sortRankedTasks orders tasks by score. Ties go to the earlier due date, then to the
+// smaller effort estimate (unestimated tasks last), then to the older task.
+func sortRankedTasks(tasks []models.RankedTask) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !sameTime(a.Task.DueDate, b.Task.DueDate) {
+			if a.Task.DueDate == nil || b.Task.DueDate == nil {
+				return b.Task.DueDate == nil
+			}
+			return a.Task.DueDate.Before(*b.Task.DueDate)
+		}
+		if a.Task.EffortMinutes != b.Task.EffortMinutes {
+			if a.Task.EffortMinutes == 0 || b.Task.EffortMinutes == 0 {
+				return b.Task.EffortMinutes == 0
+			}
+			return a.Task.EffortMinutes < b.Task.EffortMinutes
+		}
+		return a.Task.ID < b.Task.ID
+	})
+}
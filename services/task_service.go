@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/EmelinDanila/task-manager-api/repository"
@@ -11,30 +14,142 @@ import (
 // TaskService defines the interface for working with tasks.
 type TaskService interface {
 	CreateTask(task *models.Task) error
+	ValidateTask(task *models.Task) error
+	ValidateTasks(tasks []models.Task) []error
 	GetTaskByID(id, userID uint) (*models.Task, error)
 	GetUserTasks(userID uint) ([]models.Task, error)
-	UpdateTask(task *models.Task, userID uint) error
+	ListTasks(userID uint, filter models.TaskFilter) (*models.TaskPage, error)
+	UpdateTask(taskID, userID uint, request models.TaskUpdateRequest) (*models.Task, error)
+	UpdateTasks(userID uint, updates []TaskUpdate) (int, error)
+	ValidateUpdate(taskID, userID uint, request models.TaskUpdateRequest) (*models.Task, error)
 	DeleteTask(id, userID uint) error
+	SetAssignees(taskID, userID uint, assigneeIDs []uint) (*models.Task, error)
+	GetAssignmentHistory(taskID, userID uint) ([]models.TaskAssignmentChange, error)
+	ListAssigned(userID uint, filter models.TaskFilter) (*models.TaskPage, error)
+	WatchTask(taskID, userID uint) error
+	UnwatchTask(taskID, userID uint) error
+	ListWatching(userID uint, filter models.TaskFilter) (*models.TaskPage, error)
+	SetBlockers(taskID, userID uint, blockerIDs []uint) (*models.Task, error)
+	MoveTask(taskID, userID uint, request models.TaskMoveRequest) (*models.Task, error)
+	NextTasks(userID uint, limit int) ([]models.RankedTask, error)
+	WithTx(tx *gorm.DB) TaskService
+	ForOrganization(organizationID uint) TaskService
 }
 
 type taskService struct {
-	repo repository.TaskRepository
+	repo           repository.TaskRepository
+	projectRepo    repository.ProjectRepository
+	orgRepo        repository.OrganizationRepository
+	events         EventBus
+	organizationID uint
 }
 
-// NewTaskService creates a new instance of TaskService.
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+// NewTaskService creates a new instance of TaskService. Assignments, mentions and updates
+// are published on the event bus, if there is one.
+func NewTaskService(repo repository.TaskRepository, projectRepo repository.ProjectRepository, orgRepo repository.OrganizationRepository, events EventBus) TaskService {
+	return &taskService{repo: repo, projectRepo: projectRepo, orgRepo: orgRepo, events: events}
+}
+
+// WithTx returns a TaskService whose repositories run inside the given transaction.
+func (s *taskService) WithTx(tx *gorm.DB) TaskService {
+	return &taskService{
+		repo:           s.repo.WithTx(tx),
+		projectRepo:    s.projectRepo.WithTx(tx),
+		orgRepo:        s.orgRepo,
+		events:         s.events,
+		organizationID: s.organizationID,
+	}
+}
+
+// ForOrganization returns a TaskService restricted to the organization's tasks and projects.
+// Tasks outside projects can be assigned to members of the organization.
+func (s *taskService) ForOrganization(organizationID uint) TaskService {
+	return &taskService{
+		repo:           s.repo.ForOrganization(organizationID),
+		projectRepo:    s.projectRepo.ForOrganization(organizationID),
+		orgRepo:        s.orgRepo,
+		events:         s.events,
+		organizationID: organizationID,
+	}
 }
 
 // CreateTask ensures task belongs to a user before saving.
 func (s *taskService) CreateTask(task *models.Task) error {
+	if err := s.ValidateTask(task); err != nil {
+		return err
+	}
+	if err := s.repo.Create(task); err != nil {
+		return err
+	}
+
+	s.publish(Event{Type: EventTaskAssigned, Task: *task, ActorID: task.UserID, UserIDs: assigneeIDsOf(task)})
+	s.publish(Event{Type: EventTaskMentioned, Task: *task, ActorID: task.UserID, Mentions: mentionedEmails(task.Title, task.Description)})
+	return nil
+}
+
+// ValidateTasks checks new tasks like ValidateTask, looking each project's workflow and custom
+// fields up once. It returns an error for every task, nil for the valid ones.
+func (s *taskService) ValidateTasks(tasks []models.Task) []error {
+	workflows, fields := workflowCache{}, customFieldCache{}
+	errs := make([]error, len(tasks))
+	for i := range tasks {
+		errs[i] = s.validateTask(&tasks[i], workflows, fields)
+	}
+	return errs
+}
+
+// ValidateTask checks a new task without saving it and sets up its assignees from AssigneeIDs.
+// Tasks without a status start in the first status of their project's workflow.
+func (s *taskService) ValidateTask(task *models.Task) error {
+	return s.validateTask(task, workflowCache{}, customFieldCache{})
+}
+
+// validateTask validates a new task, looking its project's workflow and custom fields up in
+// the caches.
+func (s *taskService) validateTask(task *models.Task, workflows workflowCache, customFields customFieldCache) error {
 	if task.Title == "" {
 		return errors.New("task title cannot be empty")
 	}
-	return s.repo.Create(task)
+	if err := validatePlanning(task); err != nil {
+		return err
+	}
+	task.BlockedBy = nil // Blockers are set with SetBlockers
+	if err := s.checkProjectMembership(task.ProjectID, task.UserID); err != nil {
+		return err
+	}
+	workflow, err := workflows.load(s.projectRepo, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	status, ok := workflow.Initial(), true
+	if task.Status != "" {
+		status, ok = workflow.Resolve(task.Status)
+	}
+	if !ok {
+		return errors.New("invalid task status")
+	}
+	task.Status, task.StatusCategory = status.Name, status.Category
+
+	fields, err := customFields.load(s.projectRepo, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if task.CustomFields, err = s.fieldValues(task.ProjectID, fields, nil, task.CustomFields, true); err != nil {
+		return err
+	}
+
+	assigneeIDs := uniqueIDs(task.AssigneeIDs)
+	if err := s.checkAssignees(task.ProjectID, assigneeIDs); err != nil {
+		return err
+	}
+	task.Assignees = nil
+	for _, id := range assigneeIDs {
+		task.Assignees = append(task.Assignees, models.TaskAssignee{UserID: id, AssignedBy: task.UserID})
+	}
+	return nil
 }
 
-// GetTaskByID ensures user can only retrieve their own tasks.
+// GetTaskByID ensures user can only retrieve tasks they created, are assigned to or watch.
 func (s *taskService) GetTaskByID(taskID, userID uint) (*models.Task, error) {
 	task := &models.Task{}
 	err := s.repo.GetByIDAndUserID(taskID, userID, task)
@@ -61,24 +176,215 @@ func (s *taskService) GetUserTasks(userID uint) ([]models.Task, error) {
 	return tasks, nil
 }
 
-// UpdateTask checks if the user owns the task before updating.
-func (s *taskService) UpdateTask(task *models.Task, userID uint) error {
-	existingTask, err := s.GetTaskByID(task.ID, userID)
+// ListTasks returns one page of the tasks the user created or is assigned to that match the filter.
+func (s *taskService) ListTasks(userID uint, filter models.TaskFilter) (*models.TaskPage, error) {
+	filter.Normalize()
+	if err := s.resolveFieldFilters(&filter); err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{}
+	total, err := s.repo.GetFiltered(userID, filter, &tasks)
 	if err != nil {
-		return err // Уже содержит "task not found"
+		return nil, err
 	}
 
-	// Проверяем, владеет ли пользователь задачей
-	if existingTask.UserID != userID {
-		return errors.New("forbidden") // 403 Forbidden
+	return &models.TaskPage{
+		Tasks:    tasks,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+// ListAssigned returns one page of the tasks assigned to the user that match the filter.
+func (s *taskService) ListAssigned(userID uint, filter models.TaskFilter) (*models.TaskPage, error) {
+	filter.AssigneeID = &userID
+	return s.ListTasks(userID, filter)
+}
+
+// UpdateTask checks if the user created or is assigned to the task before updating. Fields the
+// request leaves out keep their current value.
+func (s *taskService) UpdateTask(taskID, userID uint, request models.TaskUpdateRequest) (*models.Task, error) {
+	update, err := s.saveUpdate(taskID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publishUpdate(userID, update); err != nil {
+		return nil, err
+	}
+	return update.task, nil
+}
+
+// TaskUpdate is the update of one task in UpdateTasks
+type TaskUpdate struct {
+	TaskID  uint
+	Request models.TaskUpdateRequest
+}
+
+// UpdateTasks runs the checks of UpdateTask on several updates and saves them in order, so each
+// is checked against the ones saved before it. Events are published once every update is saved.
+// It stops at the first update that fails and returns its index; use WithTx to save all or none.
+func (s *taskService) UpdateTasks(userID uint, updates []TaskUpdate) (int, error) {
+	saved := make([]*taskUpdate, 0, len(updates))
+	for i, update := range updates {
+		checked, err := s.saveUpdate(update.TaskID, userID, update.Request)
+		if err != nil {
+			return i, err
+		}
+		saved = append(saved, checked)
+	}
+	for _, update := range saved {
+		if err := s.publishUpdate(userID, update); err != nil {
+			return len(updates), err
+		}
+	}
+	return len(updates), nil
+}
+
+// ValidateUpdate runs the checks of UpdateTask without saving and returns the updated task.
+func (s *taskService) ValidateUpdate(taskID, userID uint, request models.TaskUpdateRequest) (*models.Task, error) {
+	update, err := s.prepareUpdate(taskID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	return update.task, nil
+}
+
+// saveUpdate checks an update and saves the updated task
+func (s *taskService) saveUpdate(taskID, userID uint, request models.TaskUpdateRequest) (*taskUpdate, error) {
+	update, err := s.prepareUpdate(taskID, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	err = s.saveInList(update.task.ProjectID, update.task.Status, update.task.ID, update.checkWIP, func(repo repository.TaskRepository) error {
+		return repo.Update(update.task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// saveInList runs write, which puts a task into the list of the project and status. With
+// checkWIP, the list is locked and its WIP limit checked again first, so concurrent moves
+// cannot both take the last place.
+func (s *taskService) saveInList(projectID *uint, status string, taskID uint, checkWIP bool, write func(repo repository.TaskRepository) error) error {
+	if !checkWIP || projectID == nil {
+		return write(s.repo)
+	}
+	limited, err := s.hasWIPLimit(*projectID, status)
+	if err != nil {
+		return err
+	}
+	if !limited {
+		return write(s.repo)
+	}
+	return s.repo.LockLists(*projectID, func(repo repository.TaskRepository) error {
+		locked := *s
+		locked.repo = repo
+		if err := locked.checkWIPLimit(projectID, status, taskID, false); err != nil {
+			return err
+		}
+		return write(repo)
+	})
+}
+
+// publishUpdate notifies the watchers of a saved update and the users it newly mentions
+func (s *taskService) publishUpdate(userID uint, update *taskUpdate) error {
+	task := update.task
+	if s.events != nil && len(update.changes) > 0 {
+		watcherIDs, err := s.repo.GetWatcherIDs(task.ID)
+		if err != nil {
+			return err
+		}
+		s.publish(Event{Type: EventTaskUpdated, Task: *task, ActorID: userID, UserIDs: watcherIDs, Changes: update.changes})
+	}
+	mentions := newMentions(update.mentionsBefore, mentionedEmails(task.Title, task.Description))
+	s.publish(Event{Type: EventTaskMentioned, Task: *task, ActorID: userID, Mentions: mentions})
+	return nil
+}
+
+// taskUpdate is a checked update that is ready to be saved
+type taskUpdate struct {
+	task           *models.Task
+	changes        []string // Fields the update changes, for watchers
+	mentionsBefore []string // Emails mentioned before the update
+	checkWIP       bool     // The task enters a list whose WIP limit applies
+}
+
+// prepareUpdate checks an update of a task and applies it to the stored task, including a new
+// rank if the task changes lists.
+func (s *taskService) prepareUpdate(taskID, userID uint, request models.TaskUpdateRequest) (*taskUpdate, error) {
+	existingTask, err := s.GetTaskByID(taskID, userID)
+	if err != nil {
+		return nil, err // Уже содержит "task not found"
+	}
+
+	// Проверяем, работает ли пользователь над задачей
+	if !canEdit(existingTask, userID) {
+		return nil, errors.New("forbidden") // 403 Forbidden
+	}
+
+	if request.Title != nil && *request.Title == "" {
+		return nil, errors.New("invalid title: cannot be empty")
+	}
+	if request.Priority != nil && *request.Priority == "" {
+		return nil, errors.New("invalid priority: must be one of P0, P1, P2, P3, P4")
+	}
+	updated := request.Apply(*existingTask)
+	task := &updated
+	if err := validatePlanning(task); err != nil {
+		return nil, err
+	}
+	if !sameProject(existingTask.ProjectID, task.ProjectID) {
+		if err := s.checkProjectMembership(task.ProjectID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.resolveStatus(existingTask, task); err != nil {
+		return nil, err
+	}
+	if task.CustomFields, err = s.updatedFieldValues(existingTask, task); err != nil {
+		return nil, err
+	}
+
+	changes := changedFields(existingTask, task)
+	changesList := existingTask.Status != task.Status || !sameProject(existingTask.ProjectID, task.ProjectID)
+	mentionsBefore := mentionedEmails(existingTask.Title, existingTask.Description)
+	if !sameTime(existingTask.DueDate, task.DueDate) {
+		existingTask.RemindedAt = nil // Remind again before the new due date
 	}
 
 	// Обновляем только разрешенные поля
 	existingTask.Title = task.Title
 	existingTask.Description = task.Description
 	existingTask.Status = task.Status
+	existingTask.StatusCategory = task.StatusCategory
+	existingTask.DueDate = task.DueDate
+	existingTask.Priority = task.Priority
+	existingTask.Important = task.Important
+	existingTask.Urgent = task.Urgent
+	existingTask.EffortMinutes = task.EffortMinutes
+	existingTask.CustomFields = task.CustomFields
 
-	return s.repo.Update(existingTask)
+	if !sameProject(existingTask.ProjectID, task.ProjectID) {
+		if err := s.checkAssignees(task.ProjectID, assigneeIDsOf(existingTask)); err != nil {
+			return nil, err
+		}
+		existingTask.ProjectID = task.ProjectID
+	}
+	if changesList {
+		if err := s.checkWIPLimit(existingTask.ProjectID, existingTask.Status, existingTask.ID, task.OverrideWIP); err != nil {
+			return nil, err
+		}
+		// The task goes to the end of its new project and status list
+		if err := s.rankLast(existingTask); err != nil {
+			return nil, err
+		}
+	}
+
+	return &taskUpdate{task: existingTask, changes: changes, mentionsBefore: mentionsBefore, checkWIP: changesList && !task.OverrideWIP}, nil
 }
 
 // DeleteTask ensures only the owner can delete a task.
@@ -94,3 +400,670 @@ func (s *taskService) DeleteTask(id, userID uint) error {
 
 	return s.repo.Delete(task.ID)
 }
+
+// SetAssignees replaces the assignees of a task. The creator and the current assignees can
+// reassign it; the change is recorded in the task's assignment history.
+func (s *taskService) SetAssignees(taskID, userID uint, assigneeIDs []uint) (*models.Task, error) {
+	task, err := s.GetTaskByID(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(task, userID) {
+		return nil, errors.New("forbidden")
+	}
+	assigneeIDs = uniqueIDs(assigneeIDs)
+	if err := s.checkAssignees(task.ProjectID, assigneeIDs); err != nil {
+		return nil, err
+	}
+	previous := assigneeIDsOf(task)
+	if err := s.repo.SetAssignees(task, assigneeIDs, userID); err != nil {
+		return nil, err
+	}
+
+	s.publish(Event{Type: EventTaskAssigned, Task: *task, ActorID: userID, UserIDs: addedIDs(previous, assigneeIDs)})
+	return task, nil
+}
+
+// WatchTask lets the user follow a task. Besides the tasks they can already see, users can
+// watch the tasks of projects they are members of.
+func (s *taskService) WatchTask(taskID, userID uint) error {
+	_, err := s.GetTaskByID(taskID, userID)
+	if err != nil && err.Error() == "task not found" {
+		err = s.checkProjectTask(taskID, userID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Watch(taskID, userID)
+}
+
+// UnwatchTask stops the user from following a task. Creators and assignees can unwatch their
+// tasks too; they keep access to them.
+func (s *taskService) UnwatchTask(taskID, userID uint) error {
+	if _, err := s.GetTaskByID(taskID, userID); err != nil {
+		return err
+	}
+	return s.repo.Unwatch(taskID, userID)
+}
+
+// ListWatching returns one page of the tasks the user watches that match the filter.
+func (s *taskService) ListWatching(userID uint, filter models.TaskFilter) (*models.TaskPage, error) {
+	filter.WatcherID = &userID
+	return s.ListTasks(userID, filter)
+}
+
+// SetBlockers replaces the tasks that must be completed before a task. The creator and the
+// assignees can change them; blockers must be tasks the user can see and must not block
+// each other in a cycle.
+func (s *taskService) SetBlockers(taskID, userID uint, blockerIDs []uint) (*models.Task, error) {
+	task, err := s.GetTaskByID(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(task, userID) {
+		return nil, errors.New("forbidden")
+	}
+	blockerIDs = uniqueIDs(blockerIDs)
+	for _, id := range blockerIDs {
+		if id == task.ID {
+			return nil, errors.New("invalid blocker: a task cannot block itself")
+		}
+		if err := s.repo.GetByIDAndUserID(id, userID, &models.Task{}); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("invalid blocker: task %d not found", id)
+			}
+			return nil, err
+		}
+	}
+	if err := s.checkBlockerCycle(task.ID, blockerIDs); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetBlockers(task, blockerIDs, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// MoveTask places a task between two tasks of its list, the tasks of its project with the
+// same status, optionally moving it to another status. Only the moved task's rank changes;
+// if its neighbours leave no room, the list is rebalanced first.
+func (s *taskService) MoveTask(taskID, userID uint, request models.TaskMoveRequest) (*models.Task, error) {
+	task, err := s.GetTaskByID(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(task, userID) {
+		return nil, errors.New("forbidden")
+	}
+	status, category := task.Status, task.Category()
+	if request.Status != "" {
+		target, err := s.workflowStatus(task.ProjectID, task.Status, request.Status, true)
+		if err != nil {
+			return nil, err
+		}
+		status, category = target.Name, target.Category
+	}
+	if status != task.Status {
+		if err := s.checkWIPLimit(task.ProjectID, status, task.ID, request.OverrideWIP); err != nil {
+			return nil, err
+		}
+	}
+	after, err := s.moveAnchor(task, status, request.AfterID, userID, "after_id")
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.moveAnchor(task, status, request.BeforeID, userID, "before_id")
+	if err != nil {
+		return nil, err
+	}
+	if after != nil && before != nil && after.Rank > before.Rank {
+		return nil, errors.New("invalid anchors: after_id must come before before_id")
+	}
+
+	rank, ok, err := s.rankBetween(task, status, after, before)
+	if err == nil && !ok {
+		// Anchors with equal ranks leave no room: rebalance the list and read them again
+		if err := s.repo.RebalanceRanks(task.ProjectID, status); err != nil {
+			return nil, err
+		}
+		if after, err = s.moveAnchor(task, status, request.AfterID, userID, "after_id"); err != nil {
+			return nil, err
+		}
+		if before, err = s.moveAnchor(task, status, request.BeforeID, userID, "before_id"); err != nil {
+			return nil, err
+		}
+		rank, ok, err = s.rankBetween(task, status, after, before)
+		if err == nil && !ok {
+			return nil, errors.New("invalid anchors: after_id must come before before_id")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveInList(task.ProjectID, status, task.ID, status != task.Status && !request.OverrideWIP, func(repo repository.TaskRepository) error {
+		return repo.UpdateRank(task.ID, rank, status, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status != task.Status && s.events != nil {
+		watcherIDs, err := s.repo.GetWatcherIDs(task.ID)
+		if err != nil {
+			return nil, err
+		}
+		updated := *task
+		updated.Status, updated.StatusCategory = status, category
+		s.publish(Event{Type: EventTaskUpdated, Task: updated, ActorID: userID, UserIDs: watcherIDs, Changes: []string{"status"}})
+	}
+	task.Rank, task.Status, task.StatusCategory = rank, status, category
+	return task, nil
+}
+
+// moveAnchor loads an anchor of a move, which must be another task of the target list.
+func (s *taskService) moveAnchor(task *models.Task, status string, anchorID *uint, userID uint, field string) (*models.Task, error) {
+	if anchorID == nil {
+		return nil, nil
+	}
+	if *anchorID == task.ID {
+		return nil, fmt.Errorf("invalid %s: a task cannot be moved next to itself", field)
+	}
+	anchor := &models.Task{}
+	if err := s.repo.GetByIDAndUserID(*anchorID, userID, anchor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("invalid %s: task %d not found", field, *anchorID)
+		}
+		return nil, err
+	}
+	if !sameProject(anchor.ProjectID, task.ProjectID) || anchor.Status != status {
+		return nil, fmt.Errorf("invalid %s: task %d is not in the same project and status", field, *anchorID)
+	}
+	return anchor, nil
+}
+
+// rankBetween returns a rank between the anchors of a move. A missing anchor is replaced by
+// the anchor's neighbour in the list, or by the end of the list without anchors.
+func (s *taskService) rankBetween(task *models.Task, status string, after, before *models.Task) (string, bool, error) {
+	if (after != nil && after.Rank == "") || (before != nil && before.Rank == "") {
+		return "", false, nil // Unranked anchors need a rebalance
+	}
+	var lower, upper string
+	var err error
+	switch {
+	case after != nil && before != nil:
+		lower, upper = after.Rank, before.Rank
+	case after != nil:
+		lower = after.Rank
+		upper, err = s.repo.GetAdjacentRank(task.ProjectID, status, after.Rank, true, task.ID)
+	case before != nil:
+		upper = before.Rank
+		lower, err = s.repo.GetAdjacentRank(task.ProjectID, status, before.Rank, false, task.ID)
+	default:
+		lower, err = s.repo.GetAdjacentRank(task.ProjectID, status, "", false, task.ID)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	rank, ok := models.RankBetween(lower, upper)
+	return rank, ok, nil
+}
+
+// rankLast places a task at the end of the list of its project and status.
+func (s *taskService) rankLast(task *models.Task) error {
+	rank, ok, err := s.rankBetween(task, task.Status, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rank, _ = models.RankBetween("", "") // The list has invalid ranks; the rebalancer fixes them
+	}
+	task.Rank = rank
+	return nil
+}
+
+// resolveStatus checks the status of an updated task against the workflow of its project and
+// sets its category. Within a project, the status can only change along the workflow's
+// transitions; tasks moved to another project can take any of its statuses.
+func (s *taskService) resolveStatus(existing, updated *models.Task) error {
+	moved := !sameProject(existing.ProjectID, updated.ProjectID)
+	if !moved && updated.Status == existing.Status {
+		updated.StatusCategory = existing.StatusCategory
+		return nil
+	}
+	status, err := s.workflowStatus(updated.ProjectID, existing.Status, updated.Status, !moved)
+	if err != nil {
+		return err
+	}
+	updated.Status, updated.StatusCategory = status.Name, status.Category
+	return nil
+}
+
+// workflowStatus returns the status of the project's workflow a task moves to, checking the
+// transition from its current status if asked to.
+func (s *taskService) workflowStatus(projectID *uint, from, to string, checkTransition bool) (models.WorkflowStatus, error) {
+	workflow, err := loadWorkflow(s.projectRepo, projectID)
+	if err != nil {
+		return models.WorkflowStatus{}, err
+	}
+	status, ok := workflow.Resolve(to)
+	if !ok {
+		return models.WorkflowStatus{}, errors.New("invalid task status")
+	}
+	if checkTransition && !workflow.CanTransition(from, status.Name) {
+		return models.WorkflowStatus{}, fmt.Errorf("invalid status transition: %q cannot move to %q", from, status.Name)
+	}
+	return status, nil
+}
+
+// updatedFieldValues merges the custom field values of an update into the task's values. A task
+// moved to another project keeps the values of the fields that project has with the same key
+// and type.
+func (s *taskService) updatedFieldValues(existing, updated *models.Task) (models.FieldValues, error) {
+	moved := !sameProject(existing.ProjectID, updated.ProjectID)
+	if len(updated.CustomFields) == 0 && (!moved || len(existing.CustomFields) == 0) {
+		return existing.CustomFields, nil
+	}
+	fields, err := customFieldCache{}.load(s.projectRepo, updated.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	current := existing.CustomFields
+	if moved {
+		previous, err := customFieldCache{}.load(s.projectRepo, existing.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		current = keptFieldValues(existing.CustomFields, previous, fields)
+	}
+	return s.fieldValues(updated.ProjectID, fields, current, updated.CustomFields, false)
+}
+
+// fieldValues validates custom field values against the fields of the task's project and
+// merges them into the current values; nil values clear a field. Required fields cannot be
+// cleared, and new tasks need a value for them.
+func (s *taskService) fieldValues(projectID *uint, fields []models.CustomField, current, values models.FieldValues, created bool) (models.FieldValues, error) {
+	byKey := make(map[string]models.CustomField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+	merged := make(models.FieldValues, len(current)+len(values))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // Report the first invalid field in a stable order
+	for _, key := range keys {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid custom field %q: not a field of the task's project", key)
+		}
+		value, err := fieldValue(field, values[key])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			if field.Required {
+				return nil, fmt.Errorf("invalid custom field %q: a value is required", key)
+			}
+			delete(merged, key)
+			continue
+		}
+		if id, ok := value.(uint); ok {
+			member, err := s.projectRepo.GetMember(*projectID, id)
+			if err != nil {
+				return nil, err
+			}
+			if member == nil {
+				return nil, fmt.Errorf("invalid custom field %q: user %d is not a member of this project", key, id)
+			}
+		}
+		merged[key] = value
+	}
+
+	if created {
+		for _, field := range fields {
+			if _, ok := merged[field.Key]; field.Required && !ok {
+				return nil, fmt.Errorf("invalid custom field %q: a value is required", field.Key)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// resolveFieldFilters checks the custom field conditions and sort of a filter against the
+// fields of its project and sets their types. Fields belong to a project, so filters that use
+// them need a project_id.
+func (s *taskService) resolveFieldFilters(filter *models.TaskFilter) error {
+	if !filter.UsesCustomFields() {
+		return nil
+	}
+	if filter.ProjectID == nil {
+		return errors.New("invalid filter: custom fields can only be used with a project_id")
+	}
+	fields, err := s.projectRepo.GetCustomFields(*filter.ProjectID)
+	if err != nil {
+		return err
+	}
+	types := make(map[string]string, len(fields))
+	for _, field := range fields {
+		types[field.Key] = field.Type
+	}
+
+	for i := range filter.CustomFields {
+		condition := &filter.CustomFields[i]
+		fieldType, ok := types[condition.Key]
+		if !ok {
+			return fmt.Errorf("invalid filter: %q is not a custom field of the project", condition.Key)
+		}
+		condition.Type = fieldType
+		value, err := fieldFilterValue(fieldType, condition.Op, condition.Value)
+		if err != nil {
+			return fmt.Errorf("invalid filter on custom field %q: %s", condition.Key, err)
+		}
+		condition.Value = value
+	}
+	if key, ok := filter.CustomSortKey(); ok {
+		fieldType, ok := types[key]
+		if !ok {
+			return fmt.Errorf("invalid sort: %q is not a custom field of the project", key)
+		}
+		filter.SortFieldType = fieldType
+	}
+	return nil
+}
+
+// checkWIPLimit ensures a task entering the list of the project and status keeps the
+// status's board column within its work-in-progress limit, unless the limit is overridden.
+func (s *taskService) checkWIPLimit(projectID *uint, status string, taskID uint, override bool) error {
+	if override || projectID == nil {
+		return nil
+	}
+	columns, err := s.projectRepo.GetBoardColumns(*projectID)
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Status != status || column.WIPLimit == nil {
+			continue
+		}
+		count, err := s.repo.CountInList(projectID, status, taskID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(*column.WIPLimit) {
+			return fmt.Errorf("wip limit reached: column %q already has %d of %d tasks", column.Name, count, *column.WIPLimit)
+		}
+	}
+	return nil
+}
+
+// hasWIPLimit reports whether the board column of the project's status has a WIP limit.
+func (s *taskService) hasWIPLimit(projectID uint, status string) (bool, error) {
+	columns, err := s.projectRepo.GetBoardColumns(projectID)
+	if err != nil {
+		return false, err
+	}
+	for _, column := range columns {
+		if column.Status == status && column.WIPLimit != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkBlockerCycle ensures none of the blockers is blocked, directly or through other
+// tasks, by the task itself.
+func (s *taskService) checkBlockerCycle(taskID uint, blockerIDs []uint) error {
+	seen := map[uint]bool{}
+	next := blockerIDs
+	for len(next) > 0 {
+		ids, err := s.repo.GetBlockerIDs(next)
+		if err != nil {
+			return err
+		}
+		next = nil
+		for _, id := range ids {
+			if id == taskID {
+				return errors.New("invalid blocker: the task would block itself through a chain of blockers")
+			}
+			if !seen[id] {
+				seen[id] = true
+				next = append(next, id)
+			}
+		}
+	}
+	return nil
+}
+
+// NextTasks ranks the open tasks the user has to do, those assigned to them and those they
+// created without assigning them, with ScoreTask and returns the best ones. Tasks that
+// wait for open blockers are left out.
+func (s *taskService) NextTasks(userID uint, limit int) ([]models.RankedTask, error) {
+	if limit < 1 {
+		limit = models.DefaultNextTasks
+	}
+	limit = min(limit, models.MaxNextTasks)
+
+	tasks, err := s.repo.GetNextCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	blockedBy, blocks, err := s.repo.CountOpenBlockers(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ranked := []models.RankedTask{}
+	for _, task := range tasks {
+		if blockedBy[task.ID] > 0 {
+			continue
+		}
+		ranked = append(ranked, ScoreTask(task, blocks[task.ID], now))
+	}
+	sortRankedTasks(ranked)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// checkProjectTask ensures the task belongs to a project the user is a member of.
+func (s *taskService) checkProjectTask(taskID, userID uint) error {
+	task, err := s.repo.GetByID(taskID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && task.ProjectID == nil) {
+		return errors.New("task not found")
+	}
+	if err != nil {
+		return err
+	}
+	member, err := s.projectRepo.GetMember(*task.ProjectID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+// GetAssignmentHistory returns the assignment history of a task the user can see.
+func (s *taskService) GetAssignmentHistory(taskID, userID uint) ([]models.TaskAssignmentChange, error) {
+	if _, err := s.GetTaskByID(taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAssignmentHistory(taskID)
+}
+
+// checkAssignees ensures tasks are only assigned to members of their project, or of the
+// organization for tasks outside projects.
+func (s *taskService) checkAssignees(projectID *uint, assigneeIDs []uint) error {
+	for _, id := range assigneeIDs {
+		if projectID != nil {
+			member, err := s.projectRepo.GetMember(*projectID, id)
+			if err != nil {
+				return err
+			}
+			if member == nil {
+				return fmt.Errorf("invalid assignee: user %d is not a member of this project", id)
+			}
+			continue
+		}
+		member, err := s.orgRepo.GetMember(s.organizationID, id)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return fmt.Errorf("invalid assignee: user %d is not a member of this organization", id)
+		}
+	}
+	return nil
+}
+
+// publish sends an event about a task of the service's organization. Events without
+// users, mentions or changes to report are dropped.
+func (s *taskService) publish(event Event) {
+	if s.events == nil {
+		return
+	}
+	if event.Type != EventTaskUpdated && len(event.UserIDs) == 0 && len(event.Mentions) == 0 {
+		return
+	}
+	if event.Type == EventTaskUpdated && len(event.Changes) == 0 {
+		return
+	}
+	if event.OrganizationID == 0 {
+		event.OrganizationID = event.Task.OrganizationID
+	}
+	if event.OrganizationID == 0 {
+		event.OrganizationID = s.organizationID
+	}
+	s.events.Publish(event)
+}
+
+// checkProjectMembership ensures the user may add tasks to the project.
+func (s *taskService) checkProjectMembership(projectID *uint, userID uint) error {
+	if projectID == nil {
+		return nil
+	}
+	member, err := s.projectRepo.GetMember(*projectID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return errors.New("forbidden") // 403
+	}
+	return nil
+}
+
+// changedFields lists the fields of a task that an update changes.
+func changedFields(existing, updated *models.Task) []string {
+	var changes []string
+	if existing.Title != updated.Title {
+		changes = append(changes, "title")
+	}
+	if existing.Description != updated.Description {
+		changes = append(changes, "description")
+	}
+	if existing.Status != updated.Status {
+		changes = append(changes, "status")
+	}
+	if !sameTime(existing.DueDate, updated.DueDate) {
+		changes = append(changes, "due_date")
+	}
+	if !sameProject(existing.ProjectID, updated.ProjectID) {
+		changes = append(changes, "project")
+	}
+	if existing.Priority != updated.Priority {
+		changes = append(changes, "priority")
+	}
+	if !sameFieldValues(existing.CustomFields, updated.CustomFields) {
+		changes = append(changes, "custom_fields")
+	}
+	return changes
+}
+
+// validatePlanning checks the priority and effort estimate of a task.
+func validatePlanning(task *models.Task) error {
+	if task.Priority != "" && !models.IsValidTaskPriority(task.Priority) {
+		return errors.New("invalid priority: must be one of P0, P1, P2, P3, P4")
+	}
+	if task.EffortMinutes < 0 || task.EffortMinutes > models.MaxEffortMinutes {
+		return fmt.Errorf("invalid effort_minutes: must be between 0 and %d", models.MaxEffortMinutes)
+	}
+	return nil
+}
+
+// sameTime compares two optional timestamps.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// addedIDs returns the IDs in after that are not in before.
+func addedIDs(before, after []uint) []uint {
+	known := make(map[uint]bool, len(before))
+	for _, id := range before {
+		known[id] = true
+	}
+	var added []uint
+	for _, id := range after {
+		if !known[id] {
+			added = append(added, id)
+		}
+	}
+	return added
+}
+
+// sameProject compares two optional project IDs.
+func sameProject(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// canEdit reports whether the user created or is assigned to the task.
+func canEdit(task *models.Task, userID uint) bool {
+	if task.UserID == userID {
+		return true
+	}
+	for _, assignee := range task.Assignees {
+		if assignee.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// assigneeIDsOf returns the IDs of the users a task is assigned to.
+func assigneeIDsOf(task *models.Task) []uint {
+	ids := make([]uint, 0, len(task.Assignees))
+	for _, assignee := range task.Assignees {
+		ids = append(ids, assignee.UserID)
+	}
+	return ids
+}
+
+// uniqueIDs removes duplicates from a list of IDs, keeping the first occurrence.
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
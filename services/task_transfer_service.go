@@ -0,0 +1,424 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+)
+
+// Supported import and export formats
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// exportBatchSize is the number of tasks read from the database at a time during export
+const exportBatchSize = 500
+
+// MaxImportRows limits the size of a single import
+const MaxImportRows = 10000
+
+// taskColumns lists the fields written by export and understood by import
+var taskColumns = []string{"id", "title", "description", "status", "project_id", "due_date", "priority", "important", "urgent", "effort_minutes", "custom_fields", "created_at", "updated_at"}
+
+// importColumns lists the fields that can be set by an import
+var importColumns = []string{"title", "description", "status", "project_id", "due_date", "priority", "important", "urgent", "effort_minutes", "custom_fields"}
+
+// TaskTransferService defines the interface for exporting and importing tasks.
+type TaskTransferService interface {
+	Export(userID uint, format string, w io.Writer) error
+	Import(userID uint, format string, r io.Reader, mapping map[string]string, dryRun bool) (*models.ImportReport, error)
+	ForOrganization(organizationID uint) TaskTransferService
+}
+
+type taskTransferService struct {
+	repo        repository.TaskRepository
+	taskService TaskService
+}
+
+// NewTaskTransferService creates a new instance of TaskTransferService.
+func NewTaskTransferService(repo repository.TaskRepository, taskService TaskService) TaskTransferService {
+	return &taskTransferService{repo: repo, taskService: taskService}
+}
+
+// ForOrganization returns a TaskTransferService restricted to the organization's tasks.
+func (s *taskTransferService) ForOrganization(organizationID uint) TaskTransferService {
+	return &taskTransferService{repo: s.repo.ForOrganization(organizationID), taskService: s.taskService.ForOrganization(organizationID)}
+}
+
+// IsValidTransferFormat reports whether the format can be used for import and export.
+func IsValidTransferFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON || format == FormatNDJSON
+}
+
+// Export writes all tasks the user created to w, reading them from the database in batches.
+func (s *taskTransferService) Export(userID uint, format string, w io.Writer) error {
+	switch format {
+	case FormatCSV:
+		return s.exportCSV(userID, w)
+	case FormatJSON:
+		return s.exportJSON(userID, w)
+	case FormatNDJSON:
+		return s.exportNDJSON(userID, w)
+	default:
+		return errors.New("unsupported format")
+	}
+}
+
+func (s *taskTransferService) exportCSV(userID uint, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taskColumns); err != nil {
+		return err
+	}
+
+	err := s.repo.EachByUserID(userID, exportBatchSize, func(tasks []models.Task) error {
+		for _, task := range tasks {
+			if err := writer.Write(taskRecord(task)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *taskTransferService) exportJSON(userID uint, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.repo.EachByUserID(userID, exportBatchSize, func(tasks []models.Task) error {
+		for _, task := range tasks {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+
+			data, err := json.Marshal(task)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func (s *taskTransferService) exportNDJSON(userID uint, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return s.repo.EachByUserID(userID, exportBatchSize, func(tasks []models.Task) error {
+		for _, task := range tasks {
+			if err := encoder.Encode(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// taskRecord converts a task into a CSV row matching taskColumns. Custom field values are
+// written as a JSON object, empty for tasks without values.
+func taskRecord(task models.Task) []string {
+	projectID := ""
+	if task.ProjectID != nil {
+		projectID = strconv.FormatUint(uint64(*task.ProjectID), 10)
+	}
+	dueDate := ""
+	if task.DueDate != nil {
+		dueDate = task.DueDate.Format(time.RFC3339)
+	}
+	customFields := ""
+	if len(task.CustomFields) > 0 {
+		data, _ := json.Marshal(task.CustomFields)
+		customFields = string(data)
+	}
+	return []string{
+		strconv.FormatUint(uint64(task.ID), 10),
+		task.Title,
+		task.Description,
+		task.Status,
+		projectID,
+		dueDate,
+		task.Priority,
+		strconv.FormatBool(task.Important),
+		strconv.FormatBool(task.Urgent),
+		strconv.Itoa(task.EffortMinutes),
+		customFields,
+		task.CreatedAt.Format(time.RFC3339),
+		task.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// Import reads tasks from r and creates them for the user in a single transaction.
+// The mapping translates task fields to source column names, e.g. {"title": "Name"}.
+// Nothing is saved when dryRun is set or when any row is invalid.
+func (s *taskTransferService) Import(userID uint, format string, r io.Reader, mapping map[string]string, dryRun bool) (*models.ImportReport, error) {
+	for field := range mapping {
+		if !contains(importColumns, field) {
+			return nil, fmt.Errorf("invalid mapping: unknown field %q", field)
+		}
+	}
+
+	var records []map[string]string
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = readCSVRecords(r)
+	case FormatJSON:
+		records, err = readJSONRecords(r)
+	case FormatNDJSON:
+		records, err = readNDJSONRecords(r)
+	default:
+		return nil, errors.New("unsupported format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Total: len(records), DryRun: dryRun, Errors: []models.ImportRowError{}}
+	tasks := make([]models.Task, 0, len(records))
+	rows := make([]int, 0, len(records))
+
+	for i, record := range records {
+		task, err := taskFromRecord(record, mapping)
+		if err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		task.UserID = userID
+		tasks = append(tasks, *task)
+		rows = append(rows, i+1)
+	}
+	for i, err := range s.taskService.ValidateTasks(tasks) {
+		if err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: rows[i], Error: err.Error()})
+		}
+	}
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	// The tasks were validated above, so they are saved as they are
+	if err := s.repo.CreateBatch(tasks); err != nil {
+		return nil, err
+	}
+	report.Imported = len(tasks)
+	return report, nil
+}
+
+// taskFromRecord builds a task from a source record using the column mapping.
+func taskFromRecord(record map[string]string, mapping map[string]string) (*models.Task, error) {
+	value := func(field string) string {
+		column := field
+		if mapped, ok := mapping[field]; ok {
+			column = mapped
+		}
+		return strings.TrimSpace(record[column])
+	}
+
+	task := &models.Task{
+		Title:       value("title"),
+		Description: value("description"),
+		Status:      value("status"),
+		Priority:    value("priority"),
+	}
+
+	for field, flag := range map[string]*bool{"important": &task.Important, "urgent": &task.Urgent} {
+		if raw := value(field); raw != "" {
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: must be true or false", field)
+			}
+			*flag = parsed
+		}
+	}
+
+	if raw := value("effort_minutes"); raw != "" {
+		effort, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("invalid effort_minutes")
+		}
+		task.EffortMinutes = effort
+	}
+
+	if raw := value("project_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid project_id")
+		}
+		projectID := uint(id)
+		task.ProjectID = &projectID
+	}
+
+	if raw := value("custom_fields"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &task.CustomFields); err != nil {
+			return nil, errors.New("invalid custom_fields: must be a JSON object")
+		}
+	}
+
+	if raw := value("due_date"); raw != "" {
+		dueDate, err := parseImportDate(raw)
+		if err != nil {
+			return nil, err
+		}
+		task.DueDate = &dueDate
+	}
+
+	return task, nil
+}
+
+// parseImportDate accepts RFC3339 timestamps and plain YYYY-MM-DD dates.
+func parseImportDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("due_date must be an RFC3339 timestamp or a YYYY-MM-DD date")
+}
+
+// readCSVRecords reads a CSV file whose first row holds the column names.
+func readCSVRecords(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("invalid file: no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(records) == MaxImportRows {
+			return nil, fmt.Errorf("invalid file: more than %d rows", MaxImportRows)
+		}
+
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[strings.TrimSpace(column)] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readJSONRecords reads a JSON array of objects.
+func readJSONRecords(r io.Reader) ([]map[string]string, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("invalid json: expected an array of objects")
+	}
+
+	var records []map[string]string
+	for decoder.More() {
+		if len(records) == MaxImportRows {
+			return nil, fmt.Errorf("invalid file: more than %d rows", MaxImportRows)
+		}
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		records = append(records, stringifyObject(object))
+	}
+	return records, nil
+}
+
+// readNDJSONRecords reads one JSON object per line, skipping blank lines.
+func readNDJSONRecords(r io.Reader) ([]map[string]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []map[string]string
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(records) == MaxImportRows {
+			return nil, fmt.Errorf("invalid file: more than %d rows", MaxImportRows)
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			return nil, fmt.Errorf("invalid ndjson: line %d is not a json object", line)
+		}
+		records = append(records, stringifyObject(object))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// stringifyObject converts JSON values to strings so all formats share one code path.
+func stringifyObject(object map[string]interface{}) map[string]string {
+	record := make(map[string]string, len(object))
+	for key, value := range object {
+		switch v := value.(type) {
+		case nil:
+			record[key] = ""
+		case string:
+			record[key] = v
+		case float64:
+			record[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			data, _ := json.Marshal(v)
+			record[key] = string(data)
+		}
+	}
+	return record
+}
+
+// contains reports whether the slice holds the value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// generateToken returns a random hex-encoded token of n bytes.
+func generateToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken returns the SHA-256 hash of a token; only hashes of secret tokens are stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signPurposeToken signs a short-lived JWT for a single purpose, such as an email link.
+// The purpose claim keeps these tokens from being accepted as login tokens.
+func signPurposeToken(secret, purpose string, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	claims["purpose"] = purpose
+	claims["exp"] = time.Now().Add(ttl).Unix()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// parsePurposeToken verifies a token created by signPurposeToken for the same purpose.
+func parsePurposeToken(secret, purpose, tokenString string) (jwt.MapClaims, bool) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose {
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	totpIssuer          = "Task Manager"  // Issuer shown in authenticator apps
+	recoveryCodeCount   = 10              // Recovery codes generated when 2FA is enabled
+	mfaTokenTTL         = 5 * time.Minute // Time to enter the second factor after the password
+	mfaTokenPurpose     = "2fa_login"     // Purpose claim of intermediate login tokens
+	recoveryCodeLength  = 10              // Characters per recovery code, without the dash
+	mfaTokenMaxFailures = 5               // Wrong codes before an mfa token stops working
+)
+
+// TwoFactorService defines the interface for TOTP two-factor authentication.
+type TwoFactorService interface {
+	Enroll(userID uint) (*models.TOTPEnrollment, error)
+	Confirm(userID uint, code string) ([]string, error)
+	Disable(userID uint, code string) error
+	StartLogin(userID uint, scopes []string) (string, error)
+	CompleteLogin(mfaToken string, code string) (uint, []string, error)
+	LoginAccount(mfaToken string) string
+}
+
+type twoFactorService struct {
+	userRepo  repository.UserRepository
+	repo      repository.TwoFactorRepository
+	attempts  repository.LoginAttemptStore
+	secretKey string
+	now       func() time.Time
+}
+
+// NewTwoFactorService creates a new instance of TwoFactorService. Wrong codes for an mfa token
+// are counted in the attempts store, so they add up across API instances.
+func NewTwoFactorService(userRepo repository.UserRepository, repo repository.TwoFactorRepository, attempts repository.LoginAttemptStore) TwoFactorService {
+	return &twoFactorService{userRepo: userRepo, repo: repo, attempts: attempts, secretKey: jwtSecret(), now: time.Now}
+}
+
+// Enroll creates a new TOTP secret for the user. It is not required at login until confirmed.
+func (s *twoFactorService) Enroll(userID uint) (*models.TOTPEnrollment, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPendingSecret(userID, secret); err != nil {
+		return nil, err
+	}
+	return &models.TOTPEnrollment{Secret: secret, URI: otpauthURI(totpIssuer, user.Email, secret)}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves the authenticator works,
+// and returns new recovery codes. The codes are only returned here; their hashes are stored.
+func (s *twoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor authentication is not set up")
+	}
+
+	now := s.now()
+	counter, ok := matchTOTP(user.TOTPSecret, code, now)
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	if err := s.repo.Enable(userID, counter, hashes, now); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking a TOTP or recovery code.
+func (s *twoFactorService) Disable(userID uint, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if err := s.checkCode(user, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(userID)
+}
+
+// StartLogin returns a short-lived token proving that the password was correct.
+// It can only be exchanged for a login token together with a second factor.
+func (s *twoFactorService) StartLogin(userID uint, scopes []string) (string, error) {
+	id, err := generateToken(16)
+	if err != nil {
+		return "", err
+	}
+	return signPurposeToken(s.secretKey, mfaTokenPurpose, jwt.MapClaims{
+		"userID": userID,
+		"scopes": scopes,
+		"jti":    id,
+	}, mfaTokenTTL)
+}
+
+// CompleteLogin checks the second factor for an intermediate token and returns the user ID
+// and the scopes requested at the password step. After mfaTokenMaxFailures wrong codes the
+// token stops working and the user has to enter the password again.
+func (s *twoFactorService) CompleteLogin(mfaToken string, code string) (uint, []string, error) {
+	claims, ok := parsePurposeToken(s.secretKey, mfaTokenPurpose, mfaToken)
+	if !ok {
+		return 0, nil, errors.New("invalid or expired mfa token")
+	}
+	userID, ok := claims["userID"].(float64)
+	id, hasID := claims["jti"].(string)
+	if !ok || !hasID {
+		return 0, nil, errors.New("invalid or expired mfa token")
+	}
+	key := "mfa:" + id
+	attempt, err := s.attempts.Get(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	if attempt != nil && attempt.Failures >= mfaTokenMaxFailures {
+		return 0, nil, errors.New("invalid or expired mfa token")
+	}
+	var scopes []string
+	if list, ok := claims["scopes"].([]interface{}); ok {
+		for _, raw := range list {
+			if scope, ok := raw.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	user, err := s.userRepo.FindByID(uint(userID))
+	if err != nil {
+		return 0, nil, err
+	}
+	if user == nil || user.TOTPEnabledAt == nil {
+		return 0, nil, errors.New("invalid or expired mfa token")
+	}
+	if err := s.checkCode(user, code); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			if _, err := s.attempts.Increment(key, s.now(), mfaTokenTTL); err != nil {
+				return 0, nil, err
+			}
+		}
+		return 0, nil, err
+	}
+	return user.ID, scopes, nil
+}
+
+// LoginAccount returns the email of the user an mfa token was issued to, or an empty string
+// for invalid tokens, so failed second factors count against the account like passwords do.
+func (s *twoFactorService) LoginAccount(mfaToken string) string {
+	claims, ok := parsePurposeToken(s.secretKey, mfaTokenPurpose, mfaToken)
+	if !ok {
+		return ""
+	}
+	userID, ok := claims["userID"].(float64)
+	if !ok {
+		return ""
+	}
+	user, err := s.userRepo.FindByID(uint(userID))
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.Email
+}
+
+// checkCode accepts an unused TOTP code or an unused recovery code.
+func (s *twoFactorService) checkCode(user *models.User, code string) error {
+	if counter, ok := matchTOTP(user.TOTPSecret, code, s.now()); ok {
+		// A code can only be used once, even within its 30 seconds
+		used, err := s.repo.UseCounter(user.ID, counter)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+		return errors.New("invalid two-factor code")
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return errors.New("invalid two-factor code")
+	}
+	used, err := s.repo.UseRecoveryCode(user.ID, hashToken(normalized), s.now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("invalid two-factor code")
+	}
+	return nil
+}
+
+func (s *twoFactorService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// generateRecoveryCode returns a random code such as "K7Q2M-XP4ZD".
+func generateRecoveryCode() (string, error) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	return secret[:recoveryCodeLength/2] + "-" + secret[recoveryCodeLength/2:recoveryCodeLength], nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the dash or in lower case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"gorm.io/gorm"
+)
+
+// WorkflowService defines the interface for working with the status workflows of projects.
+type WorkflowService interface {
+	GetWorkflow(projectID, userID uint) (*models.Workflow, error)
+	SetWorkflow(projectID, userID uint, request models.WorkflowRequest) (*models.Workflow, error)
+	ForOrganization(organizationID uint) WorkflowService
+}
+
+type workflowService struct {
+	transactor  repository.Transactor
+	projectRepo repository.ProjectRepository
+}
+
+// NewWorkflowService creates a new instance of WorkflowService.
+func NewWorkflowService(transactor repository.Transactor, projectRepo repository.ProjectRepository) WorkflowService {
+	return &workflowService{transactor: transactor, projectRepo: projectRepo}
+}
+
+// ForOrganization returns a WorkflowService restricted to the organization's projects.
+func (s *workflowService) ForOrganization(organizationID uint) WorkflowService {
+	return &workflowService{transactor: s.transactor, projectRepo: s.projectRepo.ForOrganization(organizationID)}
+}
+
+// GetWorkflow returns the workflow of a project the user is a member of.
+func (s *workflowService) GetWorkflow(projectID, userID uint) (*models.Workflow, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	workflow, err := loadWorkflow(s.projectRepo, &projectID)
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// SetWorkflow replaces the statuses of a project's workflow. Only the project owner can change
+// it. Tasks whose status is removed move to the status the request maps it to, or else to the
+// first new status of the same category. The tasks are counted and moved in the transaction
+// that replaces the statuses, so tasks created meanwhile cannot keep a removed status.
+func (s *workflowService) SetWorkflow(projectID, userID uint, request models.WorkflowRequest) (*models.Workflow, error) {
+	member, err := s.projectRepo.GetMember(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.New("project not found")
+	}
+	if member.Role != models.ProjectRoleOwner {
+		return nil, errors.New("forbidden")
+	}
+
+	workflow, err := workflowFromRequest(projectID, request.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	err = s.transactor.Transaction(func(tx *gorm.DB) error {
+		projectRepo := s.projectRepo.WithTx(tx)
+		current, err := loadWorkflow(projectRepo, &projectID)
+		if err != nil {
+			return err
+		}
+		counts, err := projectRepo.CountTasksByStatus(projectID)
+		if err != nil {
+			return err
+		}
+		moves, err := statusMoves(current, workflow, counts, request.StatusMapping)
+		if err != nil {
+			return err
+		}
+		return projectRepo.SetWorkflow(projectID, workflow.Statuses, moves)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// loadWorkflow returns the workflow of a project, or the default workflow for projects
+// without custom statuses and for tasks outside projects.
+func loadWorkflow(projectRepo repository.ProjectRepository, projectID *uint) (models.Workflow, error) {
+	if projectID == nil {
+		return models.DefaultWorkflow(nil), nil
+	}
+	statuses, err := projectRepo.GetWorkflowStatuses(*projectID)
+	if err != nil {
+		return models.Workflow{}, err
+	}
+	if len(statuses) == 0 {
+		return models.DefaultWorkflow(projectID), nil
+	}
+	return models.Workflow{ProjectID: projectID, Custom: true, Statuses: statuses}, nil
+}
+
+// workflowCache remembers the workflows of projects while a batch of tasks is validated.
+type workflowCache map[uint]models.Workflow
+
+// load returns the workflow of a project, loading it on first use.
+func (c workflowCache) load(projectRepo repository.ProjectRepository, projectID *uint) (models.Workflow, error) {
+	if projectID == nil {
+		return models.DefaultWorkflow(nil), nil
+	}
+	if workflow, ok := c[*projectID]; ok {
+		return workflow, nil
+	}
+	workflow, err := loadWorkflow(projectRepo, projectID)
+	if err != nil {
+		return models.Workflow{}, err
+	}
+	c[*projectID] = workflow
+	return workflow, nil
+}
+
+// workflowFromRequest validates the statuses of a workflow request and turns them into a workflow.
+func workflowFromRequest(projectID uint, requests []models.WorkflowStatusRequest) (models.Workflow, error) {
+	if len(requests) == 0 || len(requests) > models.MaxWorkflowStatuses {
+		return models.Workflow{}, fmt.Errorf("invalid workflow: must have between 1 and %d statuses", models.MaxWorkflowStatuses)
+	}
+	workflow := models.Workflow{ProjectID: &projectID, Custom: true, Statuses: make([]models.WorkflowStatus, len(requests))}
+	names := make(map[string]bool, len(requests))
+	done := false
+	for i, request := range requests {
+		name := strings.TrimSpace(request.Name)
+		if name == "" || len([]rune(name)) > models.MaxStatusName {
+			return models.Workflow{}, fmt.Errorf("invalid status name: must be between 1 and %d characters", models.MaxStatusName)
+		}
+		if names[name] {
+			return models.Workflow{}, fmt.Errorf("invalid workflow: status %q is listed more than once", name)
+		}
+		names[name] = true
+		if !models.IsValidStatusCategory(request.Category) {
+			return models.Workflow{}, fmt.Errorf("invalid status category %q: must be todo, doing or done", request.Category)
+		}
+		done = done || request.Category == models.StatusCategoryDone
+		workflow.Statuses[i] = models.WorkflowStatus{ProjectID: projectID, Name: name, Category: request.Category, Position: i}
+	}
+	if !done {
+		return models.Workflow{}, errors.New("invalid workflow: at least one status must be in the done category")
+	}
+
+	for i, request := range requests {
+		from := workflow.Statuses[i].Name
+		transitions := []string{}
+		seen := map[string]bool{}
+		for _, to := range request.Transitions {
+			to = strings.TrimSpace(to)
+			if !names[to] {
+				return models.Workflow{}, fmt.Errorf("invalid transition from %q: unknown status %q", from, to)
+			}
+			if to == from || seen[to] {
+				continue
+			}
+			seen[to] = true
+			transitions = append(transitions, to)
+		}
+		workflow.Statuses[i].Transitions = transitions
+	}
+	return workflow, nil
+}
+
+// statusMoves decides where the tasks of statuses missing from the new workflow go: to the
+// status the mapping gives, or else to the first new status of the old status's category.
+// Removed statuses of the current workflow get a move even without tasks when there is a
+// target, so tasks created in them meanwhile move too.
+func statusMoves(current, next models.Workflow, counts map[string]int64, mapping map[string]string) (map[string]string, error) {
+	for from, to := range mapping {
+		if _, ok := next.Status(to); !ok {
+			return nil, fmt.Errorf("invalid status_mapping: %q is not a status of the new workflow", to)
+		}
+		if _, ok := next.Status(from); ok {
+			return nil, fmt.Errorf("invalid status_mapping: status %q is kept in the new workflow", from)
+		}
+	}
+
+	removed := map[string]int64{}
+	for _, status := range current.Statuses {
+		removed[status.Name] = 0
+	}
+	for status, count := range counts {
+		removed[status] = count
+	}
+
+	moves := map[string]string{}
+	for status, count := range removed {
+		if _, ok := next.Status(status); ok {
+			continue
+		}
+		if to, ok := mapping[status]; ok {
+			moves[status] = to
+			continue
+		}
+		category := models.DefaultStatusCategory(status)
+		if old, ok := current.Status(status); ok {
+			category = old.Category
+		}
+		to, ok := next.FirstOfCategory(category)
+		if !ok {
+			if count == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid status_mapping: tasks with status %q need a new status", status)
+		}
+		moves[status] = to.Name
+	}
+	return moves, nil
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newAccountUser returns a user whose password is "OldPassw0rd!"
+func newAccountUser(t *testing.T) *models.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte("OldPassw0rd!"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return &models.User{ID: 6, Email: "user@example.com", Password: string(hash)}
+}
+
+// TestUserJSONHidesPassword verifies that the password hash is never serialized
+func TestUserJSONHidesPassword(t *testing.T) {
+	data, err := json.Marshal(newAccountUser(t))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), `"password"`)
+	assert.NotContains(t, string(data), "$2a$")
+}
+
+// TestChangePassword verifies that the current password is required and the new one must meet the policy
+func TestChangePassword(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	service := services.NewAccountService(userRepo, nil, services.DefaultPasswordPolicy(), nil)
+
+	userRepo.On("FindByID", uint(6)).Return(newAccountUser(t), nil)
+	userRepo.On("UpdatePassword", uint(6), mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("NewPassw0rd!")) == nil
+	}), mock.Anything).Return(nil)
+
+	assert.EqualError(t, service.ChangePassword(6, "WrongPassw0rd!", "NewPassw0rd!"), "invalid password")
+
+	var policyErr *services.PasswordPolicyError
+	assert.ErrorAs(t, service.ChangePassword(6, "OldPassw0rd!", "short"), &policyErr)
+	userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.NoError(t, service.ChangePassword(6, "OldPassw0rd!", "NewPassw0rd!"))
+	userRepo.AssertNumberOfCalls(t, "UpdatePassword", 1)
+}
+
+// TestChangeEmail verifies that the new address only takes effect once its link is opened
+func TestChangeEmail(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := services.NewMemoryMailer()
+	verification := services.NewEmailVerificationService(userRepo, mailer)
+	service := services.NewAccountService(userRepo, verification, services.DefaultPasswordPolicy(), nil)
+
+	user := newAccountUser(t)
+	userRepo.On("FindByID", uint(6)).Return(user, nil).Times(3)
+	userRepo.On("FindByEmail", "taken@example.com").Return(&models.User{ID: 7, Email: "taken@example.com"}, nil)
+	userRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+	userRepo.On("SetPendingEmail", uint(6), "new@example.com").Return(nil)
+
+	assert.EqualError(t, service.ChangeEmail(6, "WrongPassw0rd!", "new@example.com"), "invalid password")
+	assert.EqualError(t, service.ChangeEmail(6, "OldPassw0rd!", "taken@example.com"), "email address is already in use")
+	assert.NoError(t, service.ChangeEmail(6, "OldPassw0rd!", "new@example.com"))
+
+	sent := mailer.Sent()
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "new@example.com", sent[0].To)
+	assert.Equal(t, "user@example.com", sent[1].To)
+
+	// Opening the link switches the account to the pending address
+	pending := *user
+	pending.PendingEmail = "new@example.com"
+	userRepo.On("FindByID", uint(6)).Return(&pending, nil)
+	userRepo.On("ConfirmEmailChange", uint(6), "new@example.com", mock.Anything).Return(true, nil)
+
+	assert.NoError(t, verification.Verify(verificationTokenFromMail(t, sent[0])))
+	userRepo.AssertCalled(t, "ConfirmEmailChange", uint(6), "new@example.com", mock.Anything)
+	userRepo.AssertNotCalled(t, "MarkVerified", mock.Anything, mock.Anything)
+}
+
+// TestDeleteAccount verifies that the account is only queued for erasure with the right password
+func TestDeleteAccount(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	personalDataRepo := new(MockPersonalDataRepository)
+	personalData := services.NewPersonalDataService(personalDataRepo, nil, nil)
+	service := services.NewAccountService(userRepo, nil, services.DefaultPasswordPolicy(), personalData)
+
+	user := newAccountUser(t)
+	userRepo.On("FindByID", uint(6)).Return(user, nil)
+	personalDataRepo.On("QueueErasure", mock.Anything).Return(nil)
+
+	assert.EqualError(t, service.DeleteAccount(6, "WrongPassw0rd!"), "invalid password")
+	personalDataRepo.AssertNotCalled(t, "QueueErasure", mock.Anything)
+
+	// The erasure itself is left to the background job
+	assert.NoError(t, service.DeleteAccount(6, "OldPassw0rd!"))
+	personalDataRepo.AssertCalled(t, "QueueErasure", mock.MatchedBy(func(record *models.ErasureRecord) bool {
+		return record.UserID == 6 && record.RequestedBy == 6 && record.CompletedAt == nil
+	}))
+	personalDataRepo.AssertNotCalled(t, "Erase", mock.Anything)
+}
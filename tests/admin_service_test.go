@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/controllers"
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockAdminRepository is a mock implementation of AdminRepository
+type MockAdminRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepository) ListUsers(filter models.UserFilter) (*models.UserPage, error) {
+	args := m.Called(filter)
+	page, _ := args.Get(0).(*models.UserPage)
+	return page, args.Error(1)
+}
+
+func (m *MockAdminRepository) SetDisabled(userID uint, at *time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) SetRole(userID uint, role string) error {
+	args := m.Called(userID, role)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) RequirePasswordReset(userID uint, at time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) GetStats(now time.Time) (*models.SystemStats, error) {
+	args := m.Called(now)
+	stats, _ := args.Get(0).(*models.SystemStats)
+	return stats, args.Error(1)
+}
+
+// MockAuditRepository is a mock implementation of AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(entry *models.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(filter models.AuditFilter) (*models.AuditPage, error) {
+	args := m.Called(filter)
+	page, _ := args.Get(0).(*models.AuditPage)
+	return page, args.Error(1)
+}
+
+// TestParseUserFilter verifies parsing of admin user list query parameters
+func TestParseUserFilter(t *testing.T) {
+	values, _ := url.ParseQuery("q=example.com&role=admin&disabled=false&page=2&page_size=10")
+
+	filter, err := models.ParseUserFilter(values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", filter.Search)
+	assert.Equal(t, models.RoleAdmin, filter.Role)
+	assert.False(t, *filter.Disabled)
+	assert.Equal(t, 10, filter.Offset())
+
+	_, err = models.ParseUserFilter(url.Values{"role": {"owner"}})
+	assert.Error(t, err)
+	_, err = models.ParseUserFilter(url.Values{"disabled": {"maybe"}})
+	assert.Error(t, err)
+	_, err = models.ParseAuditFilter(url.Values{"actor_id": {"x"}})
+	assert.Error(t, err)
+}
+
+// TestAdminSetDisabled verifies that admins can disable others but not themselves
+func TestAdminSetDisabled(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	adminRepo := new(MockAdminRepository)
+	service := services.NewAdminService(userRepo, adminRepo, nil, nil, nil)
+
+	userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Email: "user@example.com"}, nil)
+	userRepo.On("FindByID", uint(9)).Return(nil, nil)
+	adminRepo.On("SetDisabled", uint(7), mock.Anything).Return(nil)
+
+	user, err := service.SetDisabled(1, 7, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, user.DisabledAt)
+
+	_, err = service.SetDisabled(1, 1, true)
+	assert.EqualError(t, err, "invalid request: you cannot disable your own account")
+
+	_, err = service.SetDisabled(1, 9, true)
+	assert.EqualError(t, err, "user not found")
+}
+
+// TestAdminSetRole verifies role validation and that admins cannot demote themselves
+func TestAdminSetRole(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	adminRepo := new(MockAdminRepository)
+	service := services.NewAdminService(userRepo, adminRepo, nil, nil, nil)
+
+	userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Role: models.RoleUser}, nil)
+	adminRepo.On("SetRole", uint(7), models.RoleAdmin).Return(nil)
+
+	user, err := service.SetRole(1, 7, models.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+
+	_, err = service.SetRole(1, 7, "owner")
+	assert.EqualError(t, err, "invalid role: must be user or admin")
+	_, err = service.SetRole(1, 1, models.RoleUser)
+	assert.EqualError(t, err, "invalid request: you cannot change your own role")
+}
+
+// TestAdminForcePasswordReset verifies that a forced reset revokes sessions and emails a reset link
+func TestAdminForcePasswordReset(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	adminRepo := new(MockAdminRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	mailer := services.NewMemoryMailer()
+	passwordReset := services.NewPasswordResetService(userRepo, resetRepo, mailer, services.DefaultPasswordPolicy())
+	service := services.NewAdminService(userRepo, adminRepo, nil, passwordReset, nil)
+
+	user := &models.User{ID: 7, Email: "user@example.com"}
+	userRepo.On("FindByID", uint(7)).Return(user, nil)
+	userRepo.On("FindByEmail", "user@example.com").Return(user, nil)
+	adminRepo.On("RequirePasswordReset", uint(7), mock.Anything).Return(nil)
+	resetRepo.On("Create", mock.Anything).Return(nil)
+
+	assert.NoError(t, service.ForcePasswordReset(7))
+	adminRepo.AssertCalled(t, "RequirePasswordReset", uint(7), mock.Anything)
+	assert.Len(t, mailer.Sent(), 1)
+}
+
+// TestActiveSession_DisabledUser verifies that requests of disabled users are rejected
+func TestActiveSession_DisabledUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService()
+	userRepo := new(MockUserRepository)
+
+	token, _ := authService.GenerateToken(3)
+	disabledAt := time.Now()
+	userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, DisabledAt: &disabledAt}, nil)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, nil), middleware.ActiveSession(userRepo))
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Account is disabled")
+}
+
+// TestLoginUser_DisabledOrResetRequired verifies that blocked accounts cannot log in with the right password
+func TestLoginUser_DisabledOrResetRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userRepo := new(MockUserRepository)
+	controller := controllers.NewAuthController(services.NewAuthService(), userRepo, nil, nil, services.DefaultPasswordPolicy(), nil)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	disabledAt := time.Now()
+	userRepo.On("FindByEmail", "disabled@example.com").Return(&models.User{ID: 1, Password: string(hash), DisabledAt: &disabledAt}, nil)
+	userRepo.On("FindByEmail", "reset@example.com").Return(&models.User{ID: 2, Password: string(hash), MustResetPassword: true}, nil)
+
+	router := gin.New()
+	router.POST("/login", controller.LoginUser)
+
+	for email, message := range map[string]string{
+		"disabled@example.com": "Account is disabled",
+		"reset@example.com":    "Password reset required",
+	} {
+		body := `{"email": "` + email + `", "password": "Password123!"}`
+		req := httptest.NewRequest("POST", "/login", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, email)
+		assert.Contains(t, w.Body.String(), message)
+	}
+}
+
+// TestAuditAdmin verifies that admin requests are recorded, including rejected ones
+func TestAuditAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything).Return(nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+	admin := router.Group("/admin", middleware.AuditAdmin(services.NewAuditService(auditRepo)))
+	admin.PUT("/users/:id/role", func(c *gin.Context) {
+		middleware.AddAuditDetail(c, "role", "admin")
+		c.Status(http.StatusOK)
+	})
+	admin.POST("/users/unlock", func(c *gin.Context) {
+		middleware.SetAuditTarget(c, 7)
+		c.AbortWithStatus(http.StatusForbidden)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/admin/users/7/role?dry_run=true", nil))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/users/unlock", nil))
+
+	assert.Len(t, auditRepo.Calls, 2)
+	entry := auditRepo.Calls[0].Arguments.Get(0).(*models.AuditEntry)
+	assert.Equal(t, uint(1), entry.ActorID)
+	assert.Equal(t, "PUT /admin/users/:id/role", entry.Action)
+	assert.Equal(t, uint(7), *entry.TargetUserID)
+	assert.Equal(t, map[string]string{"id": "7", "dry_run": "true", "role": "admin"}, entry.Details)
+	assert.Equal(t, http.StatusOK, entry.Status)
+
+	entry = auditRepo.Calls[1].Arguments.Get(0).(*models.AuditEntry)
+	assert.Equal(t, uint(7), *entry.TargetUserID)
+	assert.Nil(t, entry.Details)
+	assert.Equal(t, http.StatusForbidden, entry.Status)
+}
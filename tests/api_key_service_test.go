@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key *models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByUserID(userID uint, keys *[]models.APIKey) error {
+	args := m.Called(userID, keys)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	args := m.Called(keyHash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Delete(id uint, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestCreateAPIKeyStoresOnlyHash verifies that the plaintext key is returned once and only its hash is stored
+func TestCreateAPIKeyStoresOnlyHash(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := services.NewAPIKeyService(mockRepo)
+
+	var stored *models.APIKey
+	mockRepo.On("Create", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.APIKey)
+	})
+
+	created, err := service.CreateKey(1, models.APIKeyCreateRequest{Name: "CI", Scopes: []string{models.ScopeTasksRead, models.ScopeTasksRead}, ExpiresInDays: 30})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, services.APIKeyPrefix))
+	assert.Equal(t, sha256Hex(created.Key), stored.KeyHash)
+	assert.True(t, strings.HasPrefix(created.Key, stored.Prefix))
+	assert.Equal(t, []string{models.ScopeTasksRead}, stored.Scopes)
+	assert.NotNil(t, stored.ExpiresAt)
+}
+
+// TestCreateAPIKeyRejectsUnknownScope verifies scope validation
+func TestCreateAPIKeyRejectsUnknownScope(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := services.NewAPIKeyService(mockRepo)
+
+	_, err := service.CreateKey(1, models.APIKeyCreateRequest{Name: "CI", Scopes: []string{"tasks:delete"}})
+
+	assert.EqualError(t, err, "invalid key: unknown scope tasks:delete")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestAuthenticateExpiredAPIKey verifies that expired keys are rejected
+func TestAuthenticateExpiredAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := services.NewAPIKeyService(mockRepo)
+
+	expired := time.Now().Add(-time.Hour)
+	mockRepo.On("FindByHash", sha256Hex("tm_secret")).Return(&models.APIKey{ID: 1, UserID: 1, ExpiresAt: &expired}, nil)
+
+	_, err := service.Authenticate("tm_secret")
+
+	assert.EqualError(t, err, "api key expired")
+	mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+// TestAuthMiddleware_APIKey verifies that API keys authenticate and set their scopes in the context
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockAPIKeyRepository)
+	apiKeyService := services.NewAPIKeyService(mockRepo)
+
+	mockRepo.On("FindByHash", sha256Hex("tm_secret")).Return(&models.APIKey{ID: 5, UserID: 7, Scopes: []string{models.ScopeTasksRead}}, nil)
+	mockRepo.On("TouchLastUsed", uint(5), mock.Anything).Return(nil)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer tm_secret")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	middleware.AuthMiddleware(services.NewAuthService(), apiKeyService)(c)
+
+	userID, ok := middleware.GetUserID(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, ok)
+	assert.Equal(t, uint(7), userID)
+	assert.True(t, middleware.HasScope(c, models.ScopeTasksRead))
+	assert.False(t, middleware.HasScope(c, models.ScopeTasksWrite))
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRequireLoginToken verifies that API keys, even with the admin scope, cannot use account routes
+func TestRequireLoginToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockAPIKeyRepository)
+	authService := services.NewAuthService()
+	mockRepo.On("FindByHash", sha256Hex("tm_admin")).Return(&models.APIKey{ID: 5, UserID: 7, Scopes: models.AllScopes}, nil)
+	mockRepo.On("TouchLastUsed", uint(5), mock.Anything).Return(nil)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, services.NewAPIKeyService(mockRepo)))
+	router.DELETE("/me", middleware.RequireScopes(models.ScopeAdmin), middleware.RequireLoginToken(), func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+	deleteAccount := func(token string) int {
+		req := httptest.NewRequest("DELETE", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, deleteAccount("tm_admin"))
+	token, _ := authService.GenerateToken(7)
+	assert.Equal(t, http.StatusAccepted, deleteAccount(token))
+}
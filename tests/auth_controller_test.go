@@ -2,9 +2,11 @@ package tests
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/EmelinDanila/task-manager-api/controllers"
 	"github.com/EmelinDanila/task-manager-api/models"
@@ -13,6 +15,7 @@ import (
 	"github.com/EmelinDanila/task-manager-api/tests/testutils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // Test for user registration
@@ -24,7 +27,7 @@ func TestAuthController_RegisterUser(t *testing.T) {
 	// Creating required services and repositories
 	authService := services.NewAuthService()
 	repo := repository.NewUserRepository(db.GetDB())
-	controller := controllers.NewAuthController(authService, repo)
+	controller := controllers.NewAuthController(authService, repo, nil, nil, services.DefaultPasswordPolicy(), nil)
 
 	// Test data for user registration
 	registerData := `{
@@ -71,7 +74,7 @@ func TestAuthController_LoginUser(t *testing.T) {
 	// Creating required services and repositories
 	authService := services.NewAuthService()
 	repo := repository.NewUserRepository(db.GetDB())
-	controller := controllers.NewAuthController(authService, repo)
+	controller := controllers.NewAuthController(authService, repo, nil, nil, services.DefaultPasswordPolicy(), nil)
 
 	// Creating a user for testing
 	user := &models.User{
@@ -121,7 +124,7 @@ func TestAuthController_LoginUser_InvalidCredentials(t *testing.T) {
 	// Creating required services and repositories
 	authService := services.NewAuthService()
 	repo := repository.NewUserRepository(db.GetDB())
-	controller := controllers.NewAuthController(authService, repo)
+	controller := controllers.NewAuthController(authService, repo, nil, nil, services.DefaultPasswordPolicy(), nil)
 
 	// Test data for login with incorrect credentials
 	loginData := `{
@@ -149,3 +152,35 @@ func TestAuthController_LoginUser_InvalidCredentials(t *testing.T) {
 	// Checking the error message in the response
 	assert.Contains(t, w.Body.String(), "Invalid email or password")
 }
+
+// TestLoginTwoFactorChecksAccount verifies that the second factor repeats the account checks of /login
+func TestLoginTwoFactorChecksAccount(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	repo := new(MockTwoFactorRepository)
+	twoFactor := services.NewTwoFactorService(userRepo, repo, repository.NewMemoryLoginAttemptStore())
+	controller := controllers.NewAuthController(services.NewAuthService(), userRepo, nil, twoFactor, services.DefaultPasswordPolicy(), nil)
+	r := gin.Default()
+	r.POST("/login/2fa", controller.LoginTwoFactor)
+
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	enabledAt := time.Now()
+	disabledAt := time.Now()
+	// Disabled by an administrator after the password step
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: &enabledAt, DisabledAt: &disabledAt}, nil)
+	userRepo.On("FindByID", uint(2)).Return(&models.User{ID: 2, TOTPSecret: secret, TOTPEnabledAt: &enabledAt, MustResetPassword: true}, nil)
+	repo.On("UseCounter", mock.Anything, mock.Anything).Return(true, nil)
+
+	for userID, message := range map[uint]string{1: "Account is disabled", 2: "Password reset required"} {
+		mfaToken, err := twoFactor.StartLogin(userID, nil)
+		assert.NoError(t, err)
+		body, _ := json.Marshal(models.LoginTwoFactorRequest{MFAToken: mfaToken, Code: testTOTP(secret, time.Now())})
+		req, _ := http.NewRequest("POST", "/login/2fa", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), message)
+		assert.NotContains(t, w.Body.String(), "token")
+	}
+}
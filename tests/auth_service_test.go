@@ -67,3 +67,57 @@ func TestAuthService_InvalidToken(t *testing.T) {
 	_, err := authService.VerifyToken("invalid_token")
 	assert.Error(t, err, "Invalid token should return an error")
 }
+
+func TestAuthService_VerifyTokenClaims(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	authService := services.NewAuthService()
+
+	// Scoped tokens keep their scopes
+	token, err := authService.GenerateScopedToken(123, []string{"tasks:read"})
+	assert.NoError(t, err)
+	claims, err := authService.VerifyTokenClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(123), claims.UserID)
+	assert.Equal(t, []string{"tasks:read"}, claims.Scopes)
+	assert.False(t, claims.IssuedAt.IsZero())
+
+	// Tokens issued before scopes existed have every scope
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userID": 123,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test_secret"))
+	claims, err = authService.VerifyTokenClaims(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tasks:read", "tasks:write", "admin"}, claims.Scopes)
+
+	// Unknown scopes are rejected
+	_, err = authService.GenerateScopedToken(123, []string{"tasks:delete"})
+	assert.Error(t, err)
+}
+
+// TestAuthService_ReissueToken verifies that exchanged tokens keep the lifetime of the original
+func TestAuthService_ReissueToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	authService := services.NewAuthService()
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userID": 123,
+		"scopes": []string{"tasks:read"},
+		"iat":    time.Now().Add(-20 * time.Hour).Unix(),
+		"exp":    time.Now().Add(4 * time.Hour).Unix(),
+	}).SignedString([]byte("test_secret"))
+	original, err := authService.VerifyTokenClaims(token)
+	assert.NoError(t, err)
+
+	reissued, err := authService.ReissueToken(*original, 4)
+	assert.NoError(t, err)
+	claims, err := authService.VerifyTokenClaims(reissued)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), claims.OrganizationID)
+	assert.Equal(t, []string{"tasks:read"}, claims.Scopes)
+	assert.Equal(t, original.IssuedAt, claims.IssuedAt)
+	assert.Equal(t, original.ExpiresAt, claims.ExpiresAt)
+
+	_, err = authService.ReissueToken(services.TokenClaims{UserID: 123}, 4)
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetBoard verifies that boards group the project's tasks by column and flag columns over their limit
+func TestGetBoard(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	taskRepo := new(MockTaskRepository)
+	boardService := services.NewBoardService(projectRepo, taskRepo)
+
+	projectRepo.On("GetMember", uint(7), uint(1)).Return(&models.ProjectMember{ProjectID: 7, UserID: 1, Role: models.ProjectRoleMember}, nil)
+	projectRepo.On("GetMember", uint(7), uint(2)).Return(nil, nil)
+	projectRepo.On("GetWorkflowStatuses", uint(7)).Return(nil, nil)
+	taskRepo.On("GetByProject", uint(7)).Return([]models.Task{
+		{ID: 1, Status: models.StatusInProgress, Rank: "a"},
+		{ID: 2, Status: models.StatusInProgress, Rank: "b"},
+		{ID: 3, Status: models.StatusPending, Rank: "a"},
+	}, nil)
+
+	// Boards that were not configured have one column per status
+	projectRepo.On("GetBoardColumns", uint(7)).Return(nil, nil).Once()
+	board, err := boardService.GetBoard(7, 1)
+	assert.NoError(t, err)
+	assert.Len(t, board.Columns, len(models.TaskStatuses))
+	assert.Equal(t, models.StatusPending, board.Columns[0].Name)
+	assert.Equal(t, "#9e9e9e", board.Columns[0].Color)
+	assert.Equal(t, 2, board.Columns[1].TaskCount)
+	assert.Empty(t, board.Columns[2].Tasks)
+
+	limit := 1
+	projectRepo.On("GetBoardColumns", uint(7)).Return([]models.BoardColumn{
+		{ProjectID: 7, Status: models.StatusInProgress, Name: "Doing", Position: 0, WIPLimit: &limit},
+		{ProjectID: 7, Status: models.StatusPending, Name: "Backlog", Position: 1},
+		{ProjectID: 7, Status: models.StatusCompleted, Name: "Done", Position: 2},
+	}, nil)
+	board, err = boardService.GetBoard(7, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Doing", board.Columns[0].Name)
+	assert.Equal(t, []uint{1, 2}, []uint{board.Columns[0].Tasks[0].ID, board.Columns[0].Tasks[1].ID})
+	assert.True(t, board.Columns[0].OverLimit)
+	assert.False(t, board.Columns[1].OverLimit)
+
+	_, err = boardService.GetBoard(7, 2)
+	assert.EqualError(t, err, "project not found")
+}
+
+// TestSetBoardColumns verifies that only owners configure boards and that columns are validated
+func TestSetBoardColumns(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	boardService := services.NewBoardService(projectRepo, new(MockTaskRepository))
+
+	projectRepo.On("GetMember", uint(7), uint(1)).Return(&models.ProjectMember{ProjectID: 7, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetMember", uint(7), uint(2)).Return(&models.ProjectMember{ProjectID: 7, UserID: 2, Role: models.ProjectRoleMember}, nil)
+	projectRepo.On("SetBoardColumns", uint(7), mock.Anything).Return(nil)
+	projectRepo.On("GetWorkflowStatuses", uint(7)).Return(nil, nil)
+
+	limit, zero := 3, 0
+	columns, err := boardService.SetColumns(7, 1, []models.BoardColumnRequest{
+		{Status: models.StatusCompleted, Name: " Done "},
+		{Status: models.StatusPending, Color: "#FFAA00"},
+		{Status: models.StatusInProgress, Name: "Doing", WIPLimit: &limit},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BoardColumn{
+		{ProjectID: 7, Status: models.StatusCompleted, Name: "Done", Position: 0, Color: "#4caf50"},
+		{ProjectID: 7, Status: models.StatusPending, Name: models.StatusPending, Position: 1, Color: "#ffaa00"},
+		{ProjectID: 7, Status: models.StatusInProgress, Name: "Doing", Position: 2, Color: "#2196f3", WIPLimit: &limit},
+	}, columns)
+	projectRepo.AssertCalled(t, "SetBoardColumns", uint(7), columns)
+
+	_, err = boardService.SetColumns(7, 2, nil)
+	assert.EqualError(t, err, "forbidden")
+
+	invalid := []struct {
+		columns []models.BoardColumnRequest
+		err     string
+	}{
+		{[]models.BoardColumnRequest{{Status: "Someday"}}, `invalid column status "Someday"`},
+		{[]models.BoardColumnRequest{{Status: models.StatusPending}, {Status: models.StatusPending}}, `invalid columns: status "Pending" has more than one column`},
+		{[]models.BoardColumnRequest{{Status: models.StatusPending}, {Status: models.StatusCompleted}}, `invalid columns: status "In Progress" has no column`},
+		{[]models.BoardColumnRequest{{Status: models.StatusPending, Color: "red"}}, "invalid column color: must be written as #RRGGBB"},
+		{[]models.BoardColumnRequest{{Status: models.StatusPending, WIPLimit: &zero}}, "invalid wip_limit: must be a positive number or null"},
+	}
+	for _, tc := range invalid {
+		_, err := boardService.SetColumns(7, 1, tc.columns)
+		assert.EqualError(t, err, tc.err)
+	}
+	projectRepo.AssertNumberOfCalls(t, "SetBoardColumns", 1)
+}
+
+// TestWIPLimit verifies that moves into a full column fail unless the limit is overridden
+func TestWIPLimit(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID, limit := uint(7), 2
+	task := models.Task{ID: 1, Title: "Task", UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "c"}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = task
+	})
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetBoardColumns", projectID).Return([]models.BoardColumn{
+		{ProjectID: projectID, Status: models.StatusInProgress, Name: "Doing", WIPLimit: &limit},
+	}, nil)
+	mockRepo.On("CountInList", &projectID, models.StatusInProgress, uint(1)).Return(int64(2), nil)
+	mockRepo.On("GetAdjacentRank", &projectID, models.StatusInProgress, "", false, uint(1)).Return("m", nil)
+	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("UpdateRank", uint(1), "n", models.StatusInProgress, models.StatusCategoryDoing).Return(nil)
+
+	_, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Status: strPtr(models.StatusInProgress)})
+	assert.EqualError(t, err, `wip limit reached: column "Doing" already has 2 of 2 tasks`)
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{Status: models.StatusInProgress})
+	assert.EqualError(t, err, `wip limit reached: column "Doing" already has 2 of 2 tasks`)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateRank", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Moves within a column and overridden moves are allowed
+	_, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Title: strPtr("Renamed"), Status: strPtr(models.StatusPending)})
+	assert.NoError(t, err)
+	update, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Status: strPtr(models.StatusInProgress), OverrideWIP: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "n", update.Rank)
+	moved, err := taskService.MoveTask(1, 1, models.TaskMoveRequest{Status: models.StatusInProgress, OverrideWIP: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusInProgress, moved.Status)
+	mockRepo.AssertNumberOfCalls(t, "CountInList", 2)
+}
+
+// TestWIPLimitCheckedInLock verifies that the limit is checked again with the list locked before a task is saved
+func TestWIPLimitCheckedInLock(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID, limit := uint(7), 2
+	task := models.Task{ID: 1, Title: "Task", UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "c"}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = task
+	})
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetBoardColumns", projectID).Return([]models.BoardColumn{
+		{ProjectID: projectID, Status: models.StatusInProgress, Name: "Doing", WIPLimit: &limit},
+	}, nil)
+	mockRepo.On("GetAdjacentRank", &projectID, models.StatusInProgress, "", false, uint(1)).Return("m", nil)
+	mockRepo.On("LockLists", projectID)
+	// Another task takes the last place between the first check and the save
+	mockRepo.On("CountInList", &projectID, models.StatusInProgress, uint(1)).Return(int64(1), nil).Once()
+	mockRepo.On("CountInList", &projectID, models.StatusInProgress, uint(1)).Return(int64(2), nil)
+
+	_, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Status: strPtr(models.StatusInProgress)})
+	assert.EqualError(t, err, `wip limit reached: column "Doing" already has 2 of 2 tasks`)
+	mockRepo.AssertCalled(t, "LockLists", projectID)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCalendarFeedRepository is a mock implementation of CalendarFeedRepository
+type MockCalendarFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalendarFeedRepository) Replace(feed *models.CalendarFeed) error {
+	args := m.Called(feed)
+	return args.Error(0)
+}
+
+func (m *MockCalendarFeedRepository) FindByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	args := m.Called(tokenHash)
+	feed, _ := args.Get(0).(*models.CalendarFeed)
+	return feed, args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) DeleteByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockCalendarFeedRepository) ForOrganization(organizationID uint) repository.CalendarFeedRepository {
+	return m
+}
+
+// newCalendarTestService wires a CalendarService with mocked repositories
+func newCalendarTestService() (services.CalendarService, *MockCalendarFeedRepository, *MockTaskRepository) {
+	feedRepo := new(MockCalendarFeedRepository)
+	taskRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(taskRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+	return services.NewCalendarService(MockTransactor{}, feedRepo, taskRepo, new(MockUserRepository), taskService), feedRepo, taskRepo
+}
+
+// TestCalendarFeedTokenIsHashed verifies that only the token hash is stored and resolves back to active users
+func TestCalendarFeedTokenIsHashed(t *testing.T) {
+	feedRepo := new(MockCalendarFeedRepository)
+	userRepo := new(MockUserRepository)
+	calendarService := services.NewCalendarService(MockTransactor{}, feedRepo, new(MockTaskRepository), userRepo, nil)
+
+	var stored *models.CalendarFeed
+	feedRepo.On("Replace", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.CalendarFeed)
+	})
+
+	token, err := calendarService.CreateFeed(1)
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+	assert.NotEqual(t, token, stored.TokenHash)
+
+	disabled := time.Now()
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	userRepo.On("FindByID", uint(2)).Return(&models.User{ID: 2, DisabledAt: &disabled}, nil)
+	feedRepo.On("FindByTokenHash", stored.TokenHash).Return(stored, nil)
+	feedRepo.On("FindByTokenHash", sha256Hex("disabled")).Return(&models.CalendarFeed{UserID: 2, OrganizationID: 1}, nil)
+	feedRepo.On("FindByTokenHash", mock.Anything).Return(nil, nil)
+
+	feed, err := calendarService.ResolveFeed(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), feed.UserID)
+
+	_, err = calendarService.ResolveFeed("revoked")
+	assert.EqualError(t, err, "feed not found")
+
+	// Feeds stop working while the account is disabled
+	_, err = calendarService.ResolveFeed("disabled")
+	assert.EqualError(t, err, "account is disabled")
+}
+
+// TestCalendarFeedRoundTrip verifies that an exported feed imports back onto the same tasks
+func TestCalendarFeedRoundTrip(t *testing.T) {
+	calendarService, _, taskRepo := newCalendarTestService()
+
+	due := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	stamp := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: 1, UserID: 1, Title: "Pay rent; call landlord, again", Description: "Line one\nLine two", Status: models.StatusPending, DueDate: &due, CreatedAt: stamp, UpdatedAt: stamp},
+		{ID: 2, UserID: 1, Title: strings.Repeat("Long title ", 12), Status: models.StatusCompleted, ICalUID: "external-uid@example.com", CreatedAt: stamp, UpdatedAt: stamp},
+	}
+	taskRepo.On("EachByUserID", uint(1), mock.Anything, mock.Anything).Return([][]models.Task{tasks}, nil)
+
+	var feed bytes.Buffer
+	err := calendarService.WriteFeed(1, services.CalendarTodos, &feed)
+	assert.NoError(t, err)
+	assert.Contains(t, feed.String(), "UID:task-1@task-manager-api\r\n")
+	assert.Contains(t, feed.String(), "UID:external-uid@example.com\r\n")
+	assert.Contains(t, feed.String(), "DUE:20250601T093000Z\r\n")
+	for _, line := range strings.Split(feed.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "lines must be folded")
+	}
+
+	taskRepo.On("GetByIDs", uint(1), []uint{1}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*[]models.Task)) = []models.Task{tasks[0]}
+	})
+	taskRepo.On("GetByICalUIDs", uint(1), mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*[]models.Task)) = []models.Task{tasks[1]}
+	})
+	for _, task := range tasks {
+		task := task
+		taskRepo.On("GetByIDAndUserID", task.ID, uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			*(args.Get(2).(*models.Task)) = task
+		})
+	}
+	taskRepo.On("CreateBatch", []models.Task{}).Return(nil)
+	taskRepo.On("Update", mock.MatchedBy(func(saved *models.Task) bool {
+		return saved.ID == 1 && saved.Title == tasks[0].Title && saved.Description == tasks[0].Description && saved.DueDate.Equal(due)
+	})).Return(nil).Once()
+	taskRepo.On("Update", mock.MatchedBy(func(saved *models.Task) bool {
+		return saved.ID == 2 && saved.Title == tasks[1].Title && saved.Status == models.StatusCompleted
+	})).Return(nil).Once()
+
+	report, err := calendarService.ImportICS(1, &feed, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+	taskRepo.AssertExpectations(t)
+}
+
+// TestImportICSChecksUpdates verifies that updates of existing tasks go through the checks of UpdateTask
+func TestImportICSChecksUpdates(t *testing.T) {
+	calendarService, _, taskRepo := newCalendarTestService()
+
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:task-1@task-manager-api\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	task := models.Task{ID: 1, UserID: 1, Title: "Pay rent", Status: models.StatusPending}
+
+	taskRepo.On("GetByIDs", uint(1), []uint{1}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*[]models.Task)) = []models.Task{task}
+	})
+	taskRepo.On("GetByICalUIDs", uint(1), mock.Anything, mock.Anything).Return(nil)
+	taskRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = task
+	})
+
+	report, err := calendarService.ImportICS(1, strings.NewReader(ics), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ImportRowError{{Row: 1, Error: "invalid title: cannot be empty"}}, report.Errors)
+	assert.Zero(t, report.Imported)
+	taskRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// TestImportICSNewTodos verifies that unknown VTODOs become new tasks keeping their UID
+func TestImportICSNewTodos(t *testing.T) {
+	calendarService, _, taskRepo := newCalendarTestService()
+
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:abc-123\r\nSUMMARY:Renew\r\n  passport\r\nSTATUS:IN-PROCESS\r\nDUE;VALUE=DATE:20250710\r\nBEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\nEND:VTODO\r\nBEGIN:VEVENT\r\nSUMMARY:Ignored\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	taskRepo.On("GetByIDs", uint(1), mock.Anything, mock.Anything).Return(nil)
+	taskRepo.On("GetByICalUIDs", uint(1), []string{"abc-123"}, mock.Anything).Return(nil)
+	taskRepo.On("CreateBatch", mock.MatchedBy(func(saved []models.Task) bool {
+		return len(saved) == 1 && saved[0].ID == 0 && saved[0].ICalUID == "abc-123" &&
+			saved[0].Title == "Renew passport" && saved[0].Description == "" &&
+			saved[0].Status == models.StatusInProgress && saved[0].UserID == 1
+	})).Return(nil)
+
+	report, err := calendarService.ImportICS(1, strings.NewReader(ics), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Total)
+	assert.Equal(t, 1, report.Imported)
+	taskRepo.AssertExpectations(t)
+}
+
+// TestImportICSInvalidFile verifies that non-iCalendar files are rejected
+func TestImportICSInvalidFile(t *testing.T) {
+	calendarService, _, _ := newCalendarTestService()
+
+	_, err := calendarService.ImportICS(1, strings.NewReader("title,status\n"), false)
+
+	assert.EqualError(t, err, "invalid ics: missing BEGIN:VCALENDAR")
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// storyPoints is the maximum of the story points field used in these tests
+var storyPoints = 100.0
+
+// projectFields are the custom fields of project 7 used in these tests
+var projectFields = []models.CustomField{
+	{ProjectID: 7, Key: "customer", Name: "Customer", Type: models.FieldTypeText, Required: true},
+	{ProjectID: 7, Key: "story_points", Name: "Story points", Type: models.FieldTypeNumber, Max: &storyPoints, Position: 1},
+	{ProjectID: 7, Key: "platforms", Name: "Platforms", Type: models.FieldTypeMultiSelect, Options: []string{"web", "ios", "android"}, Position: 2},
+	{ProjectID: 7, Key: "reviewer", Name: "Reviewer", Type: models.FieldTypeUser, Position: 3},
+}
+
+// TestSetCustomFields verifies that owners can replace the custom fields of a project and that field rules are checked
+func TestSetCustomFields(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	customFieldService := services.NewCustomFieldService(projectRepo)
+
+	projectRepo.On("GetMember", uint(7), uint(1)).Return(&models.ProjectMember{ProjectID: 7, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetMember", uint(7), uint(2)).Return(&models.ProjectMember{ProjectID: 7, UserID: 2, Role: models.ProjectRoleMember}, nil)
+	projectRepo.On("GetCustomFields", uint(7)).Return(projectFields, nil)
+	projectRepo.On("SetCustomFields", uint(7), mock.Anything).Return(nil)
+
+	requests := []models.CustomFieldRequest{
+		{Name: " Story points ", Type: models.FieldTypeNumber, Max: &storyPoints},
+		{Name: "Target release (Q?)", Type: models.FieldTypeSelect, Options: []string{" 1.0 ", "2.0"}},
+	}
+	fields, err := customFieldService.SetFields(7, 1, requests)
+	assert.NoError(t, err)
+	assert.Equal(t, "story_points", fields[0].Key)
+	assert.Equal(t, "Story points", fields[0].Name)
+	assert.Equal(t, "target_release_q", fields[1].Key)
+	assert.Equal(t, []string{"1.0", "2.0"}, fields[1].Options)
+	assert.Equal(t, 1, fields[1].Position)
+	projectRepo.AssertCalled(t, "SetCustomFields", uint(7), fields)
+
+	_, err = customFieldService.SetFields(7, 2, requests)
+	assert.EqualError(t, err, "forbidden")
+
+	min, length := 10.0, 0
+	invalid := []struct {
+		request models.CustomFieldRequest
+		err     string
+	}{
+		{models.CustomFieldRequest{Type: models.FieldTypeText}, "invalid field name: must be between 1 and 50 characters"},
+		{models.CustomFieldRequest{Name: "Заказчик", Type: models.FieldTypeText}, `invalid field key "": must start with a lowercase letter and have at most 40 lowercase letters, digits and underscores`},
+		{models.CustomFieldRequest{Name: "Size", Type: "color"}, `invalid field type "color": must be one of text, number, date, select, multi_select, user, checkbox`},
+		{models.CustomFieldRequest{Name: "Customer", Type: models.FieldTypeSelect, Options: []string{"Acme"}}, `invalid field "customer": the type of an existing field cannot change`},
+		{models.CustomFieldRequest{Name: "Done", Type: models.FieldTypeCheckbox, Required: true}, `invalid field "done": checkbox fields cannot be required`},
+		{models.CustomFieldRequest{Name: "Size", Type: models.FieldTypeText, Options: []string{"S"}}, `invalid field "size": only select and multi_select fields have options`},
+		{models.CustomFieldRequest{Name: "Size", Type: models.FieldTypeSelect}, `invalid field "size": must have between 1 and 50 options`},
+		{models.CustomFieldRequest{Name: "Size", Type: models.FieldTypeSelect, Options: []string{"S", "S "}}, `invalid field "size": option "S" is listed more than once`},
+		{models.CustomFieldRequest{Name: "Size", Type: models.FieldTypeNumber, Min: &min, Max: &min}, ""},
+		{models.CustomFieldRequest{Name: "Size", Type: models.FieldTypeNumber, Min: &storyPoints, Max: &min}, `invalid field "size": min must not be greater than max`},
+		{models.CustomFieldRequest{Name: "Notes", Type: models.FieldTypeText, MaxLength: &length}, `invalid field "notes": max_length must be between 1 and 1000`},
+	}
+	for _, tc := range invalid {
+		_, err := customFieldService.SetFields(7, 1, []models.CustomFieldRequest{tc.request})
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tc.err)
+		}
+	}
+
+	_, err = customFieldService.SetFields(7, 1, []models.CustomFieldRequest{
+		{Name: "Size", Type: models.FieldTypeText},
+		{Key: "size", Name: "Other size", Type: models.FieldTypeText},
+	})
+	assert.EqualError(t, err, `invalid fields: key "size" is used more than once`)
+	projectRepo.AssertNumberOfCalls(t, "SetCustomFields", 2)
+}
+
+// TestTaskCustomFieldValues verifies that custom field values are validated against the fields of the task's project
+func TestTaskCustomFieldValues(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID := uint(7)
+	mockProjectRepo.On("GetMember", projectID, uint(1)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 1}, nil)
+	mockProjectRepo.On("GetMember", projectID, uint(2)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 2}, nil)
+	mockProjectRepo.On("GetMember", projectID, uint(3)).Return(nil, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetCustomFields", projectID).Return(projectFields, nil)
+	mockProjectRepo.On("GetBoardColumns", projectID).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("GetAdjacentRank", &projectID, mock.Anything, "", false, uint(1)).Return("", nil)
+
+	task := &models.Task{Title: "Checkout", UserID: 1, ProjectID: &projectID, CustomFields: models.FieldValues{
+		"customer":     " Acme ",
+		"story_points": 5.0,
+		"platforms":    []interface{}{"web", "ios", "web"},
+		"reviewer":     2.0,
+	}}
+	assert.NoError(t, taskService.CreateTask(task))
+	assert.Equal(t, models.FieldValues{"customer": "Acme", "story_points": 5.0, "platforms": []string{"web", "ios"}, "reviewer": uint(2)}, task.CustomFields)
+
+	invalid := []struct {
+		values models.FieldValues
+		err    string
+	}{
+		{models.FieldValues{}, `invalid custom field "customer": a value is required`},
+		{models.FieldValues{"customer": "Acme", "budget": 10.0}, `invalid custom field "budget": not a field of the task's project`},
+		{models.FieldValues{"customer": "Acme", "story_points": "five"}, `invalid custom field "story_points": must be a number`},
+		{models.FieldValues{"customer": "Acme", "story_points": 120.0}, `invalid custom field "story_points": must be at most 100`},
+		{models.FieldValues{"customer": "Acme", "platforms": []interface{}{"desktop"}}, `invalid custom field "platforms": must be a list of the field's options`},
+		{models.FieldValues{"customer": "Acme", "reviewer": 3.0}, `invalid custom field "reviewer": user 3 is not a member of this project`},
+	}
+	for _, tc := range invalid {
+		err := taskService.CreateTask(&models.Task{Title: "Other", UserID: 1, ProjectID: &projectID, CustomFields: tc.values})
+		assert.EqualError(t, err, tc.err)
+	}
+	assert.EqualError(t, taskService.CreateTask(&models.Task{Title: "Personal", UserID: 1, CustomFields: models.FieldValues{"customer": "Acme"}}),
+		`invalid custom field "customer": not a field of the task's project`)
+
+	existing := models.Task{ID: 1, Title: "Checkout", UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "c",
+		CustomFields: models.FieldValues{"customer": "Acme", "story_points": 5.0}}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = existing
+	})
+
+	// Updates merge into the current values and null clears a field
+	update, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{CustomFields: models.FieldValues{"story_points": nil, "platforms": []interface{}{"android"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.FieldValues{"customer": "Acme", "platforms": []string{"android"}}, update.CustomFields)
+
+	_, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{CustomFields: models.FieldValues{"customer": nil}})
+	assert.EqualError(t, err, `invalid custom field "customer": a value is required`)
+
+	// Without values the task keeps its own
+	update, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Title: strPtr("Renamed")})
+	assert.NoError(t, err)
+	assert.Equal(t, existing.CustomFields, update.CustomFields)
+}
+
+// TestListTasksByCustomFields verifies that custom field filters and sorts are checked against the fields of the project
+func TestListTasksByCustomFields(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	mockProjectRepo.On("GetCustomFields", uint(7)).Return(projectFields, nil)
+
+	values, _ := url.ParseQuery("project_id=7&cf.story_points.gte=3&cf.customer=acme&sort=cf.story_points&order=desc")
+	filter, err := models.ParseTaskFilter(values)
+	assert.NoError(t, err)
+	assert.True(t, models.HasFilterParams(values))
+
+	expected := filter
+	expected.Normalize()
+	expected.CustomFields = []models.CustomFieldFilter{
+		{Key: "customer", Op: "eq", Value: "acme", Type: models.FieldTypeText},
+		{Key: "story_points", Op: "gte", Value: "3", Type: models.FieldTypeNumber},
+	}
+	expected.SortFieldType = models.FieldTypeNumber
+	mockRepo.On("GetFiltered", uint(1), expected, mock.Anything).Return(int64(0), nil)
+
+	_, err = taskService.ListTasks(1, filter)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	invalid := map[string]string{
+		"cf.customer=acme":                  "invalid filter: custom fields can only be used with a project_id",
+		"project_id=7&cf.budget=10":         `invalid filter: "budget" is not a custom field of the project`,
+		"project_id=7&cf.story_points=many": `invalid filter on custom field "story_points": must be a number`,
+		"project_id=7&cf.customer.gte=a":    `invalid filter on custom field "customer": only number and date fields support gte`,
+		"project_id=7&sort=cf.budget":       `invalid sort: "budget" is not a custom field of the project`,
+	}
+	for query, message := range invalid {
+		values, _ := url.ParseQuery(query)
+		filter, err := models.ParseTaskFilter(values)
+		assert.NoError(t, err, query)
+		_, err = taskService.ListTasks(1, filter)
+		assert.EqualError(t, err, message, query)
+	}
+
+	for _, query := range []string{"cf.story_points.between=1", "cf.Customer=acme", "sort=cf."} {
+		values, _ := url.ParseQuery(query)
+		_, err := models.ParseTaskFilter(values)
+		assert.Error(t, err, query)
+	}
+}
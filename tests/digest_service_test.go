@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/EmelinDanila/task-manager-api/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDigestRepository is a mock implementation of DigestRepository
+type MockDigestRepository struct {
+	mock.Mock
+}
+
+func (m *MockDigestRepository) GetSettings(userID uint) (*models.DigestSettings, error) {
+	args := m.Called(userID)
+	settings, _ := args.Get(0).(*models.DigestSettings)
+	return settings, args.Error(1)
+}
+
+func (m *MockDigestRepository) SaveSettings(settings *models.DigestSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) GetDue(now time.Time) ([]models.DigestSettings, error) {
+	args := m.Called(now)
+	due, _ := args.Get(0).([]models.DigestSettings)
+	return due, args.Error(1)
+}
+
+func (m *MockDigestRepository) MarkSent(userID uint, sentAt time.Time, next *time.Time) error {
+	args := m.Called(userID, sentAt, next)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) GetDigest(userID uint, since, until, dueUntil time.Time) (*models.Digest, error) {
+	args := m.Called(userID, since, until, dueUntil)
+	digest, _ := args.Get(0).(*models.Digest)
+	return digest, args.Error(1)
+}
+
+// TestDigestSettings verifies that settings are validated and the next digest is scheduled in the user's time zone
+func TestDigestSettings(t *testing.T) {
+	mockRepo := new(MockDigestRepository)
+	digestService := services.NewDigestService(mockRepo, new(MockUserRepository), services.NewMemoryMailer())
+
+	mockRepo.On("GetSettings", uint(1)).Return(nil, nil)
+	mockRepo.On("SaveSettings", mock.Anything).Return(nil)
+
+	settings, err := digestService.GetSettings(1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DigestOff, settings.Frequency)
+	assert.Nil(t, settings.NextSendAt)
+
+	weekly, sendTime, zone, monday := models.DigestWeekly, "07:30", "Europe/Berlin", 1
+	settings, err = digestService.UpdateSettings(1, models.DigestSettingsRequest{Frequency: &weekly, SendTime: &sendTime, TimeZone: &zone, Weekday: &monday})
+	require.NoError(t, err)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	next := settings.NextSendAt.In(berlin)
+	assert.True(t, next.After(time.Now()))
+	assert.True(t, next.Before(time.Now().Add(7*24*time.Hour+time.Hour)))
+	assert.Equal(t, time.Monday, next.Weekday())
+	assert.Equal(t, "07:30", next.Format("15:04"))
+
+	for _, request := range []struct {
+		request models.DigestSettingsRequest
+		err     string
+	}{
+		{models.DigestSettingsRequest{Frequency: strPtr("hourly")}, "invalid frequency: must be off, daily or weekly"},
+		{models.DigestSettingsRequest{SendTime: strPtr("25:00")}, "invalid send_time: must be HH:MM"},
+		{models.DigestSettingsRequest{Weekday: intPtr(7)}, "invalid weekday: must be 0 (Sunday) to 6 (Saturday)"},
+		{models.DigestSettingsRequest{TimeZone: strPtr("Mars/Olympus")}, "invalid time_zone: must be an IANA time zone such as Europe/Berlin"},
+	} {
+		_, err := digestService.UpdateSettings(1, request.request)
+		assert.EqualError(t, err, request.err)
+	}
+	mockRepo.AssertNumberOfCalls(t, "SaveSettings", 1)
+}
+
+// TestSendDigest verifies that due digests are rendered as text and HTML, delivered over SMTP
+// with a one-click unsubscribe link, and that the link turns digests off
+func TestSendDigest(t *testing.T) {
+	sink := testutils.NewSMTPSink(t)
+	mockRepo := new(MockDigestRepository)
+	mockUserRepo := new(MockUserRepository)
+	mailer := services.NewSMTPMailer(sink.Host(), sink.Port(), "", "", "digest@example.com")
+	digestService := services.NewDigestService(mockRepo, mockUserRepo, mailer)
+
+	now := time.Date(2024, 5, 6, 6, 0, 0, 0, time.UTC) // 08:00 in Berlin
+	lastSent := now.Add(-24 * time.Hour)
+	due := now.Add(3 * time.Hour)
+	settings := models.DigestSettings{UserID: 1, Frequency: models.DigestDaily, SendTime: "08:00", TimeZone: "Europe/Berlin", LastSentAt: &lastSent}
+	digest := &models.Digest{
+		DueSoon:   []models.Task{{ID: 1, Title: "Ship <release>", Status: models.StatusPending, DueDate: &due}},
+		Completed: []models.Task{{ID: 2, Title: "Write notes", Status: models.StatusCompleted}},
+	}
+	mockRepo.On("GetDue", now).Return([]models.DigestSettings{settings, {UserID: 2, Frequency: models.DigestDaily, SendTime: "08:00", TimeZone: "UTC"}}, nil)
+	mockUserRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Email: "ann@example.com"}, nil)
+	mockUserRepo.On("FindByID", uint(2)).Return(&models.User{ID: 2, Email: "bob@example.com"}, nil)
+	mockRepo.On("GetDigest", uint(1), lastSent, now, now.Add(24*time.Hour)).Return(digest, nil)
+	mockRepo.On("GetDigest", uint(2), now.Add(-24*time.Hour), now, now.Add(24*time.Hour)).Return(&models.Digest{}, nil)
+	mockRepo.On("MarkSent", mock.Anything, now, mock.Anything).Return(nil)
+
+	sent, err := digestService.SendDue(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent) // Bob's digest is empty
+	nextBerlin := time.Date(2024, 5, 7, 6, 0, 0, 0, time.UTC)
+	mockRepo.AssertCalled(t, "MarkSent", uint(1), now, &nextBerlin)
+	nextUTC := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	mockRepo.AssertCalled(t, "MarkSent", uint(2), now, &nextUTC)
+
+	messages := sink.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"ann@example.com"}, messages[0].To)
+	message, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, "Your daily Task Manager digest", message.Header.Get("Subject"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", message.Header.Get("List-Unsubscribe-Post"))
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	assert.Contains(t, parts["text/plain"], "Due soon:\r\n- Ship <release> (Pending, due Mon, May 6 11:00 CEST)")
+	assert.Contains(t, parts["text/plain"], "Completed:\r\n- Write notes (Completed)")
+	assert.NotContains(t, parts["text/plain"], "Overdue")
+	assert.Contains(t, parts["text/html"], "<strong>Ship &lt;release&gt;</strong>")
+
+	// The one-click link turns digests off
+	link := strings.Trim(message.Header.Get("List-Unsubscribe"), "<>")
+	assert.Contains(t, parts["text/plain"], link)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	mockRepo.On("GetSettings", uint(1)).Return(&settings, nil)
+	mockRepo.On("SaveSettings", mock.MatchedBy(func(s *models.DigestSettings) bool {
+		return s.UserID == 1 && s.Frequency == models.DigestOff && s.NextSendAt == nil
+	})).Return(nil)
+	assert.NoError(t, digestService.Unsubscribe(parsed.Query().Get("token")))
+	mockRepo.AssertNumberOfCalls(t, "SaveSettings", 1)
+
+	assert.EqualError(t, digestService.Unsubscribe("forged"), "invalid or expired unsubscribe link")
+}
+
+func strPtr(s string) *string { return &s }
+
+func intPtr(i int) *int { return &i }
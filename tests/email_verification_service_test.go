@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// verificationTokenFromMail extracts the token of the link in a verification email
+func verificationTokenFromMail(t *testing.T, mail services.Mail) string {
+	link := regexp.MustCompile(`https?://\S+`).FindString(mail.Body)
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+// TestVerifyEmail verifies that the emailed link marks the address as verified
+func TestVerifyEmail(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewEmailVerificationService(userRepo, mailer)
+
+	user := &models.User{ID: 4, Email: "user@example.com"}
+	userRepo.On("SetVerificationSentAt", uint(4), mock.Anything).Return(nil)
+	userRepo.On("FindByID", uint(4)).Return(user, nil)
+	userRepo.On("MarkVerified", uint(4), mock.Anything).Return(nil)
+
+	assert.NoError(t, service.SendVerification(user))
+	assert.Len(t, mailer.Sent(), 1)
+
+	token := verificationTokenFromMail(t, mailer.Sent()[0])
+	assert.NoError(t, service.Verify(token))
+	userRepo.AssertCalled(t, "MarkVerified", uint(4), mock.Anything)
+
+	// The link cannot be used as a login token
+	_, err := services.NewAuthService().VerifyToken(token)
+	assert.Error(t, err)
+}
+
+// TestVerifyEmailRejectsOtherTokens verifies that login tokens and links for an old email are rejected
+func TestVerifyEmailRejectsOtherTokens(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewEmailVerificationService(userRepo, mailer)
+
+	userRepo.On("SetVerificationSentAt", uint(4), mock.Anything).Return(nil)
+	userRepo.On("FindByID", uint(4)).Return(&models.User{ID: 4, Email: "new@example.com"}, nil)
+
+	assert.NoError(t, service.SendVerification(&models.User{ID: 4, Email: "old@example.com"}))
+	token := verificationTokenFromMail(t, mailer.Sent()[0])
+	assert.EqualError(t, service.Verify(token), "invalid or expired verification link")
+
+	loginToken, _ := services.NewAuthService().GenerateToken(4)
+	assert.EqualError(t, service.Verify(loginToken), "invalid or expired verification link")
+	userRepo.AssertNotCalled(t, "MarkVerified", mock.Anything, mock.Anything)
+}
+
+// TestResendVerificationThrottled verifies that verification emails cannot be requested in quick succession
+func TestResendVerificationThrottled(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewEmailVerificationService(userRepo, mailer)
+
+	sentAt := time.Now().Add(-30 * time.Second)
+	userRepo.On("FindByEmail", "user@example.com").Return(&models.User{ID: 4, Email: "user@example.com", VerificationSentAt: &sentAt}, nil)
+
+	err := service.Resend("user@example.com")
+
+	var throttled *services.ThrottledError
+	assert.True(t, errors.As(err, &throttled))
+	assert.InDelta(t, 90, throttled.RetryAfterSeconds(), 2)
+	assert.Empty(t, mailer.Sent())
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockTransactor runs the transaction function directly
+type MockTransactor struct{}
+
+func (MockTransactor) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+// MockTagRepository is a mock implementation of TagRepository
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) GetByUserID(userID uint, tags *[]models.Tag) error {
+	args := m.Called(userID, tags)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) FindOrCreate(userID uint, names []string) ([]models.Tag, error) {
+	args := m.Called(userID, names)
+	tags, _ := args.Get(0).([]models.Tag)
+	return tags, args.Error(1)
+}
+
+func (m *MockTagRepository) WithTx(tx *gorm.DB) repository.TagRepository {
+	return m
+}
+
+func (m *MockTagRepository) ForOrganization(organizationID uint) repository.TagRepository {
+	return m
+}
+
+// newImportTestService wires an ImportService with mocked repositories
+func newImportTestService() (services.ImportService, *MockProjectRepository, *MockTagRepository, *MockTaskRepository) {
+	projectRepo := new(MockProjectRepository)
+	tagRepo := new(MockTagRepository)
+	taskRepo := new(MockTaskRepository)
+	projectRepo.On("GetWorkflowStatuses", mock.Anything).Return(nil, nil)
+	projectRepo.On("GetCustomFields", mock.Anything).Return(nil, nil)
+	taskService := services.NewTaskService(taskRepo, projectRepo, new(MockOrganizationRepository), nil)
+	return services.NewImportService(MockTransactor{}, projectRepo, tagRepo, taskRepo, taskService), projectRepo, tagRepo, taskRepo
+}
+
+const trelloExport = `{
+	"name": "Website",
+	"lists": [
+		{"id": "l1", "name": "To Do"},
+		{"id": "l2", "name": "Doing"},
+		{"id": "l3", "name": "Old", "closed": true}
+	],
+	"labels": [
+		{"id": "b1", "name": "bug", "color": "red"},
+		{"id": "b2", "name": "", "color": "green"}
+	],
+	"cards": [
+		{"name": "Fix header", "desc": "Broken on mobile", "idList": "l2", "idLabels": ["b1", "b2"], "due": "2025-04-01T12:00:00.000Z"},
+		{"name": "Write copy", "idList": "l1", "idLabels": [], "dueComplete": true},
+		{"name": "Archived", "idList": "l1", "closed": true},
+		{"name": "In archived list", "idList": "l3"}
+	]
+}`
+
+// TestImportTrello verifies that a board becomes a project, labels become tags and lists become statuses
+func TestImportTrello(t *testing.T) {
+	importService, projectRepo, tagRepo, taskRepo := newImportTestService()
+
+	projectRepo.On("GetByUserID", uint(1), mock.Anything).Return(nil)
+	projectRepo.On("Create", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Project).ID = 9
+	})
+	projectRepo.On("GetMember", uint(9), uint(1)).Return(&models.ProjectMember{ProjectID: 9, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	tagRepo.On("FindOrCreate", uint(1), []string{"bug", "green"}).Return([]models.Tag{{ID: 1, Name: "bug"}, {ID: 2, Name: "green"}}, nil)
+	taskRepo.On("CreateBatch", mock.MatchedBy(func(tasks []models.Task) bool {
+		return len(tasks) == 2 &&
+			tasks[0].Title == "Fix header" && tasks[0].Status == models.StatusInProgress && len(tasks[0].Tags) == 2 && tasks[0].DueDate != nil &&
+			tasks[1].Status == models.StatusCompleted && *tasks[1].ProjectID == 9
+	})).Return(nil)
+
+	report, err := importService.Import(1, services.SourceTrello, strings.NewReader(trelloExport), "", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"Website"}, report.Projects)
+	assert.Equal(t, []string{"bug", "green"}, report.Tags)
+	taskRepo.AssertExpectations(t)
+}
+
+// TestImportChecksExistingProjects verifies that rows are checked against the project they are imported into
+func TestImportChecksExistingProjects(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	taskRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(taskRepo, projectRepo, new(MockOrganizationRepository), nil)
+	importService := services.NewImportService(MockTransactor{}, projectRepo, new(MockTagRepository), taskRepo, taskService)
+
+	projectRepo.On("GetByUserID", uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*[]models.Project)) = []models.Project{{ID: 9, Name: "Website", OwnerID: 1}}
+	})
+	projectRepo.On("GetMember", uint(9), uint(1)).Return(&models.ProjectMember{ProjectID: 9, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetWorkflowStatuses", uint(9)).Return(reviewWorkflow, nil)
+	projectRepo.On("GetCustomFields", uint(9)).Return([]models.CustomField{{ProjectID: 9, Key: "team", Name: "Team", Type: models.FieldTypeText, Required: true}}, nil)
+
+	report, err := importService.Import(1, services.SourceTrello, strings.NewReader(trelloExport), "", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ImportRowError{
+		{Row: 1, Error: `invalid custom field "team": a value is required`},
+		{Row: 2, Error: `invalid custom field "team": a value is required`},
+	}, report.Errors)
+	projectRepo.AssertNotCalled(t, "Create", mock.Anything)
+	taskRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportChecksWorkflowOnce verifies that statuses are checked once against the project's workflow and reported per row
+func TestImportChecksWorkflowOnce(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	taskRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(taskRepo, projectRepo, new(MockOrganizationRepository), nil)
+	importService := services.NewImportService(MockTransactor{}, projectRepo, new(MockTagRepository), taskRepo, taskService)
+
+	projectRepo.On("GetByUserID", uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*[]models.Project)) = []models.Project{{ID: 9, Name: "Website", OwnerID: 1}}
+	})
+	projectRepo.On("GetMember", uint(9), uint(1)).Return(&models.ProjectMember{ProjectID: 9, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetWorkflowStatuses", uint(9)).Return([]models.WorkflowStatus{
+		{ProjectID: 9, Name: "Open", Category: models.StatusCategoryTodo},
+		{ProjectID: 9, Name: "Closed", Category: models.StatusCategoryDone, Position: 1},
+	}, nil)
+	projectRepo.On("GetCustomFields", uint(9)).Return(nil, nil)
+
+	// The workflow has no status for cards in progress
+	report, err := importService.Import(1, services.SourceTrello, strings.NewReader(trelloExport), "", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ImportRowError{{Row: 1, Error: "invalid task status"}}, report.Errors)
+	assert.Equal(t, 0, report.Imported)
+	projectRepo.AssertNumberOfCalls(t, "GetWorkflowStatuses", 1)
+	taskRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportTodoistDryRun verifies Todoist parsing without saving anything
+func TestImportTodoistDryRun(t *testing.T) {
+	importService, projectRepo, _, taskRepo := newImportTestService()
+	projectRepo.On("GetByUserID", uint(1), mock.Anything).Return(nil)
+
+	backup := `{
+		"projects": [{"id": "100", "name": "Inbox", "inbox_project": true}, {"id": 200, "name": "Home"}],
+		"sections": [{"id": "s1", "name": "In progress"}],
+		"items": [
+			{"content": "Buy milk", "project_id": "100", "checked": 0, "labels": ["errand"]},
+			{"content": "Paint fence", "project_id": 200, "section_id": "s1", "checked": false, "due": {"date": "2025-05-20"}},
+			{"content": "Gone", "project_id": 200, "is_deleted": 1}
+		]
+	}`
+
+	report, err := importService.Import(1, services.SourceTodoist, strings.NewReader(backup), "", true)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []string{"Home"}, report.Projects)
+	assert.Equal(t, []string{"errand"}, report.Tags)
+	assert.Empty(t, report.Errors)
+	taskRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportGitHubReportsInvalidIssues verifies that invalid issues are reported and pull requests skipped
+func TestImportGitHubReportsInvalidIssues(t *testing.T) {
+	importService, projectRepo, _, taskRepo := newImportTestService()
+	projectRepo.On("GetByUserID", uint(1), mock.Anything).Return(nil)
+
+	issues := `[
+		{"number": 1, "title": "Crash on start", "state": "open", "labels": [{"name": "in progress"}], "repository_url": "https://api.github.com/repos/acme/app"},
+		{"number": 2, "title": "", "state": "closed"},
+		{"number": 3, "title": "Add feature", "state": "open", "pull_request": {"url": "https://api.github.com/repos/acme/app/pulls/3"}}
+	]`
+
+	report, err := importService.Import(1, services.SourceGitHub, strings.NewReader(issues), "", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []models.ImportRowError{{Row: 2, Error: "task title cannot be empty"}}, report.Errors)
+	assert.Equal(t, []string{"acme/app"}, report.Projects)
+	taskRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportInvalidFile verifies that unreadable export files are rejected
+func TestImportInvalidFile(t *testing.T) {
+	importService, _, _, _ := newImportTestService()
+
+	_, err := importService.Import(1, services.SourceGitHub, strings.NewReader(`{"not": "an array"}`), "", false)
+
+	assert.EqualError(t, err, "invalid github export: expected an array of issues")
+}
@@ -0,0 +1,295 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/controllers"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockInvitationRepository is a mock implementation of InvitationRepository
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetByID(organizationID, id uint) (*models.Invitation, error) {
+	args := m.Called(organizationID, id)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetPending(organizationID uint, now time.Time) ([]models.Invitation, error) {
+	args := m.Called(organizationID, now)
+	invitations, _ := args.Get(0).([]models.Invitation)
+	return invitations, args.Error(1)
+}
+
+func (m *MockInvitationRepository) FindByTokenHash(tokenHash string) (*models.Invitation, error) {
+	args := m.Called(tokenHash)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+
+func (m *MockInvitationRepository) Renew(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Delete(organizationID, id uint) error {
+	args := m.Called(organizationID, id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Accept(invitation *models.Invitation, member *models.OrganizationMember, at time.Time) (bool, error) {
+	args := m.Called(invitation, member, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInvitationRepository) WithTx(tx *gorm.DB) repository.InvitationRepository {
+	return m
+}
+
+// failingMailer fails every send, like an unreachable SMTP server
+type failingMailer struct{}
+
+func (failingMailer) Send(mail services.Mail) error {
+	return errors.New("smtp: connection refused")
+}
+
+// recordingTransactor runs the transaction function directly and records whether it was rolled back
+type recordingTransactor struct {
+	rolledBack int
+}
+
+func (r *recordingTransactor) Transaction(fn func(tx *gorm.DB) error) error {
+	err := fn(nil)
+	if err != nil {
+		r.rolledBack++
+	}
+	return err
+}
+
+var invitationTokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+// invitationToken extracts the token from the link in an invitation email
+func invitationToken(t *testing.T, mail services.Mail) string {
+	match := invitationTokenPattern.FindStringSubmatch(mail.Body)
+	if match == nil {
+		t.Fatalf("No invitation link in %q", mail.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("Invalid invitation link: %v", err)
+	}
+	return token
+}
+
+// TestInvitationInvite verifies who can invite and that the link is emailed
+func TestInvitationInvite(t *testing.T) {
+	repo := new(MockInvitationRepository)
+	orgRepo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewInvitationService(MockTransactor{}, repo, orgRepo, userRepo, mailer)
+
+	orgRepo.On("GetMember", uint(1), uint(10)).Return(membership(10, models.OrganizationRoleOwner), nil)
+	orgRepo.On("GetMember", uint(1), uint(11)).Return(membership(11, models.OrganizationRoleAdmin), nil)
+	orgRepo.On("GetMember", uint(1), uint(12)).Return(membership(12, models.OrganizationRoleMember), nil)
+	orgRepo.On("GetMember", uint(1), uint(20)).Return(membership(20, models.OrganizationRoleMember), nil)
+	orgRepo.On("GetByID", uint(1)).Return(&models.Organization{ID: 1, Name: "Acme"}, nil)
+	userRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+	userRepo.On("FindByEmail", "old@example.com").Return(&models.User{ID: 20, Email: "old@example.com"}, nil)
+	repo.On("Create", mock.Anything).Return(nil)
+
+	invitation, err := service.Invite(1, 11, "new@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrganizationRoleMember, invitation.Role)
+	assert.WithinDuration(t, time.Now().Add(services.InvitationTTL), invitation.ExpiresAt, time.Minute)
+
+	// Only the hash of the emailed token is stored
+	sent := mailer.Sent()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, "new@example.com", sent[0].To)
+	assert.Contains(t, sent[0].Subject, `"Acme"`)
+	token := invitationToken(t, sent[0])
+	assert.NotEqual(t, token, invitation.TokenHash)
+
+	_, err = service.Invite(1, 11, "new@example.com", models.OrganizationRoleAdmin)
+	assert.EqualError(t, err, "forbidden")
+	_, err = service.Invite(1, 12, "new@example.com", "")
+	assert.EqualError(t, err, "forbidden")
+	_, err = service.Invite(1, 10, "new@example.com", "guest")
+	assert.EqualError(t, err, "invalid role: must be owner, admin or member")
+	_, err = service.Invite(1, 10, "old@example.com", "")
+	assert.EqualError(t, err, "user is already a member")
+	assert.Len(t, mailer.Sent(), 1)
+}
+
+// TestInvitationAccept verifies that a token works once, before it expires, for the invited address
+func TestInvitationAccept(t *testing.T) {
+	repo := new(MockInvitationRepository)
+	orgRepo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	service := services.NewInvitationService(MockTransactor{}, repo, orgRepo, userRepo, services.NewMemoryMailer())
+
+	pending := &models.Invitation{ID: 5, OrganizationID: 1, Email: "New@Example.com", Role: models.OrganizationRoleAdmin, ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &models.Invitation{ID: 6, OrganizationID: 1, Email: "new@example.com", ExpiresAt: time.Now().Add(-time.Hour)}
+	repo.On("FindByTokenHash", sha256Hex("pending")).Return(pending, nil)
+	repo.On("FindByTokenHash", sha256Hex("expired")).Return(expired, nil)
+	repo.On("FindByTokenHash", mock.Anything).Return(nil, nil)
+	userRepo.On("FindByID", uint(20)).Return(&models.User{ID: 20, Email: "new@example.com"}, nil)
+	userRepo.On("FindByID", uint(21)).Return(&models.User{ID: 21, Email: "other@example.com"}, nil)
+	orgRepo.On("GetMember", uint(1), uint(20)).Return(nil, nil)
+	repo.On("Accept", pending, mock.Anything, mock.Anything).Return(true, nil).Once()
+	repo.On("Accept", pending, mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := service.Accept("pending", 21)
+	assert.EqualError(t, err, "invalid invitation: it was sent to another email address")
+	_, err = service.Accept("expired", 20)
+	assert.EqualError(t, err, "invalid or expired invitation")
+	_, err = service.Accept("unknown", 20)
+	assert.EqualError(t, err, "invalid or expired invitation")
+
+	member, err := service.Accept("pending", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), member.OrganizationID)
+	assert.Equal(t, models.OrganizationRoleAdmin, member.Role)
+
+	// A token accepted concurrently is not accepted twice
+	_, err = service.Accept("pending", 20)
+	assert.EqualError(t, err, "invalid or expired invitation")
+}
+
+// TestInvitationResend verifies that resending issues a new link for pending invitations only
+func TestInvitationResend(t *testing.T) {
+	repo := new(MockInvitationRepository)
+	orgRepo := new(MockOrganizationRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewInvitationService(MockTransactor{}, repo, orgRepo, new(MockUserRepository), mailer)
+
+	accepted := time.Now()
+	orgRepo.On("GetMember", uint(1), uint(11)).Return(membership(11, models.OrganizationRoleAdmin), nil)
+	orgRepo.On("GetByID", uint(1)).Return(&models.Organization{ID: 1, Name: "Acme"}, nil)
+	repo.On("GetByID", uint(1), uint(5)).Return(&models.Invitation{ID: 5, OrganizationID: 1, Email: "new@example.com", TokenHash: "old", ExpiresAt: time.Now()}, nil)
+	repo.On("GetByID", uint(1), uint(6)).Return(&models.Invitation{ID: 6, OrganizationID: 1, AcceptedAt: &accepted}, nil)
+	repo.On("GetByID", uint(1), uint(7)).Return(nil, nil)
+	repo.On("Renew", mock.Anything).Return(nil)
+
+	invitation, err := service.Resend(1, 11, 5)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "old", invitation.TokenHash)
+	assert.True(t, invitation.ExpiresAt.After(time.Now().Add(services.InvitationTTL-time.Minute)))
+	assert.Equal(t, sha256Hex(invitationToken(t, mailer.Sent()[0])), invitation.TokenHash)
+
+	_, err = service.Resend(1, 11, 6)
+	assert.EqualError(t, err, "invalid request: invitation was already accepted")
+	_, err = service.Resend(1, 11, 7)
+	assert.EqualError(t, err, "invitation not found")
+}
+
+// TestInvitationEmailFailure verifies that invitations whose email cannot be sent are not kept
+func TestInvitationEmailFailure(t *testing.T) {
+	repo := new(MockInvitationRepository)
+	orgRepo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	transactor := &recordingTransactor{}
+	service := services.NewInvitationService(transactor, repo, orgRepo, userRepo, failingMailer{})
+
+	orgRepo.On("GetMember", uint(1), uint(11)).Return(membership(11, models.OrganizationRoleAdmin), nil)
+	orgRepo.On("GetByID", uint(1)).Return(&models.Organization{ID: 1, Name: "Acme"}, nil)
+	userRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+	repo.On("Create", mock.Anything).Return(nil)
+	repo.On("GetByID", uint(1), uint(5)).Return(&models.Invitation{ID: 5, OrganizationID: 1, Email: "new@example.com"}, nil)
+	repo.On("Renew", mock.Anything).Return(nil)
+
+	_, err := service.Invite(1, 11, "new@example.com", "")
+	assert.EqualError(t, err, "smtp: connection refused")
+	_, err = service.Resend(1, 11, 5)
+	assert.EqualError(t, err, "smtp: connection refused")
+	assert.Equal(t, 2, transactor.rolledBack, "the stored and renewed invitations are rolled back")
+}
+
+// TestShowInvitation verifies that the emailed link shows the invitation without signing in
+func TestShowInvitation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(MockInvitationRepository)
+	service := services.NewInvitationService(MockTransactor{}, repo, new(MockOrganizationRepository), new(MockUserRepository), services.NewMemoryMailer())
+	controller := controllers.NewInvitationController(service)
+
+	repo.On("FindByTokenHash", sha256Hex("invite")).Return(&models.Invitation{ID: 5, OrganizationID: 3, Email: "new@example.com", Role: models.OrganizationRoleMember, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	repo.On("FindByTokenHash", mock.Anything).Return(nil, nil)
+
+	router := gin.New()
+	router.GET("/invitations/accept", controller.Show)
+	show := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/invitations/accept"+query, nil))
+		return w
+	}
+
+	w := show("?token=invite")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"organization_id":3`)
+	assert.NotContains(t, w.Body.String(), "token")
+	assert.Equal(t, http.StatusBadRequest, show("?token=unknown").Code)
+	assert.Equal(t, http.StatusBadRequest, show("").Code)
+}
+
+// TestRegisterWithInvitation verifies that registering with an invitation joins the organization
+func TestRegisterWithInvitation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(MockInvitationRepository)
+	orgRepo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	invitations := services.NewInvitationService(MockTransactor{}, repo, orgRepo, userRepo, services.NewMemoryMailer())
+	controller := controllers.NewAuthController(services.NewAuthService(), userRepo, nil, nil, services.DefaultPasswordPolicy(), invitations)
+
+	invitation := &models.Invitation{ID: 5, OrganizationID: 3, Email: "new@example.com", Role: models.OrganizationRoleMember, ExpiresAt: time.Now().Add(time.Hour)}
+	repo.On("FindByTokenHash", sha256Hex("invite")).Return(invitation, nil)
+	userRepo.On("FindByEmail", mock.Anything).Return(nil, nil)
+	userRepo.On("CreateUser", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).ID = 20
+	}).Return(nil)
+	userRepo.On("FindByID", uint(20)).Return(&models.User{ID: 20, Email: "new@example.com"}, nil)
+	orgRepo.On("GetMember", uint(3), uint(20)).Return(nil, nil)
+	repo.On("Accept", invitation, mock.Anything, mock.Anything).Return(true, nil)
+
+	router := gin.New()
+	router.POST("/register", controller.RegisterUser)
+	register := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/register", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Someone else's invitation is rejected before an account is created
+	w := register(`{"email":"other@example.com","password":"Corr3ct-Horse-Battery","invitation_token":"invite"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "another email address")
+	userRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+
+	w = register(`{"email":"new@example.com","password":"Corr3ct-Horse-Battery","invitation_token":"invite"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"organization_id":3`)
+	repo.AssertCalled(t, "Accept", invitation, &models.OrganizationMember{OrganizationID: 3, UserID: 20, Role: models.OrganizationRoleMember}, mock.Anything)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func testThrottleConfig() services.LoginThrottleConfig {
+	return services.LoginThrottleConfig{
+		Window:           time.Minute,
+		AccountThreshold: 3,
+		IPThreshold:      5,
+		BaseLockout:      time.Minute,
+		MaxLockout:       3 * time.Minute,
+	}
+}
+
+// TestLoginThrottleLocksAccount verifies the progressive account lockout and unlocking
+func TestLoginThrottleLocksAccount(t *testing.T) {
+	service := services.NewLoginThrottleService(repository.NewMemoryLoginAttemptStore(), testThrottleConfig())
+
+	var locks []time.Duration
+	for i := 0; i < 5; i++ {
+		wait, err := service.RecordFailure("User@Example.com", "")
+		assert.NoError(t, err)
+		locks = append(locks, wait)
+	}
+	assert.Equal(t, []time.Duration{0, 0, time.Minute, 2 * time.Minute, 3 * time.Minute}, locks)
+
+	wait, err := service.Check("user@example.com", "198.51.100.1")
+	assert.NoError(t, err)
+	assert.InDelta(t, (3 * time.Minute).Seconds(), wait.Seconds(), 1)
+
+	assert.NoError(t, service.Unlock("user@example.com", ""))
+	wait, _ = service.Check("user@example.com", "")
+	assert.Zero(t, wait)
+}
+
+// TestLoginThrottleSuccessKeepsIPCounter verifies that a successful login only resets the account
+func TestLoginThrottleSuccessKeepsIPCounter(t *testing.T) {
+	service := services.NewLoginThrottleService(repository.NewMemoryLoginAttemptStore(), testThrottleConfig())
+
+	for i := 0; i < 4; i++ {
+		_, _ = service.RecordFailure("victim@example.com", "203.0.113.7")
+		if i == 1 {
+			assert.NoError(t, service.RecordSuccess("victim@example.com"))
+		}
+	}
+	wait, _ := service.Check("victim@example.com", "")
+	assert.Zero(t, wait, "account was reset by the successful login")
+
+	wait, _ = service.RecordFailure("other@example.com", "203.0.113.7")
+	assert.Equal(t, time.Minute, wait, "the fifth failure from the IP locks it")
+	wait, _ = service.Check("third@example.com", "203.0.113.7")
+	assert.Greater(t, wait, time.Duration(0))
+}
+
+// TestLoginThrottleMiddleware verifies that locked logins get 429 with Retry-After
+func TestLoginThrottleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := services.NewLoginThrottleService(repository.NewMemoryLoginAttemptStore(), testThrottleConfig())
+
+	router := gin.New()
+	router.POST("/login", middleware.LoginThrottle(service, middleware.EmailAccount), func(c *gin.Context) {
+		var body struct {
+			Email string `json:"email"`
+		}
+		_ = c.ShouldBindJSON(&body)
+		assert.Equal(t, "user@example.com", body.Email, "the handler still reads the body")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	})
+
+	login := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/login", bytes.NewBufferString(`{"email": "user@example.com", "password": "wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login().Code)
+	}
+	w := login()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+// TestLoginThrottleTwoFactor verifies that wrong second factors count against the account the
+// mfa_token was issued to, whatever the client IP
+func TestLoginThrottleTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := services.NewLoginThrottleService(repository.NewMemoryLoginAttemptStore(), testThrottleConfig())
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Email: "user@example.com"}, nil)
+	twoFactor := services.NewTwoFactorService(userRepo, new(MockTwoFactorRepository), repository.NewMemoryLoginAttemptStore())
+	mfaToken, err := twoFactor.StartLogin(1, nil)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/login/2fa", middleware.LoginThrottle(service, middleware.MFATokenAccount(twoFactor)), func(c *gin.Context) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid two-factor code"})
+	})
+
+	for i, ip := range []string{"198.51.100.1:1000", "198.51.100.2:1000", "198.51.100.3:1000"} {
+		req := httptest.NewRequest("POST", "/login/2fa", bytes.NewBufferString(`{"mfa_token": "`+mfaToken+`", "code": "000000"}`))
+		req.RemoteAddr = ip
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, i)
+	}
+	wait, err := service.Check("user@example.com", "")
+	assert.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0))
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/EmelinDanila/task-manager-api/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -30,7 +31,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	c.Request = req
 
 	//Call Middleware
-	middleware.AuthMiddleware(authService)(c)
+	middleware.AuthMiddleware(authService, nil)(c)
 
 	//Check the status code
 	assert.Equal(t, http.StatusOK, w.Code, "Valid token should allow access")
@@ -47,7 +48,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
 
-	middleware.AuthMiddleware(authService)(c)
+	middleware.AuthMiddleware(authService, nil)(c)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code, "Invalid token should return 401")
 }
@@ -62,7 +63,7 @@ func TestAuthMiddleware_MissingToken(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
 
-	middleware.AuthMiddleware(authService)(c)
+	middleware.AuthMiddleware(authService, nil)(c)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code, "Missing token should return 401")
 }
@@ -78,7 +79,7 @@ func TestAuthMiddleware_InvalidHeaderFormat(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
 
-	middleware.AuthMiddleware(authService)(c)
+	middleware.AuthMiddleware(authService, nil)(c)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code, "Invalid header format should return 401")
 }
@@ -104,3 +105,46 @@ func TestGetUserID_Missing(t *testing.T) {
 	assert.False(t, ok, "UserID should not be present in context")
 	assert.Equal(t, uint(0), retrievedUserID, "Default UserID should be 0 when not present")
 }
+
+// TestRequireScopes_Insufficient verifies that a token without the scope gets 403 and a WWW-Authenticate challenge
+func TestRequireScopes_Insufficient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService()
+	token, _ := authService.GenerateScopedToken(123, []string{models.ScopeTasksRead})
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, nil))
+	router.GET("/tasks", middleware.RequireScopes(models.ScopeTasksRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/tasks", middleware.RequireScopes(models.ScopeTasksWrite), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	req := httptest.NewRequest("GET", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "Read scope should allow GET")
+
+	req = httptest.NewRequest("POST", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "Missing write scope should return 403")
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `scope="tasks:write"`)
+}
+
+// TestRequireScopes_LoginToken verifies that default login tokens have every scope
+func TestRequireScopes_LoginToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService()
+	token, _ := authService.GenerateToken(123)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, nil))
+	router.DELETE("/api-keys/1", middleware.RequireScopes(models.ScopeAdmin), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest("DELETE", "/api-keys/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
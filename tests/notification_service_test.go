@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationRepository is a mock implementation of NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) CreateBatch(notifications []models.Notification) error {
+	args := m.Called(notifications)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetPage(userID uint, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	args := m.Called(userID, filter)
+	page, _ := args.Get(0).(*models.NotificationPage)
+	return page, args.Error(1)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(userID, id uint, at time.Time) (bool, error) {
+	args := m.Called(userID, id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	args := m.Called(userID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetPreferences(userID uint) ([]models.NotificationPreference, error) {
+	args := m.Called(userID)
+	preferences, _ := args.Get(0).([]models.NotificationPreference)
+	return preferences, args.Error(1)
+}
+
+func (m *MockNotificationRepository) SetPreferences(userID uint, preferences map[string]bool) error {
+	args := m.Called(userID, preferences)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetDisabledUsers(userIDs []uint, notificationType string) ([]uint, error) {
+	args := m.Called(userIDs, notificationType)
+	disabled, _ := args.Get(0).([]uint)
+	return disabled, args.Error(1)
+}
+
+// recipientsOf returns the users of a batch of notifications
+func recipientsOf(notifications []models.Notification) []uint {
+	ids := []uint{}
+	for _, notification := range notifications {
+		ids = append(ids, notification.UserID)
+	}
+	return ids
+}
+
+// memberRepo returns an organization repository in which only the given users are members of organization 1
+func memberRepo(userIDs ...uint) *MockOrganizationRepository {
+	orgRepo := new(MockOrganizationRepository)
+	for _, id := range userIDs {
+		orgRepo.On("GetMember", uint(1), id).Return(membership(id, models.OrganizationRoleMember), nil)
+	}
+	orgRepo.On("GetMember", mock.Anything, mock.Anything).Return(nil, nil)
+	return orgRepo
+}
+
+// captureNotifications records the notifications the repository is asked to create
+func captureNotifications(mockRepo *MockNotificationRepository) *[]models.Notification {
+	created := &[]models.Notification{}
+	mockRepo.On("CreateBatch", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*created = append(*created, args.Get(0).([]models.Notification)...)
+	})
+	return created
+}
+
+// TestNotifyOnAssignment verifies that assigning a task notifies the new assignees, except
+// the user who assigned them and users who turned assignment notifications off
+func TestNotifyOnAssignment(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockTaskRepo := new(MockTaskRepository)
+	orgRepo := memberRepo(1, 2, 3)
+	events := services.NewEventBus()
+	services.NewNotificationService(mockRepo, new(MockUserRepository), orgRepo).Subscribe(events)
+	taskService := services.NewTaskService(mockTaskRepo, new(MockProjectRepository), orgRepo, events).ForOrganization(1)
+
+	mockTaskRepo.On("Create", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Task).ID = 5
+	})
+	mockRepo.On("GetDisabledUsers", []uint{2, 3}, models.NotificationTaskAssigned).Return([]uint{3}, nil)
+	created := captureNotifications(mockRepo)
+
+	err := taskService.CreateTask(&models.Task{Title: "Review", UserID: 1, AssigneeIDs: []uint{1, 2, 3}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2}, recipientsOf(*created))
+	notification := (*created)[0]
+	assert.Equal(t, models.NotificationTaskAssigned, notification.Type)
+	assert.Equal(t, uint(1), notification.OrganizationID)
+	assert.Equal(t, uint(5), *notification.TaskID)
+	assert.Equal(t, uint(1), *notification.ActorID)
+}
+
+// TestNotifyOnUpdateAndMention verifies that an update notifies the other watchers of the
+// task and that only newly mentioned members of the organization are notified of mentions
+func TestNotifyOnUpdateAndMention(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockTaskRepo := new(MockTaskRepository)
+	mockUserRepo := new(MockUserRepository)
+	orgRepo := memberRepo(1, 2, 3)
+	events := services.NewEventBus()
+	services.NewNotificationService(mockRepo, mockUserRepo, orgRepo).Subscribe(events)
+	taskService := services.NewTaskService(mockTaskRepo, new(MockProjectRepository), orgRepo, events).ForOrganization(1)
+
+	existing := models.Task{ID: 5, OrganizationID: 1, Title: "Review", Description: "ask @ann@example.com", Status: "Pending", UserID: 1,
+		Assignees: []models.TaskAssignee{{TaskID: 5, UserID: 2}}}
+	mockTaskRepo.On("GetByIDAndUserID", uint(5), uint(2), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = existing
+	})
+	mockTaskRepo.On("Update", mock.Anything).Return(nil)
+	mockTaskRepo.On("GetAdjacentRank", (*uint)(nil), "Completed", "", false, uint(5)).Return("", nil)
+	mockTaskRepo.On("GetWatcherIDs", uint(5)).Return([]uint{1, 2, 4}, nil)
+	mockUserRepo.On("FindByEmail", "bob@example.com").Return(&models.User{ID: 3}, nil)
+	mockUserRepo.On("FindByEmail", "eve@example.com").Return(&models.User{ID: 9}, nil)
+	mockUserRepo.On("FindByEmail", "nobody@example.com").Return(nil, nil)
+	mockRepo.On("GetDisabledUsers", mock.Anything, mock.Anything).Return(nil, nil)
+	created := captureNotifications(mockRepo)
+
+	_, err := taskService.UpdateTask(5, 2, models.TaskUpdateRequest{Status: strPtr("Completed"),
+		Description: strPtr("ask @ann@example.com, @Bob@example.com, @eve@example.com and @nobody@example.com")})
+	assert.NoError(t, err)
+
+	// The assignee made the change, so only the other watchers hear about it
+	assert.Equal(t, []uint{1, 4, 3}, recipientsOf(*created))
+	if assert.Len(t, *created, 3) {
+		updated, mentioned := (*created)[0], (*created)[2]
+		assert.Equal(t, models.NotificationTaskUpdated, updated.Type)
+		assert.Equal(t, `"Review" was updated: description, status`, updated.Message)
+		assert.Equal(t, models.NotificationMentioned, mentioned.Type) // Ann was mentioned before; Eve is not a member
+	}
+	mockUserRepo.AssertNotCalled(t, "FindByEmail", "ann@example.com")
+}
+
+// TestDueDateReminders verifies that reminders are sent once to the creator and assignees of tasks that are due soon
+func TestDueDateReminders(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockTaskRepo := new(MockTaskRepository)
+	events := services.NewEventBus()
+	services.NewNotificationService(mockRepo, new(MockUserRepository), new(MockOrganizationRepository)).Subscribe(events)
+	reminders := services.NewReminderService(mockTaskRepo, events)
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	due := now.Add(30 * time.Minute)
+	task := models.Task{ID: 5, OrganizationID: 2, Title: "Review", UserID: 1, DueDate: &due, Assignees: []models.TaskAssignee{{TaskID: 5, UserID: 2}}}
+	mockTaskRepo.On("GetDueForReminder", now, now.Add(services.DefaultReminderLead)).Return([]models.Task{task}, nil)
+	mockTaskRepo.On("MarkReminded", []uint{5}, now).Return(nil)
+	mockRepo.On("GetDisabledUsers", []uint{1, 2}, models.NotificationReminder).Return(nil, nil)
+	created := captureNotifications(mockRepo)
+
+	sent, err := reminders.SendDueReminders(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []uint{1, 2}, recipientsOf(*created))
+	assert.Equal(t, uint(2), (*created)[0].OrganizationID)
+	assert.Nil(t, (*created)[0].ActorID)
+	assert.Equal(t, `"Review" is due at 2024-05-01T09:30:00Z`, (*created)[0].Message)
+	mockTaskRepo.AssertExpectations(t)
+}
+
+// TestNotificationPreferences verifies that all types are on by default and unknown types are rejected
+func TestNotificationPreferences(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	notificationService := services.NewNotificationService(mockRepo, new(MockUserRepository), new(MockOrganizationRepository))
+
+	mockRepo.On("GetPreferences", uint(1)).Return([]models.NotificationPreference{{UserID: 1, Type: models.NotificationTaskUpdated, Enabled: false}}, nil)
+	mockRepo.On("SetPreferences", uint(1), map[string]bool{models.NotificationTaskUpdated: false}).Return(nil)
+
+	preferences, err := notificationService.UpdatePreferences(1, map[string]bool{models.NotificationTaskUpdated: false})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		models.NotificationTaskAssigned: true,
+		models.NotificationMentioned:    true,
+		models.NotificationTaskUpdated:  false,
+		models.NotificationReminder:     true,
+	}, preferences)
+
+	_, err = notificationService.UpdatePreferences(1, map[string]bool{"digest": false})
+	assert.EqualError(t, err, "invalid notification type: digest (must be one of task_assigned, mentioned, task_updated, reminder)")
+	mockRepo.AssertNumberOfCalls(t, "SetPreferences", 1)
+}
+
+// TestMarkNotificationRead verifies that users can only mark their own notifications as read
+func TestMarkNotificationRead(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	notificationService := services.NewNotificationService(mockRepo, new(MockUserRepository), new(MockOrganizationRepository))
+
+	mockRepo.On("MarkRead", uint(1), uint(7), mock.Anything).Return(true, nil)
+	mockRepo.On("MarkRead", uint(1), uint(8), mock.Anything).Return(false, nil)
+
+	assert.NoError(t, notificationService.MarkRead(1, 7))
+	assert.EqualError(t, notificationService.MarkRead(1, 8), "notification not found")
+}
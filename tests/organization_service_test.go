@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrganizationRepository is a mock implementation of OrganizationRepository
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) Create(organization *models.Organization, ownerID uint) error {
+	args := m.Called(organization, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetByID(id uint) (*models.Organization, error) {
+	args := m.Called(id)
+	organization, _ := args.Get(0).(*models.Organization)
+	return organization, args.Error(1)
+}
+
+func (m *MockOrganizationRepository) Update(organization *models.Organization) error {
+	args := m.Called(organization)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetMemberships(userID uint) ([]models.OrganizationMember, error) {
+	args := m.Called(userID)
+	memberships, _ := args.Get(0).([]models.OrganizationMember)
+	return memberships, args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetMember(organizationID, userID uint) (*models.OrganizationMember, error) {
+	args := m.Called(organizationID, userID)
+	member, _ := args.Get(0).(*models.OrganizationMember)
+	return member, args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetFirstMembership(userID uint) (*models.OrganizationMember, error) {
+	args := m.Called(userID)
+	member, _ := args.Get(0).(*models.OrganizationMember)
+	return member, args.Error(1)
+}
+
+func (m *MockOrganizationRepository) CountOwners(organizationID uint) (int64, error) {
+	args := m.Called(organizationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) AddMember(member *models.OrganizationMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) UpdateMemberRole(organizationID, userID uint, role string) error {
+	args := m.Called(organizationID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) RemoveMember(organizationID, userID uint) error {
+	args := m.Called(organizationID, userID)
+	return args.Error(0)
+}
+
+// membership returns a mocked membership in organization 1
+func membership(userID uint, role string) *models.OrganizationMember {
+	return &models.OrganizationMember{OrganizationID: 1, UserID: userID, Role: role}
+}
+
+// TestOrganizationAddMember verifies that only owners can grant elevated roles
+func TestOrganizationAddMember(t *testing.T) {
+	repo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	service := services.NewOrganizationService(repo, userRepo)
+
+	repo.On("GetMember", uint(1), uint(10)).Return(membership(10, models.OrganizationRoleOwner), nil)
+	repo.On("GetMember", uint(1), uint(11)).Return(membership(11, models.OrganizationRoleAdmin), nil)
+	repo.On("GetMember", uint(1), uint(12)).Return(membership(12, models.OrganizationRoleMember), nil)
+	repo.On("GetMember", uint(1), uint(20)).Return(nil, nil)
+	userRepo.On("FindByEmail", "new@example.com").Return(&models.User{ID: 20, Email: "new@example.com"}, nil)
+	repo.On("AddMember", mock.Anything).Return(nil)
+
+	member, err := service.AddMember(1, 11, "new@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrganizationRoleMember, member.Role)
+
+	_, err = service.AddMember(1, 11, "new@example.com", models.OrganizationRoleAdmin)
+	assert.EqualError(t, err, "forbidden")
+	_, err = service.AddMember(1, 12, "new@example.com", "")
+	assert.EqualError(t, err, "forbidden")
+	_, err = service.AddMember(1, 10, "new@example.com", "guest")
+	assert.EqualError(t, err, "invalid role: must be owner, admin or member")
+
+	member, err = service.AddMember(1, 10, "new@example.com", models.OrganizationRoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrganizationRoleAdmin, member.Role)
+}
+
+// TestOrganizationLastOwner verifies that the last owner can neither step down nor leave
+func TestOrganizationLastOwner(t *testing.T) {
+	repo := new(MockOrganizationRepository)
+	service := services.NewOrganizationService(repo, new(MockUserRepository))
+
+	repo.On("GetMember", uint(1), uint(10)).Return(membership(10, models.OrganizationRoleOwner), nil)
+	repo.On("GetMember", uint(1), uint(11)).Return(membership(11, models.OrganizationRoleAdmin), nil)
+	repo.On("CountOwners", uint(1)).Return(int64(1), nil)
+	repo.On("RemoveMember", uint(1), uint(11)).Return(nil)
+
+	_, err := service.ChangeMemberRole(1, 10, 10, models.OrganizationRoleMember)
+	assert.EqualError(t, err, "invalid request: an organization needs at least one owner")
+	err = service.RemoveMember(1, 10, 10)
+	assert.EqualError(t, err, "invalid request: an organization needs at least one owner")
+
+	// Admins cannot remove owners, but can leave
+	err = service.RemoveMember(1, 11, 10)
+	assert.EqualError(t, err, "forbidden")
+	assert.NoError(t, service.RemoveMember(1, 11, 11))
+	repo.AssertNotCalled(t, "RemoveMember", uint(1), uint(10))
+}
+
+// TestOrganizationHiddenFromNonMembers verifies that other organizations are not found
+func TestOrganizationHiddenFromNonMembers(t *testing.T) {
+	repo := new(MockOrganizationRepository)
+	service := services.NewOrganizationService(repo, new(MockUserRepository))
+
+	repo.On("GetMember", uint(2), uint(10)).Return(nil, nil)
+
+	_, err := service.GetOrganization(2, 10)
+	assert.EqualError(t, err, "organization not found")
+	_, err = service.Rename(2, 10, "Taken over")
+	assert.EqualError(t, err, "organization not found")
+	repo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+// TestActiveOrganization verifies how the active organization is selected and checked
+func TestActiveOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService()
+	repo := new(MockOrganizationRepository)
+	service := services.NewOrganizationService(repo, new(MockUserRepository))
+
+	repo.On("GetFirstMembership", uint(1)).Return(&models.OrganizationMember{OrganizationID: 3, UserID: 1, Role: models.OrganizationRoleOwner}, nil)
+	repo.On("GetMember", uint(4), uint(1)).Return(&models.OrganizationMember{OrganizationID: 4, UserID: 1, Role: models.OrganizationRoleMember}, nil)
+	repo.On("GetMember", uint(5), uint(1)).Return(nil, nil)
+	repo.On("GetFirstMembership", uint(2)).Return(nil, nil)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, nil), middleware.ActiveOrganization(service))
+	router.GET("/tasks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"organization_id": middleware.GetOrganizationID(c)})
+	})
+
+	token, _ := authService.GenerateToken(1)
+	orgToken, _ := authService.GenerateOrganizationToken(1, models.AllScopes, 4)
+	orphanToken, _ := authService.GenerateToken(2)
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		header string
+		status int
+		body   string
+	}{
+		{"first membership", token, "", http.StatusOK, `"organization_id":3`},
+		{"token claim", orgToken, "", http.StatusOK, `"organization_id":4`},
+		{"header wins over claim", orgToken, "5", http.StatusForbidden, "not a member of this organization"},
+		{"header", token, "4", http.StatusOK, `"organization_id":4`},
+		{"other organization", token, "5", http.StatusForbidden, "not a member of this organization"},
+		{"invalid header", token, "acme", http.StatusBadRequest, "Invalid X-Organization-ID header"},
+		{"no organization", orphanToken, "", http.StatusForbidden, "not a member of any organization"},
+	} {
+		req := httptest.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		if tc.header != "" {
+			req.Header.Set(middleware.OrganizationHeader, tc.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.name)
+		assert.Contains(t, w.Body.String(), tc.body, tc.name)
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPasswordPolicy_Default verifies the rules of the default policy
+func TestPasswordPolicy_Default(t *testing.T) {
+	policy := services.DefaultPasswordPolicy()
+
+	tests := []struct {
+		password string
+		message  string
+	}{
+		{"Sh0rt!", "password must be at least 8 characters long"},
+		{"Xy7!" + strings.Repeat("a", 69), "password must not be longer than 72 bytes"},
+		{"lowercase7!", "password must contain an uppercase letter"},
+		{"NoDigitsHere!", "password must contain a number"},
+		{"NoSpecial123", "password must contain a special character"},
+		{"Jane.Doe42!", "password must not contain your email address"},
+		{"Password1!", "password is too common, choose another one"},
+		{"PASSWORD1!", "password is too common, choose another one"},
+	}
+	for _, tt := range tests {
+		err := policy.Validate(tt.password, "jane.doe@example.com")
+		assert.EqualError(t, err, tt.message, tt.password)
+		var policyErr *services.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+	}
+
+	assert.NoError(t, policy.Validate("Tr0ub4dor&3x", "jane.doe@example.com"))
+}
+
+// TestPasswordPolicy_Unicode verifies that lengths count characters and classes cover other scripts
+func TestPasswordPolicy_Unicode(t *testing.T) {
+	policy := services.DefaultPasswordPolicy()
+
+	// Eight Cyrillic characters are 15 bytes but pass the 8 character minimum
+	assert.NoError(t, policy.Validate("Пароль7§", "user@example.com"))
+	assert.EqualError(t, policy.Validate("пароль7§", "user@example.com"), "password must contain an uppercase letter")
+	// 25 three-byte characters exceed the bcrypt limit of 72 bytes
+	assert.EqualError(t, policy.Validate("A1!"+strings.Repeat("密", 24), "user@example.com"), "password must not be longer than 72 bytes")
+}
+
+// TestPasswordPolicy_FromEnv verifies the environment configuration
+func TestPasswordPolicy_FromEnv(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "common.txt")
+	assert.NoError(t, os.WriteFile(list, []byte("# company list\nAcme2024\n"), 0o600))
+
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_REQUIRED_CLASSES", "lower,digit")
+	t.Setenv("PASSWORD_COMMON_LIST", list)
+	policy, err := services.NewPasswordPolicyFromEnv()
+	assert.NoError(t, err)
+
+	assert.EqualError(t, policy.Validate("short1pass", ""), "password must be at least 12 characters long")
+	assert.NoError(t, policy.Validate("long enough 1 pass", ""))
+	// The custom list replaces the bundled one
+	assert.NoError(t, policy.Validate("password1234", ""))
+	t.Setenv("PASSWORD_MIN_LENGTH", "8")
+	policy, err = services.NewPasswordPolicyFromEnv()
+	assert.NoError(t, err)
+	assert.EqualError(t, policy.Validate("acme2024", ""), "password is too common, choose another one")
+
+	t.Setenv("PASSWORD_REQUIRED_CLASSES", "upper,emoji")
+	_, err = services.NewPasswordPolicyFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("PASSWORD_REQUIRED_CLASSES", "")
+	t.Setenv("PASSWORD_MIN_LENGTH", "zero")
+	_, err = services.NewPasswordPolicyFromEnv()
+	assert.Error(t, err)
+}
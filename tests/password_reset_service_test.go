@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/middleware"
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockPasswordResetRepository is a mock implementation of PasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) FindByTokenHash(tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	token, _ := args.Get(0).(*models.PasswordResetToken)
+	return token, args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) Consume(token *models.PasswordResetToken, passwordHash string, at time.Time) (bool, error) {
+	args := m.Called(token, passwordHash, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// TestRequestResetUnknownEmail verifies that unknown emails succeed without sending anything
+func TestRequestResetUnknownEmail(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewPasswordResetService(userRepo, resetRepo, mailer, services.DefaultPasswordPolicy())
+
+	userRepo.On("FindByEmail", "nobody@example.com").Return(nil, nil)
+
+	err := service.RequestReset("nobody@example.com")
+
+	assert.NoError(t, err)
+	assert.Empty(t, mailer.Sent())
+	resetRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestRequestResetSendsHashedToken verifies that the emailed token matches the stored hash
+func TestRequestResetSendsHashedToken(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	mailer := services.NewMemoryMailer()
+	service := services.NewPasswordResetService(userRepo, resetRepo, mailer, services.DefaultPasswordPolicy())
+
+	var stored *models.PasswordResetToken
+	userRepo.On("FindByEmail", "user@example.com").Return(&models.User{ID: 3, Email: "user@example.com"}, nil)
+	resetRepo.On("Create", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.PasswordResetToken)
+	})
+
+	err := service.RequestReset("user@example.com")
+
+	assert.NoError(t, err)
+	sent := mailer.Sent()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, "user@example.com", sent[0].To)
+
+	link := regexp.MustCompile(`https?://\S+`).FindString(sent[0].Body)
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	token := parsed.Query().Get("token")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, uint(3), stored.UserID)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(services.PasswordResetTTL), stored.ExpiresAt, time.Minute)
+}
+
+// TestResetPasswordExpiredToken verifies that expired and used tokens are rejected
+func TestResetPasswordExpiredToken(t *testing.T) {
+	resetRepo := new(MockPasswordResetRepository)
+	service := services.NewPasswordResetService(new(MockUserRepository), resetRepo, services.NewMemoryMailer(), services.DefaultPasswordPolicy())
+
+	used := time.Now().Add(-time.Minute)
+	resetRepo.On("FindByTokenHash", sha256Hex("expired")).Return(&models.PasswordResetToken{ID: 1, UserID: 3, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	resetRepo.On("FindByTokenHash", sha256Hex("used")).Return(&models.PasswordResetToken{ID: 2, UserID: 3, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &used}, nil)
+	resetRepo.On("FindByTokenHash", sha256Hex("unknown")).Return(nil, nil)
+
+	for _, token := range []string{"expired", "used", "unknown"} {
+		err := service.ResetPassword(token, "NewPassw0rd!")
+		assert.EqualError(t, err, "invalid or expired reset token", token)
+	}
+	resetRepo.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestResetPassword verifies that a valid token stores a bcrypt hash of the new password
+func TestResetPassword(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	service := services.NewPasswordResetService(userRepo, resetRepo, services.NewMemoryMailer(), services.DefaultPasswordPolicy())
+
+	userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Email: "user@example.com"}, nil)
+	reset := &models.PasswordResetToken{ID: 1, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	resetRepo.On("FindByTokenHash", sha256Hex("valid")).Return(reset, nil)
+	resetRepo.On("Consume", reset, mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("NewPassw0rd!")) == nil
+	}), mock.Anything).Return(true, nil).Once()
+	resetRepo.On("Consume", reset, mock.Anything, mock.Anything).Return(false, nil)
+
+	assert.NoError(t, service.ResetPassword("valid", "NewPassw0rd!"))
+	// A concurrent second use loses the race in the repository
+	assert.EqualError(t, service.ResetPassword("valid", "NewPassw0rd!"), "invalid or expired reset token")
+}
+
+// TestResetPasswordPolicy verifies that a weak password is rejected without using up the token
+func TestResetPasswordPolicy(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	service := services.NewPasswordResetService(userRepo, resetRepo, services.NewMemoryMailer(), services.DefaultPasswordPolicy())
+
+	userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Email: "user@example.com"}, nil)
+	resetRepo.On("FindByTokenHash", sha256Hex("valid")).Return(&models.PasswordResetToken{ID: 1, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	err := service.ResetPassword("valid", "Password1!")
+	var policyErr *services.PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	resetRepo.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestActiveSession_RevokedToken verifies that tokens issued before a password reset are rejected
+func TestActiveSession_RevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := services.NewAuthService()
+	userRepo := new(MockUserRepository)
+
+	oldToken, _ := authService.GenerateToken(3)
+	revokedAt := time.Now()
+	userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, SessionsRevokedAt: &revokedAt}, nil)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authService, nil), middleware.ActiveSession(userRepo))
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+oldToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
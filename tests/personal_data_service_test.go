@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPersonalDataRepository is a mock implementation of PersonalDataRepository
+type MockPersonalDataRepository struct {
+	mock.Mock
+}
+
+func (m *MockPersonalDataRepository) GetPersonalData(userID uint) (*models.PersonalData, error) {
+	args := m.Called(userID)
+	data, _ := args.Get(0).(*models.PersonalData)
+	return data, args.Error(1)
+}
+
+func (m *MockPersonalDataRepository) QueueErasure(record *models.ErasureRecord) error {
+	args := m.Called(record)
+	if args.Error(0) == nil {
+		record.ID = 1
+	}
+	return args.Error(0)
+}
+
+func (m *MockPersonalDataRepository) GetPendingErasures() ([]models.ErasureRecord, error) {
+	args := m.Called()
+	records, _ := args.Get(0).([]models.ErasureRecord)
+	return records, args.Error(1)
+}
+
+func (m *MockPersonalDataRepository) RecordErasureFailure(record *models.ErasureRecord, message string) error {
+	args := m.Called(record, message)
+	return args.Error(0)
+}
+
+func (m *MockPersonalDataRepository) Erase(record *models.ErasureRecord) error {
+	args := m.Called(record)
+	if args.Error(0) == nil {
+		record.ID = 1
+		record.Counts = map[string]int64{"users": 1}
+	}
+	return args.Error(0)
+}
+
+func (m *MockPersonalDataRepository) GetErasureRecords() ([]models.ErasureRecord, error) {
+	args := m.Called()
+	records, _ := args.Get(0).([]models.ErasureRecord)
+	return records, args.Error(1)
+}
+
+// readZipJSON decodes a JSON file of a ZIP archive
+func readZipJSON(t *testing.T, archive *zip.Reader, name string, value interface{}) {
+	file, err := archive.Open(name)
+	if !assert.NoError(t, err, name) {
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, value), name)
+}
+
+// TestExportPersonalData verifies that the archive contains the account and all related records as JSON
+func TestExportPersonalData(t *testing.T) {
+	repo := new(MockPersonalDataRepository)
+	transferService, taskRepo := newTransferTestService()
+	service := services.NewPersonalDataService(repo, transferService, nil)
+
+	repo.On("GetPersonalData", uint(8)).Return(&models.PersonalData{
+		User:          models.User{ID: 8, Email: "user@example.com", Password: "$2a$10$hash"},
+		Projects:      []models.Project{{ID: 2, Name: "Home", OwnerID: 8}},
+		Tags:          []models.Tag{{ID: 5, Name: "urgent", UserID: 8}},
+		TaskTags:      []models.TaskTagLink{{TaskID: 1, TagID: 5}},
+		SavedViews:    []models.SavedView{},
+		APIKeys:       []models.APIKey{{ID: 3, Name: "CI", KeyHash: "secret-hash"}},
+		CalendarFeeds: []models.CalendarFeed{},
+	}, nil)
+	taskRepo.On("EachByUserID", uint(8), mock.Anything, mock.Anything).Return([][]models.Task{{{ID: 1, Title: "Buy milk", UserID: 8}}}, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, service.Export(8, &buf))
+	assert.NotContains(t, buf.String(), "$2a$10$hash")
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	var manifest struct {
+		Version int      `json:"version"`
+		UserID  uint     `json:"user_id"`
+		Files   []string `json:"files"`
+	}
+	readZipJSON(t, archive, "manifest.json", &manifest)
+	assert.Equal(t, uint(8), manifest.UserID)
+	assert.Contains(t, manifest.Files, "tasks.json")
+	for _, name := range manifest.Files {
+		_, err := archive.Open(name)
+		assert.NoError(t, err, name)
+	}
+
+	var user map[string]interface{}
+	readZipJSON(t, archive, "user.json", &user)
+	assert.Equal(t, "user@example.com", user["email"])
+	assert.NotContains(t, user, "password")
+
+	var tasks []models.Task
+	readZipJSON(t, archive, "tasks.json", &tasks)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "Buy milk", tasks[0].Title)
+
+	var views []models.SavedView
+	readZipJSON(t, archive, "saved_views.json", &views)
+	assert.NotNil(t, views)
+
+	var keys []map[string]interface{}
+	readZipJSON(t, archive, "api_keys.json", &keys)
+	assert.Len(t, keys, 1)
+	assert.NotContains(t, keys[0], "key_hash")
+}
+
+// TestRequestErasure verifies that erasure requests are queued rather than run right away
+func TestRequestErasure(t *testing.T) {
+	repo := new(MockPersonalDataRepository)
+	service := services.NewPersonalDataService(repo, nil, nil)
+
+	repo.On("QueueErasure", mock.MatchedBy(func(record *models.ErasureRecord) bool { return record.UserID == 8 })).Return(nil)
+	repo.On("QueueErasure", mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	record, err := service.RequestErasure(8, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), record.RequestedBy)
+	assert.WithinDuration(t, time.Now(), record.RequestedAt, time.Minute)
+	assert.Nil(t, record.CompletedAt)
+	repo.AssertNotCalled(t, "Erase", mock.Anything)
+
+	_, err = service.RequestErasure(9, 1)
+	assert.EqualError(t, err, "user not found")
+}
+
+// TestRunErasures verifies that the job completes pending erasures, clears login counters and
+// keeps failed ones pending with the error
+func TestRunErasures(t *testing.T) {
+	repo := new(MockPersonalDataRepository)
+	store := repository.NewMemoryLoginAttemptStore()
+	throttle := services.NewLoginThrottleService(store, testThrottleConfig())
+	service := services.NewPersonalDataService(repo, nil, throttle)
+
+	_, err := throttle.RecordFailure("user@example.com", "")
+	assert.NoError(t, err)
+	repo.On("GetPendingErasures").Return([]models.ErasureRecord{
+		{ID: 1, UserID: 8, RequestedBy: 1},
+		{ID: 2, UserID: 9, RequestedBy: 9},
+	}, nil)
+	repo.On("GetPersonalData", uint(8)).Return(&models.PersonalData{User: models.User{ID: 8, Email: "user@example.com"}}, nil)
+	repo.On("GetPersonalData", uint(9)).Return(&models.PersonalData{User: models.User{ID: 9, Email: "other@example.com"}}, nil)
+	var erased *models.ErasureRecord
+	repo.On("Erase", mock.MatchedBy(func(record *models.ErasureRecord) bool { return record.UserID == 8 })).Return(nil).Run(func(args mock.Arguments) {
+		erased = args.Get(0).(*models.ErasureRecord)
+	})
+	repo.On("Erase", mock.Anything).Return(errors.New("deadlock detected"))
+	repo.On("RecordErasureFailure", mock.Anything, "deadlock detected").Return(nil)
+
+	completed, err := service.RunErasures()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, uint(1), erased.RequestedBy)
+	assert.Equal(t, int64(1), erased.Counts["users"])
+	if assert.NotNil(t, erased.CompletedAt) {
+		assert.WithinDuration(t, time.Now(), *erased.CompletedAt, time.Minute)
+	}
+
+	attempt, err := store.Get("account:user@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, attempt)
+
+	repo.AssertCalled(t, "RecordErasureFailure", mock.MatchedBy(func(record *models.ErasureRecord) bool {
+		return record.UserID == 9 && record.CompletedAt == nil
+	}), "deadlock detected")
+}
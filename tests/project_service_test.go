@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockProjectRepository is a mock implementation of ProjectRepository
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) Create(project *models.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetByID(id uint) (*models.Project, error) {
+	args := m.Called(id)
+	project, _ := args.Get(0).(*models.Project)
+	return project, args.Error(1)
+}
+
+func (m *MockProjectRepository) GetByUserID(userID uint, projects *[]models.Project) error {
+	args := m.Called(userID, projects)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) AddMember(member *models.ProjectMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) RemoveMember(projectID, userID uint) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetMember(projectID, userID uint) (*models.ProjectMember, error) {
+	args := m.Called(projectID, userID)
+	member, _ := args.Get(0).(*models.ProjectMember)
+	return member, args.Error(1)
+}
+
+func (m *MockProjectRepository) GetBoardColumns(projectID uint) ([]models.BoardColumn, error) {
+	args := m.Called(projectID)
+	columns, _ := args.Get(0).([]models.BoardColumn)
+	return columns, args.Error(1)
+}
+
+func (m *MockProjectRepository) SetBoardColumns(projectID uint, columns []models.BoardColumn) error {
+	args := m.Called(projectID, columns)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetWorkflowStatuses(projectID uint) ([]models.WorkflowStatus, error) {
+	args := m.Called(projectID)
+	statuses, _ := args.Get(0).([]models.WorkflowStatus)
+	return statuses, args.Error(1)
+}
+
+func (m *MockProjectRepository) CountTasksByStatus(projectID uint) (map[string]int64, error) {
+	args := m.Called(projectID)
+	counts, _ := args.Get(0).(map[string]int64)
+	return counts, args.Error(1)
+}
+
+func (m *MockProjectRepository) SetWorkflow(projectID uint, statuses []models.WorkflowStatus, moves map[string]string) error {
+	args := m.Called(projectID, statuses, moves)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) GetCustomFields(projectID uint) ([]models.CustomField, error) {
+	args := m.Called(projectID)
+	fields, _ := args.Get(0).([]models.CustomField)
+	return fields, args.Error(1)
+}
+
+func (m *MockProjectRepository) SetCustomFields(projectID uint, fields []models.CustomField) error {
+	args := m.Called(projectID, fields)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) WithTx(tx *gorm.DB) repository.ProjectRepository {
+	return m
+}
+
+func (m *MockProjectRepository) ForOrganization(organizationID uint) repository.ProjectRepository {
+	return m
+}
+
+// MockUserRepository is a mock implementation of UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) CreateUser(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) SetDefaultView(userID uint, viewID *uint) error {
+	args := m.Called(userID, viewID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkVerified(userID uint, at time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetVerificationSentAt(userID uint, at time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(userID uint, hash string, at time.Time) error {
+	args := m.Called(userID, hash, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetPendingEmail(userID uint, email string) error {
+	args := m.Called(userID, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ConfirmEmailChange(userID uint, email string, at time.Time) (bool, error) {
+	args := m.Called(userID, email, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// TestCreateProject verifies that a project requires a name
+func TestCreateProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	projectService := services.NewProjectService(mockRepo, new(MockUserRepository), new(MockOrganizationRepository))
+
+	err := projectService.CreateProject(&models.Project{OwnerID: 1})
+	assert.EqualError(t, err, "project name cannot be empty")
+
+	project := &models.Project{Name: "Website", OwnerID: 1}
+	mockRepo.On("Create", project).Return(nil)
+
+	err = projectService.CreateProject(project)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAddProjectMember verifies that the owner can add a registered user
+func TestAddProjectMember(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	mockUserRepo := new(MockUserRepository)
+	orgRepo := new(MockOrganizationRepository)
+	projectService := services.NewProjectService(mockRepo, mockUserRepo, orgRepo).ForOrganization(5)
+
+	mockRepo.On("GetMember", uint(1), uint(1)).Return(&models.ProjectMember{ProjectID: 1, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	mockRepo.On("GetMember", uint(1), uint(2)).Return(nil, nil)
+	mockUserRepo.On("FindByEmail", "teammate@example.com").Return(&models.User{ID: 2, Email: "teammate@example.com"}, nil)
+	mockUserRepo.On("FindByEmail", "outsider@example.com").Return(&models.User{ID: 3, Email: "outsider@example.com"}, nil)
+	orgRepo.On("GetMember", uint(5), uint(2)).Return(&models.OrganizationMember{OrganizationID: 5, UserID: 2}, nil)
+	orgRepo.On("GetMember", uint(5), uint(3)).Return(nil, nil)
+	mockRepo.On("AddMember", mock.Anything).Return(nil)
+
+	member, err := projectService.AddMember(1, 1, "teammate@example.com", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), member.UserID)
+	assert.Equal(t, models.ProjectRoleMember, member.Role)
+
+	_, err = projectService.AddMember(1, 1, "outsider@example.com", "")
+	assert.EqualError(t, err, "user is not a member of this organization")
+}
+
+// TestAddProjectMemberByNonOwner verifies that regular members cannot add members
+func TestAddProjectMemberByNonOwner(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	projectService := services.NewProjectService(mockRepo, new(MockUserRepository), new(MockOrganizationRepository))
+
+	mockRepo.On("GetMember", uint(1), uint(2)).Return(&models.ProjectMember{ProjectID: 1, UserID: 2, Role: models.ProjectRoleMember}, nil)
+
+	_, err := projectService.AddMember(1, 2, "someone@example.com", "")
+
+	assert.EqualError(t, err, "forbidden")
+	mockRepo.AssertNotCalled(t, "AddMember", mock.Anything)
+}
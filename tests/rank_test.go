@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRankBetween verifies that ranks can always be placed between two others and keep sorting as strings
+func TestRankBetween(t *testing.T) {
+	rank, ok := models.RankBetween("", "")
+	assert.True(t, ok)
+	assert.Equal(t, "i", rank)
+	rank, _ = models.RankBetween("i", "")
+	assert.Equal(t, "j", rank)
+	rank, _ = models.RankBetween("", "1")
+	assert.Equal(t, "0i", rank)
+	rank, _ = models.RankBetween("a", "b")
+	assert.Equal(t, "ai", rank)
+
+	for _, invalid := range [][2]string{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"A", ""}} {
+		_, ok := models.RankBetween(invalid[0], invalid[1])
+		assert.False(t, ok, invalid)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	ranks := models.SpreadRanks(10)
+	for i := 0; i < 2000; i++ {
+		sort.Strings(ranks)
+		j := random.Intn(len(ranks) + 1)
+		lower, upper := "", ""
+		if j > 0 {
+			lower = ranks[j-1]
+		}
+		if j < len(ranks) {
+			upper = ranks[j]
+		}
+		rank, ok := models.RankBetween(lower, upper)
+		require.True(t, ok, "%q %q", lower, upper)
+		require.Greater(t, rank, lower)
+		if upper != "" {
+			require.Less(t, rank, upper)
+		}
+		ranks = append(ranks, rank)
+	}
+}
+
+// TestSpreadRanks verifies that rebalanced ranks are sorted, distinct and short
+func TestSpreadRanks(t *testing.T) {
+	assert.Equal(t, []string{"6", "c", "i", "o", "u"}, models.SpreadRanks(5))
+
+	ranks := models.SpreadRanks(10000)
+	assert.True(t, sort.StringsAreSorted(ranks))
+	for i := 1; i < len(ranks); i++ {
+		assert.NotEqual(t, ranks[i-1], ranks[i])
+	}
+	assert.LessOrEqual(t, len(ranks[len(ranks)-1]), 4)
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSavedViewRepository is a mock implementation of SavedViewRepository
+type MockSavedViewRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavedViewRepository) Create(view *models.SavedView) error {
+	args := m.Called(view)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewRepository) GetByID(id uint) (*models.SavedView, error) {
+	args := m.Called(id)
+	view, _ := args.Get(0).(*models.SavedView)
+	return view, args.Error(1)
+}
+
+func (m *MockSavedViewRepository) GetVisibleToUser(userID uint, views *[]models.SavedView) error {
+	args := m.Called(userID, views)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewRepository) Update(view *models.SavedView) error {
+	args := m.Called(view)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewRepository) ForOrganization(organizationID uint) repository.SavedViewRepository {
+	return m
+}
+
+// newSavedViewTestService wires a SavedViewService with mocked repositories
+func newSavedViewTestService() (services.SavedViewService, *MockSavedViewRepository, *MockProjectRepository, *MockUserRepository, *MockTaskRepository) {
+	viewRepo := new(MockSavedViewRepository)
+	projectRepo := new(MockProjectRepository)
+	userRepo := new(MockUserRepository)
+	taskRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(taskRepo, projectRepo, new(MockOrganizationRepository), nil)
+	return services.NewSavedViewService(viewRepo, projectRepo, userRepo, taskService), viewRepo, projectRepo, userRepo, taskRepo
+}
+
+// TestCreateViewRejectsInvalidQuery verifies that view queries are validated when saved
+func TestCreateViewRejectsInvalidQuery(t *testing.T) {
+	viewService, viewRepo, _, _, _ := newSavedViewTestService()
+
+	err := viewService.CreateView(&models.SavedView{Name: "Broken", Query: "sort=password", UserID: 1})
+
+	assert.EqualError(t, err, "invalid view query: unsupported sort field")
+	viewRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestSharedViewVisibleToProjectMember verifies that project members can use a shared view
+func TestSharedViewVisibleToProjectMember(t *testing.T) {
+	viewService, viewRepo, projectRepo, _, taskRepo := newSavedViewTestService()
+
+	projectID := uint(3)
+	view := &models.SavedView{ID: 5, Name: "Overdue", Query: "overdue=true&sort=due_date", UserID: 1, ProjectID: &projectID}
+	viewRepo.On("GetByID", uint(5)).Return(view, nil)
+	projectRepo.On("GetMember", projectID, uint(2)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 2}, nil)
+	taskRepo.On("GetFiltered", uint(2), mock.MatchedBy(func(f models.TaskFilter) bool {
+		return f.Overdue && f.Sort == "due_date" && f.Page == 2
+	}), mock.Anything).Return(int64(0), nil)
+
+	page, err := viewService.GetViewTasks(5, 2, 2, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, 10, page.PageSize)
+	taskRepo.AssertExpectations(t)
+}
+
+// TestPrivateViewHiddenFromOthers verifies that unshared views are only visible to their creator
+func TestPrivateViewHiddenFromOthers(t *testing.T) {
+	viewService, viewRepo, _, _, _ := newSavedViewTestService()
+
+	viewRepo.On("GetByID", uint(5)).Return(&models.SavedView{ID: 5, Name: "Mine", UserID: 1}, nil)
+
+	_, err := viewService.GetView(5, 2)
+
+	assert.EqualError(t, err, "view not found")
+}
+
+// TestGetDefaultFilter verifies that the default view's query is returned as a filter
+func TestGetDefaultFilter(t *testing.T) {
+	viewService, viewRepo, _, userRepo, _ := newSavedViewTestService()
+
+	viewID := uint(5)
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, DefaultViewID: &viewID}, nil)
+	viewRepo.On("GetByID", viewID).Return(&models.SavedView{ID: 5, Name: "Pending", Query: "status=Pending", UserID: 1}, nil)
+
+	filter, err := viewService.GetDefaultFilter(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Pending", filter.Status)
+}
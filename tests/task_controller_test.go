@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,13 +26,14 @@ func setupTaskControllerTest(t *testing.T) (*gin.Engine, services.TaskService, u
 
 	// Setup dependencies
 	taskRepo := repository.NewTaskRepository(db.GetDB())
-	taskService := services.NewTaskService(taskRepo)
+	projectRepo := repository.NewProjectRepository(db.GetDB())
+	taskService := services.NewTaskService(taskRepo, projectRepo, repository.NewOrganizationRepository(db.GetDB()), nil)
 	taskController := controllers.TaskController{Service: taskService}
 	authService := services.NewAuthService()
 
 	// Register protected routes
 	protected := router.Group("/")
-	protected.Use(middleware.AuthMiddleware(authService))
+	protected.Use(middleware.AuthMiddleware(authService, nil))
 	{
 		protected.POST("/tasks", taskController.CreateTask)
 		protected.GET("/tasks", taskController.GetAllTasks)
@@ -64,6 +66,26 @@ func TestTaskCreation(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+// TestTaskCreationIgnoresServerFields verifies that clients cannot set the rank, organization or iCalendar UID
+func TestTaskCreationIgnoresServerFields(t *testing.T) {
+	router, _, _, token := setupTaskControllerTest(t)
+
+	taskData := `{"title": "Test Task", "rank": "~~~", "organization_id": 999, "ical_uid": "foreign@example.com"}`
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(taskData))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var task models.Task
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	assert.NotEqual(t, "~~~", task.Rank)
+	assert.NotEqual(t, uint(999), task.OrganizationID)
+	assert.Empty(t, task.ICalUID)
+}
+
 // TestFetchingAllTasks verifies getting all tasks.
 func TestFetchingAllTasks(t *testing.T) {
 	router, _, _, token := setupTaskControllerTest(t)
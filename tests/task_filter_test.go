@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseTaskFilter verifies parsing of task list query parameters
+func TestParseTaskFilter(t *testing.T) {
+	values, _ := url.ParseQuery("status=Pending&q=report&project_id=4&overdue=true&sort=due_date&order=desc&page=3&page_size=500")
+
+	filter, err := models.ParseTaskFilter(values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Pending", filter.Status)
+	assert.Equal(t, "report", filter.Search)
+	assert.Equal(t, uint(4), *filter.ProjectID)
+	assert.True(t, filter.Overdue)
+	assert.Equal(t, "due_date desc, id desc", filter.OrderClause())
+	assert.Equal(t, 3, filter.Page)
+	assert.Equal(t, models.MaxPageSize, filter.PageSize)
+	assert.Equal(t, 200, filter.Offset())
+}
+
+// TestParseTaskFilter_Priority verifies filtering and sorting by priority, importance and urgency
+func TestParseTaskFilter_Priority(t *testing.T) {
+	values, _ := url.ParseQuery("priority=P1&important=true&urgent=false&sort=priority")
+
+	filter, err := models.ParseTaskFilter(values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "P1", filter.Priority)
+	assert.True(t, *filter.Important)
+	assert.False(t, *filter.Urgent)
+	assert.Equal(t, "priority asc, id asc", filter.OrderClause())
+	assert.True(t, models.HasFilterParams(values))
+
+	filter, err = models.ParseTaskFilter(url.Values{"sort": {"rank"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `rank COLLATE "C" asc, id asc`, filter.OrderClause())
+}
+
+// TestParseTaskFilter_Defaults verifies default pagination and ordering
+func TestParseTaskFilter_Defaults(t *testing.T) {
+	filter, err := models.ParseTaskFilter(url.Values{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, filter.Page)
+	assert.Equal(t, models.DefaultPageSize, filter.PageSize)
+	assert.Equal(t, "created_at asc, id asc", filter.OrderClause())
+	assert.False(t, models.HasFilterParams(url.Values{"page": {"2"}}))
+	assert.True(t, models.HasFilterParams(url.Values{"status": {"Pending"}}))
+}
+
+// TestParseTaskFilter_Invalid verifies that invalid parameters are rejected
+func TestParseTaskFilter_Invalid(t *testing.T) {
+	invalid := []string{
+		"sort=password",
+		"order=sideways",
+		"page=0",
+		"due_before=tomorrow",
+		"project_id=abc",
+		"priority=P5",
+		"important=maybe",
+	}
+
+	for _, query := range invalid {
+		values, _ := url.ParseQuery(query)
+		_, err := models.ParseTaskFilter(values)
+		assert.Error(t, err, query)
+	}
+}
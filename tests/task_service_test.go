@@ -1,12 +1,16 @@
 package tests
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
 	"github.com/EmelinDanila/task-manager-api/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockTaskRepository is a mock implementation of TaskRepository
@@ -14,9 +18,10 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-// GetByID implements repository.TaskRepository.
 func (m *MockTaskRepository) GetByID(id uint) (*models.Task, error) {
-	panic("unimplemented")
+	args := m.Called(id)
+	task, _ := args.Get(0).(*models.Task)
+	return task, args.Error(1)
 }
 
 func (m *MockTaskRepository) Create(task *models.Task) error {
@@ -49,10 +54,148 @@ func (m *MockTaskRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) CreateBatch(tasks []models.Task) error {
+	args := m.Called(tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) EachByUserID(userID uint, batchSize int, fn func(tasks []models.Task) error) error {
+	args := m.Called(userID, batchSize, fn)
+	if batches, ok := args.Get(0).([][]models.Task); ok {
+		for _, batch := range batches {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTaskRepository) GetByIDs(userID uint, ids []uint, tasks *[]models.Task) error {
+	args := m.Called(userID, ids, tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetByICalUIDs(userID uint, uids []string, tasks *[]models.Task) error {
+	args := m.Called(userID, uids, tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SetAssignees(task *models.Task, userIDs []uint, changedBy uint) error {
+	args := m.Called(task, userIDs, changedBy)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetAssignmentHistory(taskID uint) ([]models.TaskAssignmentChange, error) {
+	args := m.Called(taskID)
+	changes, _ := args.Get(0).([]models.TaskAssignmentChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockTaskRepository) Watch(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Unwatch(taskID, userID uint) error {
+	args := m.Called(taskID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetWatcherIDs(taskID uint) ([]uint, error) {
+	args := m.Called(taskID)
+	userIDs, _ := args.Get(0).([]uint)
+	return userIDs, args.Error(1)
+}
+
+func (m *MockTaskRepository) SetBlockers(task *models.Task, blockerIDs []uint, changedBy uint) error {
+	args := m.Called(task, blockerIDs, changedBy)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetBlockerIDs(taskIDs []uint) ([]uint, error) {
+	args := m.Called(taskIDs)
+	blockerIDs, _ := args.Get(0).([]uint)
+	return blockerIDs, args.Error(1)
+}
+
+func (m *MockTaskRepository) GetNextCandidates(userID uint) ([]models.Task, error) {
+	args := m.Called(userID)
+	tasks, _ := args.Get(0).([]models.Task)
+	return tasks, args.Error(1)
+}
+
+func (m *MockTaskRepository) CountOpenBlockers(taskIDs []uint) (map[uint]int, map[uint]int, error) {
+	args := m.Called(taskIDs)
+	blockedBy, _ := args.Get(0).(map[uint]int)
+	blocks, _ := args.Get(1).(map[uint]int)
+	return blockedBy, blocks, args.Error(2)
+}
+
+func (m *MockTaskRepository) GetAdjacentRank(projectID *uint, status, rank string, next bool, excludeID uint) (string, error) {
+	args := m.Called(projectID, status, rank, next, excludeID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateRank(taskID uint, rank, status, category string) error {
+	args := m.Called(taskID, rank, status, category)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RebalanceRanks(projectID *uint, status string) error {
+	args := m.Called(projectID, status)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RebalanceLongRanks() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetByProject(projectID uint) ([]models.Task, error) {
+	args := m.Called(projectID)
+	tasks, _ := args.Get(0).([]models.Task)
+	return tasks, args.Error(1)
+}
+
+func (m *MockTaskRepository) CountInList(projectID *uint, status string, excludeID uint) (int64, error) {
+	args := m.Called(projectID, status, excludeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) LockLists(projectID uint, fn func(repo repository.TaskRepository) error) error {
+	m.Called(projectID)
+	return fn(m)
+}
+
+func (m *MockTaskRepository) GetDueForReminder(from, to time.Time) ([]models.Task, error) {
+	args := m.Called(from, to)
+	tasks, _ := args.Get(0).([]models.Task)
+	return tasks, args.Error(1)
+}
+
+func (m *MockTaskRepository) MarkReminded(ids []uint, at time.Time) error {
+	args := m.Called(ids, at)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) WithTx(tx *gorm.DB) repository.TaskRepository {
+	return m
+}
+
+func (m *MockTaskRepository) ForOrganization(organizationID uint) repository.TaskRepository {
+	return m
+}
+
+func (m *MockTaskRepository) GetFiltered(userID uint, filter models.TaskFilter, tasks *[]models.Task) (int64, error) {
+	args := m.Called(userID, filter, tasks)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // TestToCreateTask tests the CreateTask method of TaskService
 func TestToCreateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	taskService := services.NewTaskService(mockRepo)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
 
 	task := &models.Task{Title: "Test Task", UserID: 1}
 	mockRepo.On("Create", task).Return(nil)
@@ -66,7 +209,7 @@ func TestToCreateTask(t *testing.T) {
 // TestGetTaskByID tests the GetTaskByID method of TaskService
 func TestGetTaskByID(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	taskService := services.NewTaskService(mockRepo)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
 
 	task := &models.Task{ID: 1, Title: "Test Task", UserID: 1}
 	// Mock the GetByIDAndUserID method to return the task
@@ -83,24 +226,60 @@ func TestGetTaskByID(t *testing.T) {
 // TestUpdateTask tests the UpdateTask method of TaskService
 func TestUpdateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	taskService := services.NewTaskService(mockRepo)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
 
-	task := &models.Task{ID: 1, Title: "Updated Task", UserID: 1}
+	task := &models.Task{ID: 1, Title: "Task", UserID: 1}
 	mockRepo.On("GetByIDAndUserID", task.ID, task.UserID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 		*(args.Get(2).(*models.Task)) = *task
 	})
-	mockRepo.On("Update", task).Return(nil)
+	mockRepo.On("Update", &models.Task{ID: 1, Title: "Updated Task", UserID: 1}).Return(nil)
 
-	err := taskService.UpdateTask(task, task.UserID)
+	result, err := taskService.UpdateTask(task.ID, task.UserID, models.TaskUpdateRequest{Title: strPtr("Updated Task")})
 
 	assert.NoError(t, err)
+	assert.Equal(t, "Updated Task", result.Title)
 	mockRepo.AssertExpectations(t)
+
+	_, err = taskService.UpdateTask(task.ID, task.UserID, models.TaskUpdateRequest{Title: strPtr("")})
+	assert.EqualError(t, err, "invalid title: cannot be empty")
+}
+
+// TestUpdateTaskKeepsProject verifies that updates without a project_id keep the task in its
+// project, with its custom field values and rank, and that project_id 0 takes it out
+func TestUpdateTaskKeepsProject(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID := uint(7)
+	existing := models.Task{ID: 1, Title: "Design", UserID: 1, ProjectID: &projectID, Status: "Review", StatusCategory: models.StatusCategoryDoing,
+		Rank: "c", CustomFields: models.FieldValues{"customer": "Acme"}}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = existing
+	})
+	mockRepo.On("Update", mock.Anything).Return(nil)
+
+	update, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Title: strPtr("Renamed")})
+	assert.NoError(t, err)
+	assert.Equal(t, &projectID, update.ProjectID)
+	assert.Equal(t, "Review", update.Status)
+	assert.Equal(t, "c", update.Rank)
+	assert.Equal(t, existing.CustomFields, update.CustomFields)
+	mockProjectRepo.AssertNotCalled(t, "GetWorkflowStatuses", mock.Anything)
+
+	mockProjectRepo.On("GetCustomFields", projectID).Return(projectFields, nil)
+	mockRepo.On("GetAdjacentRank", (*uint)(nil), models.StatusPending, "", false, uint(1)).Return("", nil)
+	detached := uint(0)
+	update, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{ProjectID: &detached, Status: strPtr(models.StatusPending)})
+	assert.NoError(t, err)
+	assert.Nil(t, update.ProjectID)
+	assert.Empty(t, update.CustomFields)
 }
 
 // TestDeleteTask tests the DeleteTask method of TaskService
 func TestDeleteTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	taskService := services.NewTaskService(mockRepo)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
 
 	task := &models.Task{ID: 1, UserID: 1}
 	mockRepo.On("GetByIDAndUserID", task.ID, task.UserID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
@@ -113,3 +292,313 @@ func TestDeleteTask(t *testing.T) {
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+// TestCreateTaskInForeignProject verifies that tasks can only be added to projects the user belongs to
+func TestCreateTaskInForeignProject(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID := uint(7)
+	task := &models.Task{Title: "Test Task", UserID: 1, ProjectID: &projectID}
+	mockProjectRepo.On("GetMember", projectID, uint(1)).Return(nil, nil)
+
+	err := taskService.CreateTask(task)
+
+	assert.EqualError(t, err, "forbidden")
+	mockRepo.AssertNotCalled(t, "Create", task)
+}
+
+// TestListTasks verifies that ListTasks applies default pagination and wraps the result
+func TestListTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	filter := models.TaskFilter{Status: "Pending"}
+	expected := filter
+	expected.Normalize()
+	mockRepo.On("GetFiltered", uint(1), expected, mock.Anything).Return(int64(1), nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*[]models.Task)) = []models.Task{{ID: 1, Title: "Test Task", UserID: 1}}
+	})
+
+	page, err := taskService.ListTasks(1, filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, models.DefaultPageSize, page.PageSize)
+	assert.Len(t, page.Tasks, 1)
+}
+
+// TestCreateTaskWithAssignees verifies that tasks can only be assigned to members of their project or organization
+func TestCreateTaskWithAssignees(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, mockOrgRepo, nil).ForOrganization(1)
+
+	mockOrgRepo.On("GetMember", uint(1), uint(2)).Return(membership(2, models.OrganizationRoleMember), nil)
+	mockOrgRepo.On("GetMember", uint(1), uint(9)).Return(nil, nil)
+	projectID := uint(7)
+	mockProjectRepo.On("GetMember", projectID, uint(1)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 1}, nil)
+	mockProjectRepo.On("GetMember", projectID, uint(2)).Return(nil, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetCustomFields", projectID).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+
+	task := &models.Task{Title: "Review", UserID: 1, AssigneeIDs: []uint{2, 2}}
+	assert.NoError(t, taskService.CreateTask(task))
+	assert.Equal(t, []models.TaskAssignee{{UserID: 2, AssignedBy: 1}}, task.Assignees)
+
+	err := taskService.CreateTask(&models.Task{Title: "Review", UserID: 1, AssigneeIDs: []uint{9}})
+	assert.EqualError(t, err, "invalid assignee: user 9 is not a member of this organization")
+
+	// In a project, only project members can be assigned
+	err = taskService.CreateTask(&models.Task{Title: "Review", UserID: 1, ProjectID: &projectID, AssigneeIDs: []uint{2}})
+	assert.EqualError(t, err, "invalid assignee: user 2 is not a member of this project")
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// TestSetAssignees verifies that assignees can reassign a task and the change is passed on with its author
+func TestSetAssignees(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), mockOrgRepo, nil).ForOrganization(1)
+
+	task := models.Task{ID: 5, Title: "Review", UserID: 1, Assignees: []models.TaskAssignee{{TaskID: 5, UserID: 2}}}
+	mockRepo.On("GetByIDAndUserID", uint(5), uint(2), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = task
+	})
+	mockRepo.On("GetByIDAndUserID", uint(5), uint(4), mock.Anything).Return(gorm.ErrRecordNotFound)
+	mockOrgRepo.On("GetMember", uint(1), uint(3)).Return(membership(3, models.OrganizationRoleMember), nil)
+	mockRepo.On("SetAssignees", mock.Anything, []uint{3}, uint(2)).Return(nil)
+
+	_, err := taskService.SetAssignees(5, 2, []uint{3})
+	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "SetAssignees", mock.Anything, []uint{3}, uint(2))
+
+	_, err = taskService.SetAssignees(5, 4, []uint{4})
+	assert.EqualError(t, err, "task not found")
+}
+
+// TestListAssigned verifies that the assigned tasks view filters by the user as assignee
+func TestListAssigned(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	userID := uint(1)
+	expected := models.TaskFilter{Status: "Pending", AssigneeID: &userID}
+	expected.Normalize()
+	mockRepo.On("GetFiltered", uint(1), expected, mock.Anything).Return(int64(0), nil)
+
+	page, err := taskService.ListAssigned(1, models.TaskFilter{Status: "Pending"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), page.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestWatchTask verifies that users can watch the tasks they see and the tasks of their projects, but no others
+func TestWatchTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID := uint(7)
+	mockRepo.On("GetByIDAndUserID", mock.Anything, mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+	mockRepo.On("GetByID", uint(5)).Return(&models.Task{ID: 5, UserID: 1, ProjectID: &projectID}, nil)
+	mockRepo.On("GetByID", uint(6)).Return(&models.Task{ID: 6, UserID: 1}, nil)
+	mockRepo.On("GetByID", uint(8)).Return(nil, gorm.ErrRecordNotFound)
+	mockProjectRepo.On("GetMember", projectID, uint(3)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 3}, nil)
+	mockProjectRepo.On("GetMember", projectID, uint(4)).Return(nil, nil)
+	mockRepo.On("Watch", uint(5), uint(3)).Return(nil)
+
+	assert.NoError(t, taskService.WatchTask(5, 3))
+	assert.EqualError(t, taskService.WatchTask(5, 4), "task not found")
+	assert.EqualError(t, taskService.WatchTask(6, 3), "task not found") // Tasks outside projects are private
+	assert.EqualError(t, taskService.WatchTask(8, 3), "task not found")
+	mockRepo.AssertNumberOfCalls(t, "Watch", 1)
+}
+
+// TestListWatching verifies that the watching view filters by the user as watcher
+func TestListWatching(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	userID := uint(1)
+	expected := models.TaskFilter{WatcherID: &userID}
+	expected.Normalize()
+	mockRepo.On("GetFiltered", uint(1), expected, mock.Anything).Return(int64(0), nil)
+
+	page, err := taskService.ListWatching(1, models.TaskFilter{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), page.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestTaskPlanningValidation verifies that priorities and effort estimates are validated and
+// that updates keep the planning fields they leave out
+func TestTaskPlanningValidation(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	err := taskService.CreateTask(&models.Task{Title: "Test Task", UserID: 1, Priority: "urgent"})
+	assert.EqualError(t, err, "invalid priority: must be one of P0, P1, P2, P3, P4")
+	err = taskService.CreateTask(&models.Task{Title: "Test Task", UserID: 1, EffortMinutes: models.MaxEffortMinutes + 1})
+	assert.EqualError(t, err, "invalid effort_minutes: must be between 0 and 14400")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	dueDate := time.Date(2025, 6, 1, 17, 0, 0, 0, time.UTC)
+	existing := models.Task{ID: 1, Title: "Test Task", UserID: 1, Priority: models.PriorityP0, Important: true, DueDate: &dueDate}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = existing
+	})
+	mockRepo.On("Update", mock.Anything).Return(nil)
+
+	urgent := true
+	task, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Urgent: &urgent, EffortMinutes: intPtr(90)})
+	assert.NoError(t, err)
+	assert.Equal(t, models.PriorityP0, task.Priority)
+	assert.Equal(t, models.QuadrantDo, task.Quadrant())
+	assert.Equal(t, 90, task.EffortMinutes)
+	assert.Equal(t, &dueDate, task.DueDate)
+
+	var request models.TaskUpdateRequest
+	assert.NoError(t, json.Unmarshal([]byte(`{"due_date": null, "priority": "P3"}`), &request))
+	task, err = taskService.UpdateTask(1, 1, request)
+	assert.NoError(t, err)
+	assert.Nil(t, task.DueDate)
+	assert.Equal(t, models.PriorityP3, task.Priority)
+	assert.True(t, task.Important)
+	_, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Priority: strPtr("")})
+	assert.EqualError(t, err, "invalid priority: must be one of P0, P1, P2, P3, P4")
+}
+
+// TestSetBlockers verifies that blockers must be visible tasks that do not form a cycle
+func TestSetBlockers(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	for _, id := range []uint{1, 2, 3} {
+		task := models.Task{ID: id, Title: "Test Task", UserID: 1}
+		mockRepo.On("GetByIDAndUserID", id, uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			*(args.Get(2).(*models.Task)) = task
+		})
+	}
+	mockRepo.On("GetByIDAndUserID", uint(9), uint(1), mock.Anything).Return(gorm.ErrRecordNotFound)
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(2), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = models.Task{ID: 1, UserID: 1}
+	})
+	// 3 is blocked by 2, which is blocked by 1
+	mockRepo.On("GetBlockerIDs", []uint{2}).Return([]uint{}, nil)
+	mockRepo.On("GetBlockerIDs", []uint{3}).Return([]uint{2}, nil)
+	mockRepo.On("GetBlockerIDs", []uint{1}).Return([]uint{}, nil)
+	mockRepo.On("SetBlockers", mock.Anything, []uint{2}, uint(1)).Return(nil)
+
+	_, err := taskService.SetBlockers(1, 1, []uint{1})
+	assert.EqualError(t, err, "invalid blocker: a task cannot block itself")
+	_, err = taskService.SetBlockers(1, 1, []uint{9})
+	assert.EqualError(t, err, "invalid blocker: task 9 not found")
+	_, err = taskService.SetBlockers(1, 2, []uint{3})
+	assert.EqualError(t, err, "forbidden")
+
+	_, err = taskService.SetBlockers(2, 1, []uint{3}) // 3 already waits on 2
+	assert.EqualError(t, err, "invalid blocker: the task would block itself through a chain of blockers")
+
+	task, err := taskService.SetBlockers(1, 1, []uint{2, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), task.ID)
+	mockRepo.AssertNumberOfCalls(t, "SetBlockers", 1)
+}
+
+// TestNextTasks verifies the ranking of GET /tasks/next: blocked tasks are left out and the
+// score adds up priority, due date, importance, urgency and the tasks waiting on a task
+func TestNextTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+
+	overdue := time.Now().Add(-time.Hour)
+	tomorrow := time.Now().Add(20 * time.Hour)
+	nextMonth := time.Now().Add(30 * 24 * time.Hour)
+	tasks := []models.Task{
+		{ID: 1, Title: "Someday", Priority: models.PriorityP4, DueDate: &nextMonth},
+		{ID: 2, Title: "Overdue", Priority: models.PriorityP2, DueDate: &overdue},
+		{ID: 3, Title: "Blocked", Priority: models.PriorityP0, DueDate: &overdue},
+		{ID: 4, Title: "Important", Priority: models.PriorityP1, Important: true, Urgent: true, DueDate: &tomorrow},
+		{ID: 5, Title: "Unblocks others", Priority: models.PriorityP3, EffortMinutes: 30},
+		{ID: 6, Title: "Same score, longer", Priority: models.PriorityP3, EffortMinutes: 120},
+	}
+	mockRepo.On("GetNextCandidates", uint(1)).Return(tasks, nil)
+	mockRepo.On("CountOpenBlockers", []uint{1, 2, 3, 4, 5, 6}).Return(map[uint]int{3: 1}, map[uint]int{5: 5}, nil)
+
+	ranked, err := taskService.NextTasks(1, 4)
+
+	assert.NoError(t, err)
+	var ids, scores []int
+	for _, task := range ranked {
+		ids = append(ids, int(task.Task.ID))
+		scores = append(scores, task.Score)
+	}
+	assert.Equal(t, []int{4, 2, 5, 6}, ids)
+	assert.Equal(t, []int{30 + 30 + 15 + 10, 20 + 40, 10 + 20, 10}, scores)
+	assert.Equal(t, models.QuadrantDo, ranked[0].Quadrant)
+	assert.Equal(t, 5, ranked[2].Blocks)
+	assert.Equal(t, 20, ranked[2].BlockingScore) // Capped
+}
+
+// TestMoveTask verifies that a move only changes the task's rank, between its anchors or
+// after one of them, and that anchors must be in the same list
+func TestMoveTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID, otherProjectID := uint(7), uint(8)
+	mockProjectRepo.On("GetBoardColumns", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(nil, nil)
+	tasks := map[uint]models.Task{
+		1: {ID: 1, UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "c"},
+		2: {ID: 2, UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "i"},
+		3: {ID: 3, UserID: 1, ProjectID: &projectID, Status: models.StatusPending, Rank: "j"},
+		4: {ID: 4, UserID: 1, ProjectID: &otherProjectID, Status: models.StatusPending, Rank: "i"},
+		5: {ID: 5, UserID: 1, ProjectID: &projectID, Status: models.StatusInProgress, Rank: "m"},
+	}
+	for id, task := range tasks {
+		task := task
+		mockRepo.On("GetByIDAndUserID", id, uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			*(args.Get(2).(*models.Task)) = task
+		})
+	}
+	mockRepo.On("UpdateRank", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	id := func(id uint) *uint { return &id }
+
+	// Between two anchors
+	task, err := taskService.MoveTask(3, 1, models.TaskMoveRequest{AfterID: id(1), BeforeID: id(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, "f", task.Rank)
+	mockRepo.AssertCalled(t, "UpdateRank", uint(3), "f", models.StatusPending, models.StatusCategoryTodo)
+
+	// After the last task of another status column
+	mockRepo.On("GetAdjacentRank", &projectID, models.StatusInProgress, "m", true, uint(1)).Return("", nil)
+	task, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{AfterID: id(5), Status: models.StatusInProgress})
+	assert.NoError(t, err)
+	assert.Equal(t, "n", task.Rank)
+	assert.Equal(t, models.StatusInProgress, task.Status)
+
+	// Before the first task
+	mockRepo.On("GetAdjacentRank", &projectID, models.StatusPending, "c", false, uint(2)).Return("", nil)
+	task, err = taskService.MoveTask(2, 1, models.TaskMoveRequest{BeforeID: id(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, "6", task.Rank)
+
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{AfterID: id(4)})
+	assert.EqualError(t, err, "invalid after_id: task 4 is not in the same project and status")
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{AfterID: id(3), BeforeID: id(2)})
+	assert.EqualError(t, err, "invalid anchors: after_id must come before before_id")
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{BeforeID: id(1)})
+	assert.EqualError(t, err, "invalid before_id: a task cannot be moved next to itself")
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{Status: "Someday"})
+	assert.EqualError(t, err, "invalid task status")
+	mockRepo.AssertNumberOfCalls(t, "UpdateRank", 3)
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTransferTestService wires a TaskTransferService with a mocked task repository
+func newTransferTestService() (services.TaskTransferService, *MockTaskRepository) {
+	mockRepo := new(MockTaskRepository)
+	taskService := services.NewTaskService(mockRepo, new(MockProjectRepository), new(MockOrganizationRepository), nil)
+	return services.NewTaskTransferService(mockRepo, taskService), mockRepo
+}
+
+// TestExportTasksCSV verifies that exported batches are written as CSV rows
+func TestExportTasksCSV(t *testing.T) {
+	transferService, mockRepo := newTransferTestService()
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	batches := [][]models.Task{
+		{{ID: 1, Title: "First", Status: "Pending", UserID: 1, CreatedAt: created, UpdatedAt: created}},
+		{{ID: 2, Title: "Second, with comma", Status: "Completed", Priority: "P1", Urgent: true, EffortMinutes: 30, UserID: 1, CreatedAt: created, UpdatedAt: created}},
+	}
+	mockRepo.On("EachByUserID", uint(1), mock.Anything, mock.Anything).Return(batches, nil)
+
+	var out bytes.Buffer
+	err := transferService.Export(1, services.FormatCSV, &out)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "id,title,description,status,project_id,due_date,priority,important,urgent,effort_minutes,custom_fields,created_at,updated_at", lines[0])
+	assert.Equal(t, `2,"Second, with comma",,Completed,,,P1,false,true,30,,2025-01-02T03:04:05Z,2025-01-02T03:04:05Z`, lines[2])
+}
+
+// TestExportTasksJSON verifies that the JSON export is a valid array even when empty
+func TestExportTasksJSON(t *testing.T) {
+	transferService, mockRepo := newTransferTestService()
+	mockRepo.On("EachByUserID", uint(1), mock.Anything, mock.Anything).Return(nil, nil)
+
+	var out bytes.Buffer
+	err := transferService.Export(1, services.FormatJSON, &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", out.String())
+}
+
+// TestImportTasksCSVWithMapping verifies that mapped CSV columns are imported in one batch
+func TestImportTasksCSVWithMapping(t *testing.T) {
+	transferService, mockRepo := newTransferTestService()
+	mockRepo.On("CreateBatch", mock.MatchedBy(func(tasks []models.Task) bool {
+		return len(tasks) == 2 && tasks[0].Title == "Write report" && tasks[1].DueDate != nil && tasks[1].UserID == 1
+	})).Return(nil)
+
+	file := "Name,State,Deadline\nWrite report,Pending,\nShip release,In Progress,2025-03-01\n"
+	mapping := map[string]string{"title": "Name", "status": "State", "due_date": "Deadline"}
+
+	report, err := transferService.Import(1, services.FormatCSV, strings.NewReader(file), mapping, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Imported)
+	assert.Empty(t, report.Errors)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestImportTasksRowErrors verifies that invalid rows are reported and nothing is saved
+func TestImportTasksRowErrors(t *testing.T) {
+	transferService, mockRepo := newTransferTestService()
+
+	file := `{"title": "Valid"}
+{"title": ""}
+{"title": "Bad status", "status": "Someday"}
+{"title": "Bad priority", "priority": "P9"}
+{"title": "Bad effort", "effort_minutes": -5}
+`
+	report, err := transferService.Import(1, services.FormatNDJSON, strings.NewReader(file), nil, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []models.ImportRowError{
+		{Row: 2, Error: "task title cannot be empty"},
+		{Row: 3, Error: "invalid task status"},
+		{Row: 4, Error: "invalid priority: must be one of P0, P1, P2, P3, P4"},
+		{Row: 5, Error: "invalid effort_minutes: must be between 0 and 14400"},
+	}, report.Errors)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportTasksValidatesOnce verifies that rows are validated once, looking each project up once
+func TestImportTasksValidatesOnce(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+	transferService := services.NewTaskTransferService(mockRepo, taskService)
+
+	mockProjectRepo.On("GetMember", uint(7), uint(1)).Return(&models.ProjectMember{ProjectID: 7, UserID: 1}, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", uint(7)).Return(nil, nil)
+	mockProjectRepo.On("GetCustomFields", uint(7)).Return(nil, nil)
+	mockRepo.On("CreateBatch", mock.MatchedBy(func(tasks []models.Task) bool {
+		return len(tasks) == 3 && *tasks[2].ProjectID == 7 && tasks[2].Status == models.StatusInProgress
+	})).Return(nil)
+
+	file := "title,project_id,status\nOne,7,\nTwo,7,Pending\nThree,7,In Progress\n"
+	report, err := transferService.Import(1, services.FormatCSV, strings.NewReader(file), nil, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Imported)
+	mockProjectRepo.AssertNumberOfCalls(t, "GetWorkflowStatuses", 1)
+	mockProjectRepo.AssertNumberOfCalls(t, "GetCustomFields", 1)
+	mockProjectRepo.AssertNumberOfCalls(t, "GetMember", 3)
+	mockRepo.AssertNumberOfCalls(t, "CreateBatch", 1)
+
+	// Rows that cannot be read and rows that fail validation are reported in file order
+	file = "title,project_id,due_date\n,7,\nTwo,seven,\nThree,7,someday\n"
+	report, err = transferService.Import(1, services.FormatCSV, strings.NewReader(file), nil, true)
+	assert.NoError(t, err)
+	rows := []int{}
+	for _, rowError := range report.Errors {
+		rows = append(rows, rowError.Row)
+	}
+	assert.Equal(t, []int{1, 2, 3}, rows)
+
+	// Statuses are checked against the workflow of the row's project and reported per row
+	mockProjectRepo.On("GetMember", uint(8), uint(1)).Return(&models.ProjectMember{ProjectID: 8, UserID: 1}, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", uint(8)).Return([]models.WorkflowStatus{
+		{ProjectID: 8, Name: "Open", Category: models.StatusCategoryTodo},
+		{ProjectID: 8, Name: "Closed", Category: models.StatusCategoryDone, Position: 1},
+	}, nil)
+	mockProjectRepo.On("GetCustomFields", uint(8)).Return(nil, nil)
+	file = "title,project_id,status\nOne,8,Closed\nTwo,8,Review\nThree,7,Review\n"
+	report, err = transferService.Import(1, services.FormatCSV, strings.NewReader(file), nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ImportRowError{{Row: 2, Error: "invalid task status"}, {Row: 3, Error: "invalid task status"}}, report.Errors)
+	mockRepo.AssertNumberOfCalls(t, "CreateBatch", 1)
+}
+
+// TestImportTasksDryRun verifies that a dry run validates without saving
+func TestImportTasksDryRun(t *testing.T) {
+	transferService, mockRepo := newTransferTestService()
+
+	report, err := transferService.Import(1, services.FormatJSON, strings.NewReader(`[{"title": "One"}, {"title": "Two"}]`), nil, true)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 0, report.Imported)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+// TestImportTasksInvalidMapping verifies that unknown mapped fields are rejected
+func TestImportTasksInvalidMapping(t *testing.T) {
+	transferService, _ := newTransferTestService()
+
+	_, err := transferService.Import(1, services.FormatCSV, strings.NewReader("a\n1\n"), map[string]string{"user_id": "a"}, false)
+
+	assert.EqualError(t, err, `invalid mapping: unknown field "user_id"`)
+}
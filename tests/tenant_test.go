@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB opens a Postgres session with the tenant scope that only builds SQL
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("Could not open dry-run database: %v", err)
+	}
+	if err := db.Use(repository.TenantScope{RowLevelSecurity: true}); err != nil {
+		t.Fatalf("Could not register tenant scope: %v", err)
+	}
+	return db
+}
+
+// TestTenantScope_FiltersStatements verifies that queries, updates and deletes on organization data are scoped
+func TestTenantScope_FiltersStatements(t *testing.T) {
+	db := repository.ForOrganization(newDryRunDB(t), 7)
+
+	var tasks []models.Task
+	stmt := db.Where("user_id = ?", 1).Find(&tasks).Statement
+	assert.Contains(t, stmt.SQL.String(), `"tasks"."organization_id" = $`)
+	assert.Contains(t, stmt.Vars, uint(7))
+
+	stmt = db.Model(&models.Project{}).Where("id = ?", 2).Update("name", "x").Statement
+	assert.Contains(t, stmt.SQL.String(), `"projects"."organization_id" = $`)
+
+	stmt = db.Where("user_id = ?", 1).Delete(&models.Tag{}).Statement
+	assert.Contains(t, stmt.SQL.String(), `"tags"."organization_id" = $`)
+
+	// Tables without organization data are left alone
+	var users []models.User
+	stmt = db.Find(&users).Statement
+	assert.NotContains(t, stmt.SQL.String(), "organization_id")
+}
+
+// TestTenantScope_AssignsNewRows verifies that new rows join the session's organization
+func TestTenantScope_AssignsNewRows(t *testing.T) {
+	db := repository.ForOrganization(newDryRunDB(t), 7)
+
+	task := &models.Task{Title: "Write report", UserID: 1}
+	assert.NoError(t, db.Create(task).Error)
+	assert.Equal(t, uint(7), task.OrganizationID)
+
+	tags := []models.Tag{{Name: "a", UserID: 1}, {Name: "b", UserID: 1}}
+	assert.NoError(t, db.Create(&tags).Error)
+	assert.Equal(t, uint(7), tags[1].OrganizationID)
+
+	foreign := &models.Task{Title: "Elsewhere", UserID: 1, OrganizationID: 8}
+	assert.ErrorIs(t, db.Create(foreign).Error, repository.ErrOrganizationMismatch)
+}
+
+// TestTenantScope_RequiresOrganization verifies that unbound sessions cannot touch organization data
+func TestTenantScope_RequiresOrganization(t *testing.T) {
+	db := newDryRunDB(t)
+
+	var tasks []models.Task
+	assert.ErrorIs(t, db.Find(&tasks).Error, repository.ErrNoOrganization)
+	assert.ErrorIs(t, repository.ForOrganization(db, 0).Find(&tasks).Error, repository.ErrNoOrganization)
+	assert.ErrorIs(t, db.Create(&models.Task{Title: "Orphan"}).Error, repository.ErrNoOrganization)
+
+	// System-wide sessions see every organization
+	stmt := repository.AllOrganizations(db).Find(&tasks).Statement
+	assert.NoError(t, stmt.Error)
+	assert.NotContains(t, stmt.SQL.String(), "organization_id")
+}
+
+// TestTenantScope_ScopesViewSubquery verifies that views are shared through the organization's projects
+func TestTenantScope_ScopesViewSubquery(t *testing.T) {
+	db := newDryRunDB(t)
+	var sql string
+	var vars []interface{}
+	err := db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		if db.Statement.Table == "saved_views" {
+			sql, vars = db.Statement.SQL.String(), db.Statement.Vars
+		}
+	})
+	if err != nil {
+		t.Fatalf("Could not register capture callback: %v", err)
+	}
+
+	var views []models.SavedView
+	assert.NoError(t, repository.NewSavedViewRepository(db).ForOrganization(7).GetVisibleToUser(1, &views))
+	assert.Contains(t, sql, `project_id IN (SELECT "project_id" FROM "project_members" WHERE user_id = $`)
+	assert.Contains(t, sql, `"project_members"."organization_id" = $`)
+	assert.Contains(t, vars, uint(7))
+}
@@ -0,0 +1,125 @@
+package testutils
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// SMTPMessage is an email received by an SMTPSink
+type SMTPMessage struct {
+	From string
+	To   []string
+	Data string // Headers and body as sent, with CRLF line endings
+}
+
+// SMTPSink is a local SMTP server that accepts every message and keeps it in memory, so
+// mailers can be tested over a real SMTP connection.
+type SMTPSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []SMTPMessage
+}
+
+// NewSMTPSink starts an SMTP sink on a free local port; it is stopped when the test ends.
+func NewSMTPSink(t *testing.T) *SMTPSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not start SMTP sink: %v", err)
+	}
+	sink := &SMTPSink{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+// Host returns the host the sink listens on
+func (s *SMTPSink) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the sink listens on
+func (s *SMTPSink) Port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+// Messages returns a copy of the received messages
+func (s *SMTPSink) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.messages...)
+}
+
+func (s *SMTPSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle speaks just enough SMTP for net/smtp: no STARTTLS and no authentication
+func (s *SMTPSink) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost SMTP sink")
+	var message SMTPMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message = SMTPMessage{From: address(line)}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, address(line))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			message.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// address extracts the address from a "MAIL FROM:<...>" or "RCPT TO:<...>" line
+func address(line string) string {
+	start, end := strings.Index(line, "<"), strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -34,6 +34,12 @@ func SetupTestDB(t *testing.T) config.Database {
 		t.Fatalf("Could not migrate database: %v", err)
 	}
 
+	// Migrate the organization, project, tag, saved view and calendar feed models
+	err = db.GetDB().AutoMigrate(&models.Organization{}, &models.OrganizationMember{}, &models.Invitation{}, &models.TaskAssignee{}, &models.TaskAssignmentChange{}, &models.TaskWatcher{}, &models.TaskBlocker{}, &models.Notification{}, &models.NotificationPreference{}, &models.DigestSettings{}, &models.Project{}, &models.ProjectMember{}, &models.BoardColumn{}, &models.WorkflowStatus{}, &models.WorkflowTransition{}, &models.CustomField{}, &models.Tag{}, &models.SavedView{}, &models.CalendarFeed{}, &models.APIKey{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.ErasureRecord{}, &models.AuditEntry{})
+	if err != nil {
+		t.Fatalf("Could not migrate database: %v", err)
+	}
+
 	return db
 }
 
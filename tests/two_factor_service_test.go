@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/repository"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTwoFactorRepository is a mock implementation of TwoFactorRepository
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) SetPendingSecret(userID uint, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Enable(userID uint, counter int64, codeHashes []string, at time.Time) error {
+	args := m.Called(userID, counter, codeHashes, at)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Disable(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseCounter(userID uint, counter int64) (bool, error) {
+	args := m.Called(userID, counter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error) {
+	args := m.Called(userID, codeHash, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// testTOTP computes a 6-digit TOTP code like an authenticator app would
+func testTOTP(secret string, at time.Time) string {
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// TestTOTPReferenceVector checks the test helper against RFC 6238, appendix B
+func TestTOTPReferenceVector(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	assert.Equal(t, "287082", testTOTP(secret, time.Unix(59, 0)))
+	assert.Equal(t, "081804", testTOTP(secret, time.Unix(1111111109, 0)))
+}
+
+// TestEnrollAndConfirmTwoFactor verifies enrollment, confirmation and recovery code storage
+func TestEnrollAndConfirmTwoFactor(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	repo := new(MockTwoFactorRepository)
+	service := services.NewTwoFactorService(userRepo, repo, repository.NewMemoryLoginAttemptStore())
+
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Email: "user@example.com"}, nil).Once()
+	repo.On("SetPendingSecret", uint(1), mock.Anything).Return(nil)
+
+	enrollment, err := service.Enroll(1)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Task%20Manager:user@example.com?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	repo.AssertCalled(t, "SetPendingSecret", uint(1), enrollment.Secret)
+
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Email: "user@example.com", TOTPSecret: enrollment.Secret}, nil)
+	var hashes []string
+	repo.On("Enable", uint(1), mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	})
+
+	_, err = service.Confirm(1, "abcdef")
+	assert.EqualError(t, err, "invalid two-factor code")
+
+	codes, err := service.Confirm(1, testTOTP(enrollment.Secret, time.Now()))
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	assert.Len(t, hashes, 10)
+	assert.Equal(t, sha256Hex(strings.ReplaceAll(codes[0], "-", "")), hashes[0])
+}
+
+// TestTwoFactorLogin verifies the second login step with TOTP and recovery codes
+func TestTwoFactorLogin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	repo := new(MockTwoFactorRepository)
+	service := services.NewTwoFactorService(userRepo, repo, repository.NewMemoryLoginAttemptStore())
+
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	enabledAt := time.Now()
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: &enabledAt}, nil)
+
+	mfaToken, err := service.StartLogin(1, []string{models.ScopeTasksRead})
+	assert.NoError(t, err)
+
+	// The intermediate token is not a login token
+	_, err = services.NewAuthService().VerifyToken(mfaToken)
+	assert.Error(t, err)
+
+	// A TOTP code works once
+	code := testTOTP(secret, time.Now())
+	repo.On("UseCounter", uint(1), mock.Anything).Return(true, nil).Once()
+	repo.On("UseCounter", uint(1), mock.Anything).Return(false, nil)
+	userID, scopes, err := service.CompleteLogin(mfaToken, code)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+	assert.Equal(t, []string{models.ScopeTasksRead}, scopes)
+	_, _, err = service.CompleteLogin(mfaToken, code)
+	assert.EqualError(t, err, "invalid two-factor code")
+
+	// Recovery codes are accepted in any case and without the dash
+	repo.On("UseRecoveryCode", uint(1), sha256Hex("K7Q2MXP4ZD"), mock.Anything).Return(true, nil)
+	_, _, err = service.CompleteLogin(mfaToken, "k7q2mxp4zd")
+	assert.NoError(t, err)
+
+	_, _, err = service.CompleteLogin("not-a-token", code)
+	assert.EqualError(t, err, "invalid or expired mfa token")
+}
+
+// TestTwoFactorLoginLimitsCodes verifies that an mfa token stops working after five wrong codes
+// and that wrong codes count against the account the token was issued to
+func TestTwoFactorLoginLimitsCodes(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	repo := new(MockTwoFactorRepository)
+	service := services.NewTwoFactorService(userRepo, repo, repository.NewMemoryLoginAttemptStore())
+
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	enabledAt := time.Now()
+	userRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1, Email: "user@example.com", TOTPSecret: secret, TOTPEnabledAt: &enabledAt}, nil)
+	repo.On("UseRecoveryCode", uint(1), mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("UseCounter", uint(1), mock.Anything).Return(true, nil)
+
+	mfaToken, err := service.StartLogin(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", service.LoginAccount(mfaToken))
+	assert.Empty(t, service.LoginAccount("not-a-token"))
+
+	for i := 0; i < 5; i++ {
+		_, _, err = service.CompleteLogin(mfaToken, "AAAAA-AAAAA")
+		assert.EqualError(t, err, "invalid two-factor code")
+	}
+	_, _, err = service.CompleteLogin(mfaToken, testTOTP(secret, time.Now()))
+	assert.EqualError(t, err, "invalid or expired mfa token")
+
+	// A new password login gives a fresh token
+	mfaToken, err = service.StartLogin(1, nil)
+	assert.NoError(t, err)
+	_, _, err = service.CompleteLogin(mfaToken, testTOTP(secret, time.Now()))
+	assert.NoError(t, err)
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/EmelinDanila/task-manager-api/models"
 	"github.com/EmelinDanila/task-manager-api/repository"
@@ -57,4 +58,75 @@ func TestUserRepository(t *testing.T) {
 
 		t.Log(foundUser)
 	})
+	// Test case for erasing an account
+	t.Run("EraseAccount", func(t *testing.T) {
+		gdb := db.GetDB()
+		projectRepo := repository.NewProjectRepository(gdb)
+
+		leaving := &models.User{Email: "leaving@example.com", Password: "password123"}
+		staying := &models.User{Email: "staying@example.com", Password: "password123"}
+		assert.NoError(t, repo.CreateUser(leaving))
+		assert.NoError(t, repo.CreateUser(staying))
+
+		// A personal project and a project shared with another member
+		personal := &models.Project{Name: "Personal", OwnerID: leaving.ID}
+		shared := &models.Project{Name: "Shared", OwnerID: leaving.ID}
+		assert.NoError(t, projectRepo.Create(personal))
+		assert.NoError(t, projectRepo.Create(shared))
+		assert.NoError(t, projectRepo.AddMember(&models.ProjectMember{ProjectID: shared.ID, UserID: staying.ID, Role: models.ProjectRoleMember}))
+
+		tag := models.Tag{Name: "private", UserID: leaving.ID}
+		assert.NoError(t, gdb.Create(&tag).Error)
+		privateTask := &models.Task{Title: "Private", UserID: leaving.ID, Tags: []models.Tag{tag}}
+		personalTask := &models.Task{Title: "Personal project", UserID: leaving.ID, ProjectID: &personal.ID}
+		sharedTask := &models.Task{Title: "Shared project", Description: "Ask @Leaving@example.com", UserID: leaving.ID, ProjectID: &shared.ID, Tags: []models.Tag{tag}}
+		for _, task := range []*models.Task{privateTask, personalTask, sharedTask} {
+			assert.NoError(t, gdb.Create(task).Error)
+		}
+
+		personalDataRepo := repository.NewPersonalDataRepository(gdb)
+		record := &models.ErasureRecord{UserID: leaving.ID, RequestedBy: leaving.ID, RequestedAt: time.Now()}
+		assert.NoError(t, personalDataRepo.QueueErasure(record))
+		assert.NotZero(t, record.ID)
+		disabled, err := repo.FindByID(leaving.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, disabled.DisabledAt, "the account is disabled while the erasure is pending")
+		pending, err := personalDataRepo.GetPendingErasures()
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+
+		completedAt := time.Now()
+		record.CompletedAt = &completedAt
+		assert.NoError(t, personalDataRepo.Erase(record))
+		pending, err = personalDataRepo.GetPendingErasures()
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+		assert.Equal(t, int64(2), record.Counts["tasks"])
+		assert.Equal(t, int64(1), record.Counts["tasks_pseudonymized"])
+		assert.Equal(t, int64(1), record.Counts["projects_transferred"])
+
+		// The user and their own data are gone
+		deleted, err := repo.FindByEmail(leaving.Email)
+		assert.NoError(t, err)
+		assert.Nil(t, deleted)
+		var count int64
+		gdb.Unscoped().Model(&models.Task{}).Where("id IN ?", []uint{privateTask.ID, personalTask.ID}).Count(&count)
+		assert.Zero(t, count)
+		gdb.Model(&models.Tag{}).Where("user_id = ?", leaving.ID).Count(&count)
+		assert.Zero(t, count)
+		gdb.Unscoped().Model(&models.Project{}).Where("id = ?", personal.ID).Count(&count)
+		assert.Zero(t, count)
+
+		// The shared task stays without an author and the project changes hands
+		var kept models.Task
+		assert.NoError(t, gdb.Preload("Tags").First(&kept, sharedTask.ID).Error)
+		assert.Zero(t, kept.UserID)
+		assert.Empty(t, kept.Tags)
+		assert.Equal(t, "Ask @deleted-user", kept.Description, "kept tasks no longer mention the address")
+		project, err := projectRepo.GetByID(shared.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, staying.ID, project.OwnerID)
+		assert.Len(t, project.Members, 1)
+		assert.Equal(t, models.ProjectRoleOwner, project.Members[0].Role)
+	})
 }
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/EmelinDanila/task-manager-api/models"
+	"github.com/EmelinDanila/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// reviewWorkflow is a custom workflow where tasks go from Backlog through Doing and Review to Shipped
+var reviewWorkflow = []models.WorkflowStatus{
+	{ProjectID: 7, Name: "Backlog", Category: models.StatusCategoryTodo, Position: 0, Transitions: []string{"Doing"}},
+	{ProjectID: 7, Name: "Doing", Category: models.StatusCategoryDoing, Position: 1, Transitions: []string{"Backlog", "Review"}},
+	{ProjectID: 7, Name: "Review", Category: models.StatusCategoryDoing, Position: 2, Transitions: []string{"Doing", "Shipped"}},
+	{ProjectID: 7, Name: "Shipped", Category: models.StatusCategoryDone, Position: 3, Transitions: []string{}},
+}
+
+// TestWorkflowResolve verifies that built-in status names stand for the first status of their category
+func TestWorkflowResolve(t *testing.T) {
+	workflow := models.Workflow{Custom: true, Statuses: reviewWorkflow}
+
+	status, ok := workflow.Resolve("Review")
+	assert.True(t, ok)
+	assert.Equal(t, "Review", status.Name)
+	status, ok = workflow.Resolve(models.StatusCompleted)
+	assert.True(t, ok)
+	assert.Equal(t, "Shipped", status.Name)
+	_, ok = workflow.Resolve("Someday")
+	assert.False(t, ok)
+
+	assert.True(t, workflow.CanTransition("Doing", "Review"))
+	assert.False(t, workflow.CanTransition("Backlog", "Shipped"))
+	assert.True(t, workflow.CanTransition("Pending", "Shipped")) // Unknown statuses may move anywhere
+	assert.True(t, models.DefaultWorkflow(nil).CanTransition(models.StatusCompleted, models.StatusPending))
+}
+
+// TestTaskStatusesFollowWorkflow verifies that task statuses are checked against the workflow of their project
+func TestTaskStatusesFollowWorkflow(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjectRepo := new(MockProjectRepository)
+	taskService := services.NewTaskService(mockRepo, mockProjectRepo, new(MockOrganizationRepository), nil)
+
+	projectID := uint(7)
+	mockProjectRepo.On("GetMember", projectID, uint(1)).Return(&models.ProjectMember{ProjectID: projectID, UserID: 1}, nil)
+	mockProjectRepo.On("GetWorkflowStatuses", projectID).Return(reviewWorkflow, nil)
+	mockProjectRepo.On("GetCustomFields", projectID).Return(nil, nil)
+	mockProjectRepo.On("GetBoardColumns", projectID).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("GetAdjacentRank", &projectID, mock.Anything, "", false, uint(1)).Return("", nil)
+
+	// New tasks start in the first status; built-in names are mapped by category
+	task := &models.Task{Title: "Design", UserID: 1, ProjectID: &projectID}
+	assert.NoError(t, taskService.CreateTask(task))
+	assert.Equal(t, "Backlog", task.Status)
+	assert.Equal(t, models.StatusCategoryTodo, task.StatusCategory)
+	task = &models.Task{Title: "Release", UserID: 1, ProjectID: &projectID, Status: models.StatusCompleted}
+	assert.NoError(t, taskService.CreateTask(task))
+	assert.Equal(t, "Shipped", task.Status)
+	assert.EqualError(t, taskService.CreateTask(&models.Task{Title: "Other", UserID: 1, ProjectID: &projectID, Status: "Someday"}), "invalid task status")
+
+	existing := models.Task{ID: 1, Title: "Design", UserID: 1, ProjectID: &projectID, Status: "Backlog", StatusCategory: models.StatusCategoryTodo, Rank: "c"}
+	mockRepo.On("GetByIDAndUserID", uint(1), uint(1), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(*models.Task)) = existing
+	})
+
+	_, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Status: strPtr("Shipped")})
+	assert.EqualError(t, err, `invalid status transition: "Backlog" cannot move to "Shipped"`)
+	_, err = taskService.MoveTask(1, 1, models.TaskMoveRequest{Status: "Review"})
+	assert.EqualError(t, err, `invalid status transition: "Backlog" cannot move to "Review"`)
+
+	update, err := taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Status: strPtr(models.StatusInProgress)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Doing", update.Status)
+	assert.Equal(t, models.StatusCategoryDoing, update.StatusCategory)
+
+	// Without a status the task keeps its own
+	update, err = taskService.UpdateTask(1, 1, models.TaskUpdateRequest{Title: strPtr("Renamed")})
+	assert.NoError(t, err)
+	assert.Equal(t, "Backlog", update.Status)
+}
+
+// TestSetWorkflow verifies that owners can replace a workflow and that tasks of removed statuses are moved
+func TestSetWorkflow(t *testing.T) {
+	projectRepo := new(MockProjectRepository)
+	workflowService := services.NewWorkflowService(MockTransactor{}, projectRepo)
+
+	projectRepo.On("GetMember", uint(7), uint(1)).Return(&models.ProjectMember{ProjectID: 7, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetMember", uint(7), uint(2)).Return(&models.ProjectMember{ProjectID: 7, UserID: 2, Role: models.ProjectRoleMember}, nil)
+	projectRepo.On("GetWorkflowStatuses", uint(7)).Return(nil, nil)
+	projectRepo.On("CountTasksByStatus", uint(7)).Return(map[string]int64{models.StatusPending: 3, models.StatusInProgress: 2, models.StatusCompleted: 1}, nil)
+	projectRepo.On("SetWorkflow", uint(7), mock.Anything, mock.Anything).Return(nil)
+
+	request := models.WorkflowRequest{
+		Statuses: []models.WorkflowStatusRequest{
+			{Name: "Backlog", Category: models.StatusCategoryTodo, Transitions: []string{"Doing", "Doing", "Backlog"}},
+			{Name: "Doing", Category: models.StatusCategoryDoing, Transitions: []string{"Shipped"}},
+			{Name: " Shipped ", Category: models.StatusCategoryDone},
+		},
+		StatusMapping: map[string]string{models.StatusCompleted: "Shipped"},
+	}
+	workflow, err := workflowService.SetWorkflow(7, 1, request)
+	assert.NoError(t, err)
+	assert.True(t, workflow.Custom)
+	assert.Equal(t, []string{"Doing"}, workflow.Statuses[0].Transitions)
+	assert.Equal(t, "Shipped", workflow.Statuses[2].Name)
+	assert.Equal(t, []string{}, workflow.Statuses[2].Transitions)
+	projectRepo.AssertCalled(t, "SetWorkflow", uint(7), workflow.Statuses, map[string]string{
+		models.StatusPending:    "Backlog",
+		models.StatusInProgress: "Doing",
+		models.StatusCompleted:  "Shipped",
+	})
+
+	_, err = workflowService.SetWorkflow(7, 2, request)
+	assert.EqualError(t, err, "forbidden")
+
+	invalid := []struct {
+		request models.WorkflowRequest
+		err     string
+	}{
+		{models.WorkflowRequest{}, "invalid workflow: must have between 1 and 20 statuses"},
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Open", Category: models.StatusCategoryTodo}}}, "invalid workflow: at least one status must be in the done category"},
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Done", Category: "finished"}}}, `invalid status category "finished": must be todo, doing or done`},
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Done", Category: models.StatusCategoryDone}, {Name: "Done", Category: models.StatusCategoryDone}}}, `invalid workflow: status "Done" is listed more than once`},
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Done", Category: models.StatusCategoryDone, Transitions: []string{"Open"}}}}, `invalid transition from "Done": unknown status "Open"`},
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Done", Category: models.StatusCategoryDone}}, StatusMapping: map[string]string{models.StatusPending: "Open"}}, `invalid status_mapping: "Open" is not a status of the new workflow`},
+		// Pending and In Progress tasks have no status of their category to go to
+		{models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{{Name: "Done", Category: models.StatusCategoryDone}}, StatusMapping: map[string]string{models.StatusPending: "Done"}}, `invalid status_mapping: tasks with status "In Progress" need a new status`},
+	}
+	for _, tc := range invalid {
+		_, err := workflowService.SetWorkflow(7, 1, tc.request)
+		assert.EqualError(t, err, tc.err)
+	}
+	projectRepo.AssertNumberOfCalls(t, "SetWorkflow", 1)
+
+	// Removed statuses move even without tasks, so tasks created meanwhile are not left behind
+	projectRepo.On("GetMember", uint(8), uint(1)).Return(&models.ProjectMember{ProjectID: 8, UserID: 1, Role: models.ProjectRoleOwner}, nil)
+	projectRepo.On("GetWorkflowStatuses", uint(8)).Return([]models.WorkflowStatus{
+		{ProjectID: 8, Name: "Open", Category: models.StatusCategoryTodo},
+		{ProjectID: 8, Name: "Review", Category: models.StatusCategoryDoing, Position: 1},
+		{ProjectID: 8, Name: "Done", Category: models.StatusCategoryDone, Position: 2},
+	}, nil)
+	projectRepo.On("CountTasksByStatus", uint(8)).Return(map[string]int64{"Open": 4}, nil)
+	projectRepo.On("SetWorkflow", uint(8), mock.Anything, mock.Anything).Return(nil)
+
+	_, err = workflowService.SetWorkflow(8, 1, models.WorkflowRequest{Statuses: []models.WorkflowStatusRequest{
+		{Name: "Open", Category: models.StatusCategoryTodo},
+		{Name: "Shipped", Category: models.StatusCategoryDone},
+	}})
+	assert.NoError(t, err)
+	projectRepo.AssertCalled(t, "SetWorkflow", uint(8), mock.Anything, map[string]string{"Done": "Shipped"})
+}